@@ -25,9 +25,10 @@ func NewClient(params paramscmd.API) (*api.Client, error) {
 }
 
 // NewClientWithoutAuth initializes a new api client with all options following the
-// passed in parameters and disabled authentication.
-func NewClientWithoutAuth(params paramscmd.API) (*api.Client, error) {
-	return newClient(params)
+// passed in parameters and disabled authentication. Additional options are
+// applied on top.
+func NewClientWithoutAuth(params paramscmd.API, opts ...api.Option) (*api.Client, error) {
+	return newClient(params, opts...)
 }
 
 // newClient contains the logic of client initialization, except auth initialization.
@@ -0,0 +1,56 @@
+package backoffstatus
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/backoff"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/ini"
+
+	"github.com/spf13/viper"
+)
+
+// Run executes the backoff-status command.
+func Run(v *viper.Viper) (int, error) {
+	fmt.Print(Status(v))
+
+	return exitcode.Success, nil
+}
+
+// Status returns the backoff state of every endpoint found in the internal
+// config, one endpoint per line.
+func Status(v *viper.Viper) string {
+	states := backoff.LoadEndpointStates(v)
+	if len(states) == 0 {
+		return "No endpoints with backoff state\n"
+	}
+
+	var b strings.Builder
+
+	for _, state := range states {
+		switch {
+		case state.Retries < 1:
+			fmt.Fprintf(&b, "%s: ok\n", state.Endpoint)
+		case state.InBackoff():
+			fmt.Fprintf(
+				&b,
+				"%s: backing off until %s after %d failed attempt(s), last at %s\n",
+				state.Endpoint,
+				state.RetryAt().Format(ini.DateFormat),
+				state.Retries,
+				state.At.Format(ini.DateFormat),
+			)
+		default:
+			fmt.Fprintf(
+				&b,
+				"%s: retrying after %d failed attempt(s), last at %s\n",
+				state.Endpoint,
+				state.Retries,
+				state.At.Format(ini.DateFormat),
+			)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,45 @@
+package backoffstatus_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/cmd/backoffstatus"
+	"github.com/wakatime/wakatime-cli/pkg/ini"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatus(t *testing.T) {
+	at := time.Now().Add(-time.Minute).Format(ini.DateFormat)
+	until := time.Now().Add(time.Hour).Format(ini.DateFormat)
+
+	v := viper.New()
+	v.Set("internal.api_0123456789ab_backoff_at", at)
+	v.Set("internal.api_0123456789ab_backoff_endpoint", "https://api.wakatime.com/api/v1 (api key <hidden>0000)")
+	v.Set("internal.api_0123456789ab_backoff_retries", "2")
+	v.Set("internal.api_0123456789ab_backoff_until", until)
+	v.Set("internal.api_ba9876543210_backoff_at", at)
+	v.Set("internal.api_ba9876543210_backoff_endpoint", "https://api.wakatime.com/api/v1 (api key <hidden>0001)")
+	v.Set("internal.api_ba9876543210_backoff_retries", "1")
+	v.Set("internal.sink_local_backoff_endpoint", "sink local")
+	v.Set("internal.sink_local_backoff_retries", "0")
+
+	assert.Equal(
+		t,
+		"https://api.wakatime.com/api/v1 (api key <hidden>0000): backing off until "+until+
+			" after 2 failed attempt(s), last at "+at+"\n"+
+			"https://api.wakatime.com/api/v1 (api key <hidden>0001): retrying after 1 failed attempt(s),"+
+			" last at "+at+"\n"+
+			"sink local: ok\n",
+		backoffstatus.Status(v),
+	)
+}
+
+func TestStatus_Empty(t *testing.T) {
+	v := viper.New()
+	v.Set("internal.backoff_retries", "1")
+
+	assert.Equal(t, "No endpoints with backoff state\n", backoffstatus.Status(v))
+}
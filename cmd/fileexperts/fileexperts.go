@@ -6,12 +6,15 @@ import (
 	apicmd "github.com/wakatime/wakatime-cli/cmd/api"
 	paramscmd "github.com/wakatime/wakatime-cli/cmd/params"
 	"github.com/wakatime/wakatime-cli/pkg/apikey"
+	"github.com/wakatime/wakatime-cli/pkg/cache"
 	"github.com/wakatime/wakatime-cli/pkg/exitcode"
 	"github.com/wakatime/wakatime-cli/pkg/fileexperts"
 	"github.com/wakatime/wakatime-cli/pkg/filter"
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/log"
 	"github.com/wakatime/wakatime-cli/pkg/project"
+	"github.com/wakatime/wakatime-cli/pkg/pseudonym"
+	"github.com/wakatime/wakatime-cli/pkg/scrub"
 	"github.com/wakatime/wakatime-cli/pkg/wakaerror"
 
 	"github.com/spf13/viper"
@@ -103,6 +106,16 @@ func LoadParams(v *viper.Viper) (paramscmd.Params, error) {
 }
 
 func initHandleOptions(params paramscmd.Params) []heartbeat.HandleOption {
+	var detectionCache *cache.Cache
+	if params.Heartbeat.DetectionCache {
+		detectionCache = cache.NewDefault()
+	}
+
+	var pseudonymizer heartbeat.Pseudonymizer
+	if params.Heartbeat.Sanitize.Pseudonymize {
+		pseudonymizer = pseudonym.NewDefault(params.Heartbeat.Sanitize.PseudonymSecret)
+	}
+
 	return []heartbeat.HandleOption{
 		heartbeat.WithFormatting(),
 		heartbeat.WithEntityModifier(),
@@ -116,13 +129,17 @@ func initHandleOptions(params paramscmd.Params) []heartbeat.HandleOption {
 			MapPatterns:   params.API.KeyPatterns,
 		}),
 		project.WithDetection(project.Config{
+			Cache:                detectionCache,
 			HideProjectNames:     params.Heartbeat.Sanitize.HideProjectNames,
 			MapPatterns:          params.Heartbeat.Project.MapPatterns,
+			PreferredGitRemote:   params.Heartbeat.Project.PreferredGitRemote,
 			ProjectFromGitRemote: params.Heartbeat.Project.ProjectFromGitRemote,
+			Pseudonymizer:        pseudonymizer,
 			Submodule: project.Submodule{
 				DisabledPatterns: params.Heartbeat.Project.SubmodulesDisabled,
 				MapPatterns:      params.Heartbeat.Project.SubmoduleMapPatterns,
 			},
+			Subprojects: params.Heartbeat.Project.Subprojects,
 		}),
 		project.WithFiltering(project.FilterConfig{
 			ExcludeUnknownProject: params.Heartbeat.Filter.ExcludeUnknownProject,
@@ -131,7 +148,14 @@ func initHandleOptions(params paramscmd.Params) []heartbeat.HandleOption {
 			BranchPatterns:    params.Heartbeat.Sanitize.HideBranchNames,
 			FilePatterns:      params.Heartbeat.Sanitize.HideFileNames,
 			HideProjectFolder: params.Heartbeat.Sanitize.HideProjectFolder,
+			Hostname:          params.API.Hostname,
+			PrivacyProfiles:   params.Heartbeat.Sanitize.PrivacyProfiles,
 			ProjectPatterns:   params.Heartbeat.Sanitize.HideProjectNames,
+			Pseudonymizer:     pseudonymizer,
+		}),
+		scrub.WithScrubbing(scrub.Config{
+			Detectors: params.Heartbeat.Scrub.Detectors,
+			Patterns:  params.Heartbeat.Scrub.Patterns,
 		}),
 		fileexperts.WithValidation(),
 		filter.WithLengthValidator(),
@@ -0,0 +1,153 @@
+package heartbeat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	paramscmd "github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/output"
+	"github.com/wakatime/wakatime-cli/pkg/remote"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
+
+	"github.com/spf13/viper"
+)
+
+// Report contains the decisions taken by the heartbeat handle pipeline for
+// the heartbeat of an entity.
+type Report struct {
+	Entity string                      `json:"entity"`
+	Steps  []heartbeat.ExplanationStep `json:"steps"`
+	// Heartbeat is the heartbeat as it would be sent. It is nil, if the
+	// heartbeat was filtered.
+	Heartbeat *heartbeat.Heartbeat `json:"heartbeat"`
+}
+
+// RunExplain executes the explain command. It runs the heartbeat handle pipeline
+// for the entity's heartbeat without sending it and prints every decision taken.
+func RunExplain(v *viper.Viper) (int, error) {
+	params, err := LoadParams(v)
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf("failed to load command parameters: %w", err)
+	}
+
+	var out output.Output
+
+	if outputStr := vipertools.GetString(v, "output"); outputStr != "" {
+		out, err = output.Parse(outputStr)
+		if err != nil {
+			return exitcode.ErrGeneric, fmt.Errorf("failed to parse output: %s", err)
+		}
+	}
+
+	report, err := Explain(params)
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf("failed to explain heartbeat: %w", err)
+	}
+
+	rendered, err := RenderReport(report, out)
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf("failed to render explain report: %w", err)
+	}
+
+	fmt.Print(rendered)
+
+	return exitcode.Success, nil
+}
+
+// Explain runs the heartbeat handle pipeline for the entity's heartbeat
+// against a sender, which sends nothing, and returns the decisions taken.
+// Extra heartbeats are ignored.
+func Explain(params paramscmd.Params) (Report, error) {
+	h := buildHeartbeats(params)[0]
+	h.Explanation = &heartbeat.Explanation{}
+
+	report := Report{
+		Entity: h.Entity,
+	}
+
+	sender := explainSender{report: &report}
+
+	pool := remote.NewPool()
+	defer pool.Close()
+
+	handle := heartbeat.NewHandle(sender, initHandleOptions(params, pool)...)
+
+	if _, err := handle([]heartbeat.Heartbeat{h}); err != nil {
+		return Report{}, err
+	}
+
+	report.Steps = h.Explanation.Steps
+
+	if report.Heartbeat == nil {
+		report.Steps = append(report.Steps, heartbeat.ExplanationStep{
+			Stage:   "length validator",
+			Message: "no heartbeats left after filtering, nothing would be sent",
+		})
+	}
+
+	return report, nil
+}
+
+// RenderReport renders an explain report in the passed in output format.
+func RenderReport(report Report, out output.Output) (string, error) {
+	switch out {
+	case output.JSONOutput:
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to json marshal report: %s", err)
+		}
+
+		return string(data) + "\n", nil
+	case output.RawJSONOutput:
+		data, err := json.Marshal(report)
+		if err != nil {
+			return "", fmt.Errorf("failed to json marshal report: %s", err)
+		}
+
+		return string(data) + "\n", nil
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "entity: %s\n", report.Entity)
+
+	for _, step := range report.Steps {
+		fmt.Fprintf(&b, "%s: %s\n", step.Stage, step.Message)
+	}
+
+	if report.Heartbeat == nil {
+		b.WriteString("result: heartbeat would not be sent\n")
+
+		return b.String(), nil
+	}
+
+	data, err := json.Marshal(report.Heartbeat)
+	if err != nil {
+		return "", fmt.Errorf("failed to json marshal heartbeat: %s", err)
+	}
+
+	fmt.Fprintf(&b, "result: heartbeat would be sent as %s\n", data)
+
+	return b.String(), nil
+}
+
+// explainSender is a heartbeat sender, which adds the first heartbeat to
+// the report instead of sending it.
+type explainSender struct {
+	report *Report
+}
+
+// SendHeartbeats adds the first heartbeat to the report.
+func (s explainSender) SendHeartbeats(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	log.Debugf("explain mode, not sending %d heartbeat(s)", len(hh))
+
+	if len(hh) > 0 {
+		s.report.Heartbeat = &hh[0]
+	}
+
+	return []heartbeat.Result{}, nil
+}
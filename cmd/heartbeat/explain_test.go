@@ -0,0 +1,140 @@
+package heartbeat_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	cmdheartbeat "github.com/wakatime/wakatime-cli/cmd/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/output"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	entity := filepath.Join(t.TempDir(), "main.go")
+
+	data, err := os.ReadFile("testdata/main.go")
+	require.NoError(t, err)
+
+	err = os.WriteFile(entity, data, 0600)
+	require.NoError(t, err)
+
+	v := viper.New()
+	v.Set("alternate-branch", "master")
+	v.Set("disable-detection-cache", true)
+	v.Set("entity", entity)
+	v.Set("entity-type", "file")
+	v.Set("hide-branch-names", true)
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("project", "wakatime-cli")
+	v.Set("time", 1585598059.1)
+
+	params, err := cmdheartbeat.LoadParams(v)
+	require.NoError(t, err)
+
+	report, err := cmdheartbeat.Explain(params)
+	require.NoError(t, err)
+
+	assert.Equal(t, entity, report.Entity)
+	assert.Equal(t, []heartbeat.ExplanationStep{
+		{
+			Stage:   "filter",
+			Message: "heartbeat passed include, exclude and .wakatime-project file filters",
+		},
+		{
+			Stage:   "language",
+			Message: `language "Go" detected by chroma lexer "Go" with weight 0.50`,
+		},
+		{
+			Stage:   "dependencies",
+			Message: "found dependencies os",
+		},
+		{
+			Stage:   "project",
+			Message: `project "wakatime-cli" with branch "master" detected by project override`,
+		},
+		{
+			Stage:   "sanitize",
+			Message: `branch hidden by hide_branch_names pattern ".*"`,
+		},
+	}, report.Steps)
+
+	require.NotNil(t, report.Heartbeat)
+	assert.Equal(t, "wakatime-cli", *report.Heartbeat.Project)
+	assert.Nil(t, report.Heartbeat.Branch)
+}
+
+func TestExplain_Filtered(t *testing.T) {
+	v := viper.New()
+	v.Set("disable-detection-cache", true)
+	v.Set("entity", "testdata/main.go")
+	v.Set("entity-type", "file")
+	v.Set("exclude", "main.go$")
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("time", 1585598059.1)
+
+	params, err := cmdheartbeat.LoadParams(v)
+	require.NoError(t, err)
+
+	report, err := cmdheartbeat.Explain(params)
+	require.NoError(t, err)
+
+	assert.Equal(t, []heartbeat.ExplanationStep{
+		{
+			Stage:   "filter",
+			Message: `heartbeat skipped: filter by pattern: skipping because matches exclude pattern "(?i)main.go$"`,
+		},
+		{
+			Stage:   "length validator",
+			Message: "no heartbeats left after filtering, nothing would be sent",
+		},
+	}, report.Steps)
+
+	assert.Nil(t, report.Heartbeat)
+}
+
+func TestRenderReport(t *testing.T) {
+	report := cmdheartbeat.Report{
+		Entity: "/path/to/main.go",
+		Steps: []heartbeat.ExplanationStep{
+			{
+				Stage:   "project",
+				Message: `project "wakatime-cli" with branch "master" detected by git-detector`,
+			},
+		},
+		Heartbeat: &heartbeat.Heartbeat{
+			Category:   heartbeat.CodingCategory,
+			Entity:     "/path/to/main.go",
+			EntityType: heartbeat.FileType,
+			Project:    heartbeat.PointerTo("wakatime-cli"),
+			Time:       1585598059,
+		},
+	}
+
+	rendered, err := cmdheartbeat.RenderReport(report, output.TextOutput)
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		"entity: /path/to/main.go\n"+
+			`project: project "wakatime-cli" with branch "master" detected by git-detector`+"\n"+
+			`result: heartbeat would be sent as {"category":"coding","entity":"/path/to/main.go","type":"file",`+
+			`"project":"wakatime-cli","time":1585598059,"user_agent":""}`+"\n",
+		rendered,
+	)
+}
+
+func TestRenderReport_Filtered(t *testing.T) {
+	report := cmdheartbeat.Report{
+		Entity: "/path/to/main.go",
+	}
+
+	rendered, err := cmdheartbeat.RenderReport(report, output.RawJSONOutput)
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"entity":"/path/to/main.go","steps":null,"heartbeat":null}`+"\n", rendered)
+}
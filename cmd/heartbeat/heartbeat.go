@@ -3,14 +3,19 @@ package heartbeat
 import (
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 
 	apicmd "github.com/wakatime/wakatime-cli/cmd/api"
 	offlinecmd "github.com/wakatime/wakatime-cli/cmd/offline"
 	paramscmd "github.com/wakatime/wakatime-cli/cmd/params"
+	sinkcmd "github.com/wakatime/wakatime-cli/cmd/sink"
+	"github.com/wakatime/wakatime-cli/pkg/activity"
 	"github.com/wakatime/wakatime-cli/pkg/api"
 	"github.com/wakatime/wakatime-cli/pkg/apikey"
 	"github.com/wakatime/wakatime-cli/pkg/backoff"
+	"github.com/wakatime/wakatime-cli/pkg/cache"
+	"github.com/wakatime/wakatime-cli/pkg/category"
 	"github.com/wakatime/wakatime-cli/pkg/deps"
 	"github.com/wakatime/wakatime-cli/pkg/exitcode"
 	"github.com/wakatime/wakatime-cli/pkg/filestats"
@@ -21,7 +26,10 @@ import (
 	"github.com/wakatime/wakatime-cli/pkg/log"
 	"github.com/wakatime/wakatime-cli/pkg/offline"
 	"github.com/wakatime/wakatime-cli/pkg/project"
+	"github.com/wakatime/wakatime-cli/pkg/pseudonym"
 	"github.com/wakatime/wakatime-cli/pkg/remote"
+	"github.com/wakatime/wakatime-cli/pkg/scrub"
+	"github.com/wakatime/wakatime-cli/pkg/sink"
 	"github.com/wakatime/wakatime-cli/pkg/wakaerror"
 
 	"github.com/spf13/viper"
@@ -41,9 +49,12 @@ func Run(v *viper.Viper) (int, error) {
 		// api.ErrAuth represents an error when parsing api key.
 		// Save heartbeats to offline db even when api key invalid.
 		// It avoids losing heartbeats when api key is invalid.
+		// Dry runs never touch the offline queue.
 		if errors.As(err, &errauth) {
-			if err := offlinecmd.SaveHeartbeats(v, nil, queueFilepath); err != nil {
-				log.Errorf("failed to save heartbeats to offline queue: %s", err)
+			if !v.GetBool("dry-run") {
+				if err := offlinecmd.SaveHeartbeats(v, nil, queueFilepath); err != nil {
+					log.Errorf("failed to save heartbeats to offline queue: %s", err)
+				}
 			}
 
 			return errauth.ExitCode(), fmt.Errorf("sending heartbeat(s) failed: %w", errauth)
@@ -78,6 +89,10 @@ func SendHeartbeats(v *viper.Viper, queueFilepath string) error {
 
 	heartbeats := buildHeartbeats(params)
 
+	if params.Heartbeat.DryRun {
+		return dryRun(params, heartbeats)
+	}
+
 	var chOfflineSave = make(chan bool)
 
 	// only send at once the maximum amount of `offline.SendLimit`.
@@ -97,21 +112,41 @@ func SendHeartbeats(v *viper.Viper, queueFilepath string) error {
 		heartbeats = heartbeats[:offline.SendLimit]
 	}
 
-	handleOpts := initHandleOptions(params)
+	pool := remote.NewPool()
+	defer pool.Close()
+
+	handleOpts := initHandleOptions(params, pool)
+
+	activityFilepath, err := activity.Filepath()
+	if err != nil {
+		log.Warnf("failed to load activity filepath: %s", err)
+	}
+
+	if params.Offline.ActivityFile != "" {
+		activityFilepath = params.Offline.ActivityFile
+	}
+
+	if activityFilepath != "" {
+		handleOpts = append(handleOpts, activity.WithStore(activityFilepath))
+	}
+
+	if sinks := sinkcmd.NewSinks(v, params); len(sinks) > 0 {
+		handleOpts = append(handleOpts, sink.WithFanOut(sinks))
+	}
 
 	if !params.Offline.Disabled {
 		if params.Offline.QueueFile != "" {
 			queueFilepath = params.Offline.QueueFile
 		}
 
-		handleOpts = append(handleOpts, offline.WithQueue(queueFilepath))
+		handleOpts = append(handleOpts, offline.WithQueue(queueFilepath, offline.WithRetention(params.Offline.Retention)))
 	}
 
-	handleOpts = append(handleOpts, backoff.WithBackoff(backoff.Config{
-		V:        v,
-		At:       params.API.BackoffAt,
-		Retries:  params.API.BackoffRetries,
+	handleOpts = append(handleOpts, backoff.WithEndpointBackoff(backoff.EndpointConfig{
 		HasProxy: params.API.ProxyURL != "",
+		States:   params.API.Backoff,
+		URL:      params.API.URL,
+		V:        v,
 	}))
 
 	apiClient, err := apicmd.NewClientWithoutAuth(params.API)
@@ -146,14 +181,39 @@ func SendHeartbeats(v *viper.Viper, queueFilepath string) error {
 	return nil
 }
 
+// dryRun processes heartbeats and prints the api requests, which would be sent
+// for them, without touching the network or the offline queue.
+func dryRun(params paramscmd.Params, heartbeats []heartbeat.Heartbeat) error {
+	apiClient, err := apicmd.NewClientWithoutAuth(params.API)
+	if err != nil {
+		return fmt.Errorf("failed to initialize api client: %w", err)
+	}
+
+	pool := remote.NewPool()
+	defer pool.Close()
+
+	handle := heartbeat.NewHandle(api.NewDryRun(apiClient, os.Stdout), initHandleOptions(params, pool)...)
+
+	if _, err := handle(heartbeats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // LoadParams loads params from viper.Viper instance. Returns ErrAuth
-// if failed to retrieve api key.
+// if failed to retrieve api key. No api key is required for dry runs.
 func LoadParams(v *viper.Viper) (paramscmd.Params, error) {
 	if v == nil {
 		return paramscmd.Params{}, errors.New("viper instance unset")
 	}
 
-	apiParams, err := paramscmd.LoadAPIParams(v)
+	loadAPIParams := paramscmd.LoadAPIParams
+	if v.GetBool("dry-run") {
+		loadAPIParams = paramscmd.LoadAPIParamsWithoutKey
+	}
+
+	apiParams, err := loadAPIParams(v)
 	if err != nil {
 		return paramscmd.Params{}, fmt.Errorf("failed to load API parameters: %w", err)
 	}
@@ -167,6 +227,7 @@ func LoadParams(v *viper.Viper) (paramscmd.Params, error) {
 		API:       apiParams,
 		Heartbeat: heartbeatParams,
 		Offline:   paramscmd.LoadOfflineParams(v),
+		Sinks:     paramscmd.LoadSinkParams(v),
 	}, nil
 }
 
@@ -176,11 +237,13 @@ func buildHeartbeats(params paramscmd.Params) []heartbeat.Heartbeat {
 	userAgent := heartbeat.UserAgent(params.API.Plugin)
 
 	heartbeats = append(heartbeats, heartbeat.New(
+		params.Heartbeat.AILineChanges,
 		params.Heartbeat.Project.BranchAlternate,
 		params.Heartbeat.Category,
 		params.Heartbeat.CursorPosition,
 		params.Heartbeat.Entity,
 		params.Heartbeat.EntityType,
+		params.Heartbeat.HumanLineChanges,
 		params.Heartbeat.IsUnsavedEntity,
 		params.Heartbeat.IsWrite,
 		params.Heartbeat.Language,
@@ -203,11 +266,13 @@ func buildHeartbeats(params paramscmd.Params) []heartbeat.Heartbeat {
 
 		for _, h := range params.Heartbeat.ExtraHeartbeats {
 			heartbeats = append(heartbeats, heartbeat.New(
+				h.AILineChanges,
 				h.BranchAlternate,
 				h.Category,
 				h.CursorPosition,
 				h.Entity,
 				h.EntityType,
+				h.HumanLineChanges,
 				h.IsUnsavedEntity,
 				h.IsWrite,
 				h.Language,
@@ -230,7 +295,19 @@ func buildHeartbeats(params paramscmd.Params) []heartbeat.Heartbeat {
 	return heartbeats
 }
 
-func initHandleOptions(params paramscmd.Params) []heartbeat.HandleOption {
+// initHandleOptions returns the handle options of the heartbeat processing
+// pipeline. Connections to remote hosts are shared by its stages through pool.
+func initHandleOptions(params paramscmd.Params, pool *remote.Pool) []heartbeat.HandleOption {
+	var detectionCache *cache.Cache
+	if params.Heartbeat.DetectionCache {
+		detectionCache = cache.NewDefault()
+	}
+
+	var pseudonymizer heartbeat.Pseudonymizer
+	if params.Heartbeat.Sanitize.Pseudonymize {
+		pseudonymizer = pseudonym.NewDefault(params.Heartbeat.Sanitize.PseudonymSecret)
+	}
+
 	return []heartbeat.HandleOption{
 		heartbeat.WithFormatting(),
 		heartbeat.WithEntityModifier(),
@@ -239,26 +316,42 @@ func initHandleOptions(params paramscmd.Params) []heartbeat.HandleOption {
 			Include:                    params.Heartbeat.Filter.Include,
 			IncludeOnlyWithProjectFile: params.Heartbeat.Filter.IncludeOnlyWithProjectFile,
 		}),
-		remote.WithDetection(),
+		remote.WithDetection(remote.Config{
+			HeadOnly: params.Heartbeat.RemoteHeadOnly,
+			Pool:     pool,
+		}),
 		apikey.WithReplacing(apikey.Config{
 			DefaultAPIKey: params.API.Key,
 			MapPatterns:   params.API.KeyPatterns,
 		}),
-		filestats.WithDetection(),
+		filestats.WithDetection(filestats.Config{
+			Cache: detectionCache,
+		}),
 		language.WithDetection(language.Config{
+			Cache:         detectionCache,
 			GuessLanguage: params.Heartbeat.GuessLanguage,
 		}),
 		deps.WithDetection(deps.Config{
 			FilePatterns: params.Heartbeat.Sanitize.HideFileNames,
+			Manifests:    params.Heartbeat.ManifestDeps,
 		}),
 		project.WithDetection(project.Config{
+			Cache:                detectionCache,
 			HideProjectNames:     params.Heartbeat.Sanitize.HideProjectNames,
 			MapPatterns:          params.Heartbeat.Project.MapPatterns,
+			PreferredGitRemote:   params.Heartbeat.Project.PreferredGitRemote,
 			ProjectFromGitRemote: params.Heartbeat.Project.ProjectFromGitRemote,
+			Pseudonymizer:        pseudonymizer,
+			RemotePool:           pool,
 			Submodule: project.Submodule{
 				DisabledPatterns: params.Heartbeat.Project.SubmodulesDisabled,
 				MapPatterns:      params.Heartbeat.Project.SubmoduleMapPatterns,
 			},
+			Subprojects: params.Heartbeat.Project.Subprojects,
+		}),
+		category.WithInference(category.Config{
+			Conventions: params.Heartbeat.CategoryInference.Conventions,
+			MapPatterns: params.Heartbeat.CategoryInference.MapPatterns,
 		}),
 		project.WithFiltering(project.FilterConfig{
 			ExcludeUnknownProject: params.Heartbeat.Filter.ExcludeUnknownProject,
@@ -267,7 +360,14 @@ func initHandleOptions(params paramscmd.Params) []heartbeat.HandleOption {
 			BranchPatterns:    params.Heartbeat.Sanitize.HideBranchNames,
 			FilePatterns:      params.Heartbeat.Sanitize.HideFileNames,
 			HideProjectFolder: params.Heartbeat.Sanitize.HideProjectFolder,
+			Hostname:          params.API.Hostname,
+			PrivacyProfiles:   params.Heartbeat.Sanitize.PrivacyProfiles,
 			ProjectPatterns:   params.Heartbeat.Sanitize.HideProjectNames,
+			Pseudonymizer:     pseudonymizer,
+		}),
+		scrub.WithScrubbing(scrub.Config{
+			Detectors: params.Heartbeat.Scrub.Detectors,
+			Patterns:  params.Heartbeat.Scrub.Patterns,
 		}),
 		remote.WithCleanup(),
 		filter.WithLengthValidator(),
@@ -16,6 +16,7 @@ import (
 	"github.com/wakatime/wakatime-cli/cmd"
 	cmdheartbeat "github.com/wakatime/wakatime-cli/cmd/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/api"
+	"github.com/wakatime/wakatime-cli/pkg/backoff"
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/ini"
 	"github.com/wakatime/wakatime-cli/pkg/log"
@@ -151,6 +152,81 @@ func TestSendHeartbeats_WithFiltering_Exclude(t *testing.T) {
 	assert.Equal(t, 0, numCalls)
 }
 
+func TestSendHeartbeats_DryRun(t *testing.T) {
+	testServerURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var numCalls int
+
+	router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		numCalls++
+	})
+
+	v := viper.New()
+	v.SetDefault("sync-offline-activity", 1000)
+	v.Set("api-url", testServerURL)
+	v.Set("disable-detection-cache", true)
+	v.Set("dry-run", true)
+	v.Set("entity", "testdata/main.go")
+	v.Set("entity-type", "file")
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("plugin", "plugin")
+	v.Set("time", 1585598059.1)
+	v.Set("timeout", 5)
+
+	offlineQueueFile, err := os.CreateTemp(t.TempDir(), "")
+	require.NoError(t, err)
+
+	err = cmdheartbeat.SendHeartbeats(v, offlineQueueFile.Name())
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, numCalls)
+
+	info, err := offlineQueueFile.Stat()
+	require.NoError(t, err)
+
+	assert.Zero(t, info.Size())
+}
+
+func TestSendHeartbeats_DryRun_WithoutAPIKey(t *testing.T) {
+	testServerURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var numCalls int
+
+	router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		numCalls++
+	})
+
+	v := viper.New()
+	v.SetDefault("sync-offline-activity", 1000)
+	v.Set("api-url", testServerURL)
+	v.Set("disable-detection-cache", true)
+	v.Set("dry-run", true)
+	v.Set("entity", "testdata/main.go")
+	v.Set("entity-type", "file")
+	v.Set("plugin", "plugin")
+	v.Set("time", 1585598059.1)
+	v.Set("timeout", 5)
+
+	offlineQueueFile, err := os.CreateTemp(t.TempDir(), "")
+	require.NoError(t, err)
+
+	err = cmdheartbeat.SendHeartbeats(v, offlineQueueFile.Name())
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, numCalls)
+
+	info, err := offlineQueueFile.Stat()
+	require.NoError(t, err)
+
+	assert.Zero(t, info.Size())
+}
+
 func TestSendHeartbeats_ExtraHeartbeats(t *testing.T) {
 	testServerURL, router, tearDown := setupTestServer()
 	defer tearDown()
@@ -774,8 +850,10 @@ func TestSendHeartbeats_ErrBackoff(t *testing.T) {
 
 	v := viper.New()
 
-	v.Set("internal.backoff_at", time.Now().Add(10*time.Minute).Format(ini.DateFormat))
-	v.Set("internal.backoff_retries", "1")
+	v.Set("activity-file", filepath.Join(tmpDir, "activity.bdb"))
+	backoffName := backoff.EndpointName(testServerURL, "00000000-0000-4000-8000-000000000000")
+	v.Set("internal."+backoffName+"_backoff_at", time.Now().Add(10*time.Minute).Format(ini.DateFormat))
+	v.Set("internal."+backoffName+"_backoff_retries", "1")
 	v.SetDefault("sync-offline-activity", 1000)
 	v.Set("api-url", testServerURL)
 	v.Set("entity", "testdata/main.go")
@@ -837,8 +915,10 @@ func TestSendHeartbeats_ErrBackoff_Verbose(t *testing.T) {
 
 	v := viper.New()
 
-	v.Set("internal.backoff_at", time.Now().Add(10*time.Minute).Format(ini.DateFormat))
-	v.Set("internal.backoff_retries", "1")
+	v.Set("activity-file", filepath.Join(tmpDir, "activity.bdb"))
+	backoffName := backoff.EndpointName(testServerURL, "00000000-0000-4000-8000-000000000000")
+	v.Set("internal."+backoffName+"_backoff_at", time.Now().Add(10*time.Minute).Format(ini.DateFormat))
+	v.Set("internal."+backoffName+"_backoff_retries", "1")
 	v.SetDefault("sync-offline-activity", 1000)
 	v.Set("api-url", testServerURL)
 	v.Set("entity", "testdata/main.go")
@@ -5,6 +5,10 @@ import (
 	"fmt"
 
 	paramscmd "github.com/wakatime/wakatime-cli/cmd/params"
+	sinkcmd "github.com/wakatime/wakatime-cli/cmd/sink"
+	"github.com/wakatime/wakatime-cli/pkg/activity"
+	"github.com/wakatime/wakatime-cli/pkg/cache"
+	"github.com/wakatime/wakatime-cli/pkg/category"
 	"github.com/wakatime/wakatime-cli/pkg/deps"
 	"github.com/wakatime/wakatime-cli/pkg/filestats"
 	"github.com/wakatime/wakatime-cli/pkg/filter"
@@ -13,7 +17,10 @@ import (
 	"github.com/wakatime/wakatime-cli/pkg/log"
 	"github.com/wakatime/wakatime-cli/pkg/offline"
 	"github.com/wakatime/wakatime-cli/pkg/project"
+	"github.com/wakatime/wakatime-cli/pkg/pseudonym"
 	"github.com/wakatime/wakatime-cli/pkg/remote"
+	"github.com/wakatime/wakatime-cli/pkg/scrub"
+	"github.com/wakatime/wakatime-cli/pkg/sink"
 
 	"github.com/spf13/viper"
 )
@@ -41,13 +48,33 @@ func SaveHeartbeats(v *viper.Viper, heartbeats []heartbeat.Heartbeat, queueFilep
 		heartbeats = buildHeartbeats(params)
 	}
 
-	handleOpts := initHandleOptions(params)
+	pool := remote.NewPool()
+	defer pool.Close()
+
+	handleOpts := initHandleOptions(params, pool)
+
+	activityFilepath, err := activity.Filepath()
+	if err != nil {
+		log.Warnf("failed to load activity filepath: %s", err)
+	}
+
+	if params.Offline.ActivityFile != "" {
+		activityFilepath = params.Offline.ActivityFile
+	}
+
+	if activityFilepath != "" {
+		handleOpts = append(handleOpts, activity.WithStore(activityFilepath))
+	}
+
+	if sinks := sinkcmd.NewSinks(v, params); len(sinks) > 0 {
+		handleOpts = append(handleOpts, sink.WithFanOut(sinks))
+	}
 
 	if params.Offline.QueueFile != "" {
 		queueFilepath = params.Offline.QueueFile
 	}
 
-	handleOpts = append(handleOpts, offline.WithQueue(queueFilepath))
+	handleOpts = append(handleOpts, offline.WithQueue(queueFilepath, offline.WithRetention(params.Offline.Retention)))
 
 	sender := offline.Noop{}
 	handle := heartbeat.NewHandle(sender, handleOpts...)
@@ -72,6 +99,7 @@ func loadParams(v *viper.Viper) (paramscmd.Params, error) {
 		API:       paramAPI,
 		Heartbeat: paramHeartbeat,
 		Offline:   paramscmd.LoadOfflineParams(v),
+		Sinks:     paramscmd.LoadSinkParams(v),
 	}, nil
 }
 
@@ -81,11 +109,13 @@ func buildHeartbeats(params paramscmd.Params) []heartbeat.Heartbeat {
 	userAgent := heartbeat.UserAgent(params.API.Plugin)
 
 	heartbeats = append(heartbeats, heartbeat.New(
+		params.Heartbeat.AILineChanges,
 		params.Heartbeat.Project.BranchAlternate,
 		params.Heartbeat.Category,
 		params.Heartbeat.CursorPosition,
 		params.Heartbeat.Entity,
 		params.Heartbeat.EntityType,
+		params.Heartbeat.HumanLineChanges,
 		params.Heartbeat.IsUnsavedEntity,
 		params.Heartbeat.IsWrite,
 		params.Heartbeat.Language,
@@ -108,11 +138,13 @@ func buildHeartbeats(params paramscmd.Params) []heartbeat.Heartbeat {
 
 		for _, h := range params.Heartbeat.ExtraHeartbeats {
 			heartbeats = append(heartbeats, heartbeat.New(
+				h.AILineChanges,
 				h.BranchAlternate,
 				h.Category,
 				h.CursorPosition,
 				h.Entity,
 				h.EntityType,
+				h.HumanLineChanges,
 				h.IsUnsavedEntity,
 				h.IsWrite,
 				h.Language,
@@ -135,7 +167,19 @@ func buildHeartbeats(params paramscmd.Params) []heartbeat.Heartbeat {
 	return heartbeats
 }
 
-func initHandleOptions(params paramscmd.Params) []heartbeat.HandleOption {
+// initHandleOptions returns the handle options of the heartbeat processing
+// pipeline. Connections to remote hosts are shared by its stages through pool.
+func initHandleOptions(params paramscmd.Params, pool *remote.Pool) []heartbeat.HandleOption {
+	var detectionCache *cache.Cache
+	if params.Heartbeat.DetectionCache {
+		detectionCache = cache.NewDefault()
+	}
+
+	var pseudonymizer heartbeat.Pseudonymizer
+	if params.Heartbeat.Sanitize.Pseudonymize {
+		pseudonymizer = pseudonym.NewDefault(params.Heartbeat.Sanitize.PseudonymSecret)
+	}
+
 	return []heartbeat.HandleOption{
 		heartbeat.WithFormatting(),
 		heartbeat.WithEntityModifier(),
@@ -144,22 +188,38 @@ func initHandleOptions(params paramscmd.Params) []heartbeat.HandleOption {
 			Include:                    params.Heartbeat.Filter.Include,
 			IncludeOnlyWithProjectFile: params.Heartbeat.Filter.IncludeOnlyWithProjectFile,
 		}),
-		remote.WithDetection(),
-		filestats.WithDetection(),
+		remote.WithDetection(remote.Config{
+			HeadOnly: params.Heartbeat.RemoteHeadOnly,
+			Pool:     pool,
+		}),
+		filestats.WithDetection(filestats.Config{
+			Cache: detectionCache,
+		}),
 		language.WithDetection(language.Config{
+			Cache:         detectionCache,
 			GuessLanguage: params.Heartbeat.GuessLanguage,
 		}),
 		deps.WithDetection(deps.Config{
 			FilePatterns: params.Heartbeat.Sanitize.HideFileNames,
+			Manifests:    params.Heartbeat.ManifestDeps,
 		}),
 		project.WithDetection(project.Config{
+			Cache:                detectionCache,
 			HideProjectNames:     params.Heartbeat.Sanitize.HideProjectNames,
 			MapPatterns:          params.Heartbeat.Project.MapPatterns,
+			PreferredGitRemote:   params.Heartbeat.Project.PreferredGitRemote,
 			ProjectFromGitRemote: params.Heartbeat.Project.ProjectFromGitRemote,
+			Pseudonymizer:        pseudonymizer,
+			RemotePool:           pool,
 			Submodule: project.Submodule{
 				DisabledPatterns: params.Heartbeat.Project.SubmodulesDisabled,
 				MapPatterns:      params.Heartbeat.Project.SubmoduleMapPatterns,
 			},
+			Subprojects: params.Heartbeat.Project.Subprojects,
+		}),
+		category.WithInference(category.Config{
+			Conventions: params.Heartbeat.CategoryInference.Conventions,
+			MapPatterns: params.Heartbeat.CategoryInference.MapPatterns,
 		}),
 		project.WithFiltering(project.FilterConfig{
 			ExcludeUnknownProject: params.Heartbeat.Filter.ExcludeUnknownProject,
@@ -168,7 +228,14 @@ func initHandleOptions(params paramscmd.Params) []heartbeat.HandleOption {
 			BranchPatterns:    params.Heartbeat.Sanitize.HideBranchNames,
 			FilePatterns:      params.Heartbeat.Sanitize.HideFileNames,
 			HideProjectFolder: params.Heartbeat.Sanitize.HideProjectFolder,
+			Hostname:          params.API.Hostname,
+			PrivacyProfiles:   params.Heartbeat.Sanitize.PrivacyProfiles,
 			ProjectPatterns:   params.Heartbeat.Sanitize.HideProjectNames,
+			Pseudonymizer:     pseudonymizer,
+		}),
+		scrub.WithScrubbing(scrub.Config{
+			Detectors: params.Heartbeat.Scrub.Detectors,
+			Patterns:  params.Heartbeat.Scrub.Patterns,
 		}),
 		remote.WithCleanup(),
 		filter.WithLengthValidator(),
@@ -0,0 +1,40 @@
+package offlinedelete
+
+import (
+	"fmt"
+
+	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/offline"
+
+	"github.com/spf13/viper"
+)
+
+// Run executes the offline-delete command.
+func Run(v *viper.Viper) (int, error) {
+	queueFilepath, err := offline.QueueFilepath()
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf(
+			"failed to load offline queue filepath: %s",
+			err,
+		)
+	}
+
+	if p := params.LoadOfflineParams(v); p.QueueFile != "" {
+		queueFilepath = p.QueueFile
+	}
+
+	ids := v.GetStringSlice("offline-delete")
+
+	deleted, err := offline.DeleteHeartbeats(queueFilepath, ids)
+	if err != nil {
+		fmt.Println(err)
+		return exitcode.ErrGeneric, fmt.Errorf("failed to delete offline heartbeats: %w", err)
+	}
+
+	log.Debugf("deleted %d of %d offline heartbeat(s)", deleted, len(ids))
+	fmt.Println(deleted)
+
+	return exitcode.Success, nil
+}
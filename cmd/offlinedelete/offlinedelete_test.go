@@ -0,0 +1,103 @@
+package offlinedelete_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/cmd/offlinedelete"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/offline"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestOfflineDelete(t *testing.T) {
+	// setup offline queue
+	f, err := os.CreateTemp(t.TempDir(), "")
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	db, err := bolt.Open(f.Name(), 0600, nil)
+	require.NoError(t, err)
+
+	dataGo, err := os.ReadFile("testdata/heartbeat_go.json")
+	require.NoError(t, err)
+
+	dataPy, err := os.ReadFile("testdata/heartbeat_py.json")
+	require.NoError(t, err)
+
+	insertHeartbeatRecords(t, db, "heartbeats", []heartbeatRecord{
+		{
+			ID:        "1592868367.219124-file-coding-wakatime-cli-heartbeat-/tmp/main.go-true",
+			Heartbeat: string(dataGo),
+		},
+		{
+			ID:        "1592868386.079084-file-debugging-wakatime-summary-/tmp/main.py-false",
+			Heartbeat: string(dataPy),
+		},
+		{
+			ID:        "corrupted",
+			Heartbeat: "{invalid",
+		},
+	})
+
+	db.Close()
+
+	v := viper.New()
+	v.Set("offline-delete", []string{
+		"1592868367.219124-file-coding-wakatime-cli-heartbeat-/tmp/main.go-true",
+		"corrupted",
+	})
+	v.Set("offline-queue-file", f.Name())
+
+	code, err := offlinedelete.Run(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, exitcode.Success, code)
+
+	queued, err := offline.ListHeartbeats(f.Name(), offline.Filter{})
+	require.NoError(t, err)
+
+	require.Len(t, queued, 1)
+	assert.Equal(t, "1592868386.079084-file-debugging-wakatime-summary-/tmp/main.py-false", queued[0].ID)
+
+	count, err := offline.CountHeartbeats(f.Name())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, count)
+}
+
+type heartbeatRecord struct {
+	ID        string
+	Heartbeat string
+}
+
+func insertHeartbeatRecords(t *testing.T, db *bolt.DB, bucket string, hh []heartbeatRecord) {
+	for _, h := range hh {
+		insertHeartbeatRecord(t, db, bucket, h)
+	}
+}
+
+func insertHeartbeatRecord(t *testing.T, db *bolt.DB, bucket string, h heartbeatRecord) {
+	t.Helper()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return fmt.Errorf("failed to create bucket: %s", err)
+		}
+
+		err = b.Put([]byte(h.ID), []byte(h.Heartbeat))
+		if err != nil {
+			return fmt.Errorf("failed put heartbeat: %s", err)
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+}
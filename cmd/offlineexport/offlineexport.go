@@ -0,0 +1,61 @@
+package offlineexport
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/offline"
+
+	"github.com/spf13/viper"
+)
+
+// Run executes the offline-export command. Heartbeats are written to stdout
+// when the export file is "-".
+func Run(v *viper.Viper) (int, error) {
+	queueFilepath, err := offline.QueueFilepath()
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf(
+			"failed to load offline queue filepath: %s",
+			err,
+		)
+	}
+
+	if p := params.LoadOfflineParams(v); p.QueueFile != "" {
+		queueFilepath = p.QueueFile
+	}
+
+	filter, err := params.LoadOfflineFilterParams(v)
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf("failed to load offline filter parameters: %w", err)
+	}
+
+	var w io.Writer = os.Stdout
+
+	if fp := v.GetString("offline-export"); fp != "-" {
+		f, err := os.Create(fp) // nolint:gosec
+		if err != nil {
+			return exitcode.ErrGeneric, fmt.Errorf("failed to create export file: %w", err)
+		}
+
+		defer func() {
+			if err := f.Close(); err != nil {
+				log.Warnf("failed to close export file: %s", err)
+			}
+		}()
+
+		w = f
+	}
+
+	count, err := offline.ExportHeartbeats(queueFilepath, w, filter)
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf("failed to export offline heartbeats: %w", err)
+	}
+
+	log.Debugf("exported %d offline heartbeat(s)", count)
+
+	return exitcode.Success, nil
+}
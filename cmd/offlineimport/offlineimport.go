@@ -0,0 +1,57 @@
+package offlineimport
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/offline"
+
+	"github.com/spf13/viper"
+)
+
+// Run executes the offline-import command. Heartbeats are read from stdin
+// when the import file is "-".
+func Run(v *viper.Viper) (int, error) {
+	queueFilepath, err := offline.QueueFilepath()
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf(
+			"failed to load offline queue filepath: %s",
+			err,
+		)
+	}
+
+	if p := params.LoadOfflineParams(v); p.QueueFile != "" {
+		queueFilepath = p.QueueFile
+	}
+
+	var r io.Reader = os.Stdin
+
+	if fp := v.GetString("offline-import"); fp != "-" {
+		f, err := os.Open(fp) // nolint:gosec
+		if err != nil {
+			return exitcode.ErrGeneric, fmt.Errorf("failed to open import file: %w", err)
+		}
+
+		defer func() {
+			if err := f.Close(); err != nil {
+				log.Debugf("failed to close import file: %s", err)
+			}
+		}()
+
+		r = f
+	}
+
+	count, err := offline.ImportHeartbeats(queueFilepath, r)
+	if err != nil {
+		fmt.Println(err)
+		return exitcode.ErrGeneric, fmt.Errorf("failed to import offline heartbeats: %w", err)
+	}
+
+	fmt.Println(count)
+
+	return exitcode.Success, nil
+}
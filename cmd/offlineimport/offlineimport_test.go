@@ -0,0 +1,74 @@
+package offlineimport_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/cmd/offlineimport"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/offline"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOfflineImport(t *testing.T) {
+	queueFile := filepath.Join(t.TempDir(), "offline.bdb")
+
+	v := viper.New()
+	v.Set("offline-import", "testdata/heartbeats.jsonl")
+	v.Set("offline-queue-file", queueFile)
+
+	code, err := offlineimport.Run(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, exitcode.Success, code)
+
+	queued, err := offline.ListHeartbeats(queueFile, offline.Filter{})
+	require.NoError(t, err)
+
+	require.Len(t, queued, 2)
+	assert.Equal(t, "1592868367.219124-file-coding-wakatime-cli-heartbeat-/tmp/main.go-true", queued[0].ID)
+	assert.Equal(t, "1592868386.079084-file-debugging-wakatime-summary-/tmp/main.py-false", queued[1].ID)
+}
+
+func TestOfflineImport_FileNotFound(t *testing.T) {
+	v := viper.New()
+	v.Set("offline-import", "testdata/non-existing.jsonl")
+	v.Set("offline-queue-file", filepath.Join(t.TempDir(), "offline.bdb"))
+
+	code, err := offlineimport.Run(v)
+	require.Error(t, err)
+
+	assert.Equal(t, exitcode.ErrGeneric, code)
+}
+
+func TestOfflineImport_Stdin(t *testing.T) {
+	queueFile := filepath.Join(t.TempDir(), "offline.bdb")
+
+	f, err := os.Open("testdata/heartbeats.jsonl")
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	stdin := os.Stdin // keep backup of the real stdin
+	os.Stdin = f
+
+	defer func() { os.Stdin = stdin }()
+
+	v := viper.New()
+	v.Set("offline-import", "-")
+	v.Set("offline-queue-file", queueFile)
+
+	code, err := offlineimport.Run(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, exitcode.Success, code)
+
+	count, err := offline.CountHeartbeats(queueFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, count)
+}
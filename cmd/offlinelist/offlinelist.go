@@ -0,0 +1,53 @@
+package offlinelist
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/offline"
+
+	"github.com/spf13/viper"
+)
+
+// Run executes the offline-list command.
+func Run(v *viper.Viper) (int, error) {
+	queueFilepath, err := offline.QueueFilepath()
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf(
+			"failed to load offline queue filepath: %s",
+			err,
+		)
+	}
+
+	if p := params.LoadOfflineParams(v); p.QueueFile != "" {
+		queueFilepath = p.QueueFile
+	}
+
+	filter, err := params.LoadOfflineFilterParams(v)
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf("failed to load offline filter parameters: %w", err)
+	}
+
+	queued, err := offline.ListHeartbeats(queueFilepath, filter)
+	if err != nil {
+		fmt.Println(err)
+		return exitcode.ErrGeneric, fmt.Errorf("failed to list offline heartbeats: %w", err)
+	}
+
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+
+	for _, q := range queued {
+		if err := encoder.Encode(q); err != nil {
+			return exitcode.ErrGeneric, fmt.Errorf("failed to json marshal offline heartbeat: %w", err)
+		}
+	}
+
+	fmt.Print(buffer.String())
+
+	return exitcode.Success, nil
+}
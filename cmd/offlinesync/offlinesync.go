@@ -5,6 +5,7 @@ import (
 
 	cmdapi "github.com/wakatime/wakatime-cli/cmd/api"
 	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/api"
 	"github.com/wakatime/wakatime-cli/pkg/apikey"
 	"github.com/wakatime/wakatime-cli/pkg/exitcode"
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
@@ -52,7 +53,12 @@ func SyncOfflineActivity(v *viper.Viper, queueFilepath string) error {
 		return fmt.Errorf("failed to load API parameters: %w", err)
 	}
 
-	apiClient, err := cmdapi.NewClientWithoutAuth(paramAPI)
+	// send heartbeats of different api keys concurrently, in batches as large
+	// as the sync runs of a single key
+	apiClient, err := cmdapi.NewClientWithoutAuth(paramAPI,
+		api.WithBatchSize(offline.SendLimit),
+		api.WithConcurrency(paramOffline.SyncConcurrency),
+	)
 	if err != nil {
 		return fmt.Errorf("failed to initialize api client: %w", err)
 	}
@@ -62,7 +68,11 @@ func SyncOfflineActivity(v *viper.Viper, queueFilepath string) error {
 	}
 
 	handle := heartbeat.NewHandle(apiClient,
-		offline.WithSync(queueFilepath, paramOffline.SyncMax),
+		offline.WithSync(queueFilepath, paramOffline.SyncMax,
+			offline.WithCompaction(paramOffline.CompactionWindow),
+			offline.WithConcurrency(paramOffline.SyncConcurrency),
+			offline.WithRetention(paramOffline.Retention),
+		),
 		apikey.WithReplacing(apikey.Config{
 			DefaultAPIKey: paramAPI.Key,
 			MapPatterns:   paramAPI.KeyPatterns,
@@ -15,12 +15,16 @@ import (
 	paramscmd "github.com/wakatime/wakatime-cli/cmd/params"
 	"github.com/wakatime/wakatime-cli/pkg/api"
 	"github.com/wakatime/wakatime-cli/pkg/apikey"
+	"github.com/wakatime/wakatime-cli/pkg/backoff"
+	"github.com/wakatime/wakatime-cli/pkg/category"
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	inipkg "github.com/wakatime/wakatime-cli/pkg/ini"
 	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/offline"
 	"github.com/wakatime/wakatime-cli/pkg/output"
 	"github.com/wakatime/wakatime-cli/pkg/project"
 	"github.com/wakatime/wakatime-cli/pkg/regex"
+	"github.com/wakatime/wakatime-cli/pkg/sink"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -28,6 +32,27 @@ import (
 	"gopkg.in/ini.v1"
 )
 
+func TestLoadParams_AILineChanges(t *testing.T) {
+	v := viper.New()
+	v.Set("entity", "/path/to/file")
+	v.Set("ai-line-changes", 42)
+
+	params, err := paramscmd.LoadHeartbeatParams(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, 42, *params.AILineChanges)
+}
+
+func TestLoadParams_AILineChanges_Unset(t *testing.T) {
+	v := viper.New()
+	v.Set("entity", "/path/to/file")
+
+	params, err := paramscmd.LoadHeartbeatParams(v)
+	require.NoError(t, err)
+
+	assert.Nil(t, params.AILineChanges)
+}
+
 func TestLoadParams_AlternateProject(t *testing.T) {
 	v := viper.New()
 	v.Set("entity", "/path/to/file")
@@ -256,10 +281,12 @@ func TestLoadParams_ExtraHeartbeats(t *testing.T) {
 
 	assert.Equal(t, []heartbeat.Heartbeat{
 		{
+			AILineChanges:     heartbeat.PointerTo(3),
 			Category:          heartbeat.CodingCategory,
 			CursorPosition:    heartbeat.PointerTo(12),
 			Entity:            "testdata/main.go",
 			EntityType:        heartbeat.FileType,
+			HumanLineChanges:  heartbeat.PointerTo(5),
 			IsUnsavedEntity:   true,
 			IsWrite:           heartbeat.PointerTo(true),
 			LanguageAlternate: "Golang",
@@ -328,16 +355,18 @@ func TestLoadParams_ExtraHeartbeats_WithStringValues(t *testing.T) {
 
 	assert.Equal(t, []heartbeat.Heartbeat{
 		{
-			Category:        heartbeat.CodingCategory,
-			CursorPosition:  heartbeat.PointerTo(12),
-			Entity:          "testdata/main.go",
-			EntityType:      heartbeat.FileType,
-			IsUnsavedEntity: true,
-			IsWrite:         heartbeat.PointerTo(true),
-			Language:        params.ExtraHeartbeats[0].Language,
-			Lines:           heartbeat.PointerTo(45),
-			LineNumber:      heartbeat.PointerTo(42),
-			Time:            1585598059,
+			AILineChanges:    heartbeat.PointerTo(3),
+			Category:         heartbeat.CodingCategory,
+			CursorPosition:   heartbeat.PointerTo(12),
+			Entity:           "testdata/main.go",
+			EntityType:       heartbeat.FileType,
+			HumanLineChanges: heartbeat.PointerTo(5),
+			IsUnsavedEntity:  true,
+			IsWrite:          heartbeat.PointerTo(true),
+			Language:         params.ExtraHeartbeats[0].Language,
+			Lines:            heartbeat.PointerTo(45),
+			LineNumber:       heartbeat.PointerTo(42),
+			Time:             1585598059,
 		},
 		{
 			Category:        heartbeat.CodingCategory,
@@ -396,10 +425,12 @@ func TestLoadParams_ExtraHeartbeats_WithEOF(t *testing.T) {
 
 	assert.Equal(t, []heartbeat.Heartbeat{
 		{
+			AILineChanges:     heartbeat.PointerTo(3),
 			Category:          heartbeat.CodingCategory,
 			CursorPosition:    heartbeat.PointerTo(12),
 			Entity:            "testdata/main.go",
 			EntityType:        heartbeat.FileType,
+			HumanLineChanges:  heartbeat.PointerTo(5),
 			IsUnsavedEntity:   true,
 			IsWrite:           heartbeat.PointerTo(true),
 			LanguageAlternate: "Golang",
@@ -500,6 +531,186 @@ func TestLoadHeartbeat_GuessLanguage_Default(t *testing.T) {
 	assert.False(t, params.GuessLanguage)
 }
 
+func TestLoadHeartbeat_DetectionCache(t *testing.T) {
+	tests := map[string]struct {
+		Flag     any
+		Config   any
+		Expected bool
+	}{
+		"default": {
+			Expected: true,
+		},
+		"flag": {
+			Flag:     true,
+			Expected: false,
+		},
+		"config disabled": {
+			Config:   false,
+			Expected: false,
+		},
+		"config enabled": {
+			Config:   true,
+			Expected: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := viper.New()
+			v.Set("entity", "/path/to/file")
+
+			if test.Flag != nil {
+				v.Set("disable-detection-cache", test.Flag)
+			}
+
+			if test.Config != nil {
+				v.Set("settings.detection_cache", test.Config)
+			}
+
+			params, err := paramscmd.LoadHeartbeatParams(v)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, params.DetectionCache)
+		})
+	}
+}
+
+func TestLoadHeartbeat_CategoryInference(t *testing.T) {
+	v := viper.New()
+	v.Set("entity", "/path/to/file")
+	v.Set("infer-category", true)
+	v.Set("category_map.docs/adr/", "planning")
+	v.Set("category_map.^/tmp/", "invalid")
+	v.Set("category_map.[", "coding")
+
+	params, err := paramscmd.LoadHeartbeatParams(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, paramscmd.CategoryInferenceParams{
+		Conventions: true,
+		MapPatterns: []category.MapPattern{
+			{
+				Category: heartbeat.PlanningCategory,
+				Regex:    regex.MustCompile("(?i)docs/adr/"),
+			},
+		},
+	}, params.CategoryInference)
+}
+
+func TestLoadHeartbeat_CategoryInference_FromConfig(t *testing.T) {
+	v := viper.New()
+	v.Set("entity", "/path/to/file")
+	v.Set("settings.infer_category", true)
+
+	params, err := paramscmd.LoadHeartbeatParams(v)
+	require.NoError(t, err)
+
+	assert.True(t, params.CategoryInference.Conventions)
+}
+
+func TestLoadHeartbeat_RemoteHeadOnly(t *testing.T) {
+	v := viper.New()
+	v.Set("entity", "/path/to/file")
+	v.Set("settings.remote_head_only", true)
+
+	params, err := paramscmd.LoadHeartbeatParams(v)
+	require.NoError(t, err)
+
+	assert.True(t, params.RemoteHeadOnly)
+}
+
+func TestLoadHeartbeat_Scrub(t *testing.T) {
+	v := viper.New()
+	v.Set("entity", "/path/to/file")
+	v.Set("settings.scrub_secrets", false)
+	v.Set("settings.scrub_patterns", "\n(?i)ticket-[0-9]+\n\ninternal_[a-z]+\n")
+
+	params, err := paramscmd.LoadHeartbeatParams(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, paramscmd.ScrubParams{
+		Patterns: []regex.Regex{
+			regex.MustCompile("(?i)ticket-[0-9]+"),
+			regex.MustCompile("internal_[a-z]+"),
+		},
+	}, params.Scrub)
+}
+
+func TestLoadHeartbeat_Scrub_Default(t *testing.T) {
+	v := viper.New()
+	v.Set("entity", "/path/to/file")
+
+	params, err := paramscmd.LoadHeartbeatParams(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, paramscmd.ScrubParams{Detectors: true}, params.Scrub)
+}
+
+func TestLoadHeartbeat_Scrub_InvalidPattern(t *testing.T) {
+	v := viper.New()
+	v.Set("entity", "/path/to/file")
+	v.Set("settings.scrub_patterns", "[")
+
+	_, err := paramscmd.LoadHeartbeatParams(v)
+	require.Error(t, err)
+
+	assert.Contains(t, err.Error(), "failed to load scrub params: failed to compile scrub_patterns regex")
+}
+
+func TestLoadHeartbeat_ManifestDeps_FlagTakesPrecedence(t *testing.T) {
+	v := viper.New()
+	v.Set("entity", "/path/to/file")
+	v.Set("manifest-dependencies", true)
+	v.Set("settings.manifest_dependencies", false)
+
+	params, err := paramscmd.LoadHeartbeatParams(v)
+	require.NoError(t, err)
+
+	assert.True(t, params.ManifestDeps)
+}
+
+func TestLoadHeartbeat_ManifestDeps_FromConfig(t *testing.T) {
+	v := viper.New()
+	v.Set("entity", "/path/to/file")
+	v.Set("settings.manifest_dependencies", true)
+
+	params, err := paramscmd.LoadHeartbeatParams(v)
+	require.NoError(t, err)
+
+	assert.True(t, params.ManifestDeps)
+}
+
+func TestLoadHeartbeat_ManifestDeps_Default(t *testing.T) {
+	v := viper.New()
+	v.Set("entity", "/path/to/file")
+
+	params, err := paramscmd.LoadHeartbeatParams(v)
+	require.NoError(t, err)
+
+	assert.False(t, params.ManifestDeps)
+}
+
+func TestLoadParams_HumanLineChanges(t *testing.T) {
+	v := viper.New()
+	v.Set("entity", "/path/to/file")
+	v.Set("human-line-changes", 42)
+
+	params, err := paramscmd.LoadHeartbeatParams(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, 42, *params.HumanLineChanges)
+}
+
+func TestLoadParams_HumanLineChanges_Unset(t *testing.T) {
+	v := viper.New()
+	v.Set("entity", "/path/to/file")
+
+	params, err := paramscmd.LoadHeartbeatParams(v)
+	require.NoError(t, err)
+
+	assert.Nil(t, params.HumanLineChanges)
+}
+
 func TestLoadParams_IsUnsavedEntity(t *testing.T) {
 	v := viper.New()
 	v.Set("entity", "/path/to/file")
@@ -1480,6 +1691,117 @@ func TestLoadParams_SanitizeParams_OverrideProjectPath(t *testing.T) {
 	}, params.Sanitize)
 }
 
+func TestLoadParams_SanitizeParams_PrivacyProfiles(t *testing.T) {
+	v := viper.New()
+	v.Set("entity", "/path/to/file")
+	v.Set("privacy_profile.oss.paths", "^/home/user/oss/")
+	v.Set("privacy_profile.oss.round_time", "true")
+	v.Set("privacy_profile.client.paths", "^/home/user/clients/\n^/work/")
+	v.Set("privacy_profile.client.basename_only", "true")
+	v.Set("privacy_profile.client.max_depth", "2")
+	v.Set("privacy_profile.client.drop_dependencies", "true")
+	v.Set("privacy_profile.client.drop_line_numbers", "true")
+	v.Set("privacy_profile.client.drop_user_agent_details", "true")
+	v.Set("privacy_profile.client.hash_hostname", "true")
+	v.Set("privacy_profile.client.round_time", "false")
+	v.Set("privacy_profile.empty.round_time", "true")
+
+	params, err := paramscmd.LoadHeartbeatParams(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, []heartbeat.PrivacyProfile{
+		{
+			Name: "client",
+			Patterns: []regex.Regex{
+				regex.MustCompile("^/home/user/clients/"),
+				regex.MustCompile("^/work/"),
+			},
+			BasenameOnly:         true,
+			MaxDepth:             2,
+			DropDependencies:     true,
+			DropLineNumbers:      true,
+			DropUserAgentDetails: true,
+			HashHostname:         true,
+		},
+		{
+			Name:      "oss",
+			Patterns:  []regex.Regex{regex.MustCompile("^/home/user/oss/")},
+			RoundTime: true,
+		},
+	}, params.Sanitize.PrivacyProfiles)
+}
+
+func TestLoadParams_SanitizeParams_PrivacyProfiles_Invalid(t *testing.T) {
+	tests := map[string]struct {
+		Option string
+		Value  string
+	}{
+		"invalid bool": {
+			Option: "round_time",
+			Value:  "sometimes",
+		},
+		"invalid max depth": {
+			Option: "max_depth",
+			Value:  "-1",
+		},
+		"invalid paths": {
+			Option: "paths",
+			Value:  "[0-9+",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := viper.New()
+			v.Set("entity", "/path/to/file")
+			v.Set("privacy_profile.client.paths", "^/home/user/clients/")
+			v.Set("privacy_profile.client."+test.Option, test.Value)
+
+			_, err := paramscmd.LoadHeartbeatParams(v)
+			require.Error(t, err)
+
+			assert.Contains(t, err.Error(), `invalid privacy profile "client"`)
+		})
+	}
+}
+
+func TestLoadParams_SanitizeParams_Pseudonymize(t *testing.T) {
+	internalConfig := filepath.Join(t.TempDir(), "wakatime-internal.cfg")
+
+	v := viper.New()
+	v.Set("entity", "/path/to/file")
+	v.Set("internal-config", internalConfig)
+	v.Set("pseudonymize", true)
+
+	params, err := paramscmd.LoadHeartbeatParams(v)
+	require.NoError(t, err)
+
+	assert.True(t, params.Sanitize.Pseudonymize)
+	assert.Len(t, params.Sanitize.PseudonymSecret, 32)
+
+	// secret is saved to the internal config file
+	data, err := os.ReadFile(internalConfig)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(data), "[internal]")
+	assert.Contains(t, string(data), "pseudonym_secret")
+}
+
+func TestLoadParams_SanitizeParams_Pseudonymize_ExistingSecret(t *testing.T) {
+	v := viper.New()
+	v.Set("entity", "/path/to/file")
+	v.Set("settings.pseudonymize", true)
+	v.Set("internal.pseudonym_secret", "0123456789abcdef")
+
+	params, err := paramscmd.LoadHeartbeatParams(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, paramscmd.SanitizeParams{
+		Pseudonymize:    true,
+		PseudonymSecret: []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef},
+	}, params.Sanitize)
+}
+
 func TestLoadParams_SubmodulesDisabled_True(t *testing.T) {
 	tests := map[string]string{
 		"lowercase":       "true",
@@ -1602,6 +1924,26 @@ func TestLoadParams_SubmoduleProjectMap(t *testing.T) {
 	}
 }
 
+func TestLoadParams_Subprojects(t *testing.T) {
+	v := viper.New()
+	v.Set("entity", "/path/to/file")
+	v.Set("subprojects.services/*", "{repo}/{name}")
+	v.Set("subprojects.packages/**", " ")
+
+	params, err := paramscmd.LoadHeartbeatParams(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, []project.SubprojectPattern{
+		{
+			Glob: "packages/**",
+		},
+		{
+			Glob: "services/*",
+			Name: "{repo}/{name}",
+		},
+	}, params.Project.Subprojects)
+}
+
 func TestLoadParams_Plugin(t *testing.T) {
 	v := viper.New()
 	v.Set("key", "00000000-0000-4000-8000-000000000000")
@@ -1676,6 +2018,63 @@ func TestLoad_OfflineDisabled_FromFlag(t *testing.T) {
 	assert.True(t, params.Disabled)
 }
 
+func TestLoad_OfflineActivityFile(t *testing.T) {
+	v := viper.New()
+	v.Set("activity-file", "/path/to/file")
+
+	params := paramscmd.LoadOfflineParams(v)
+
+	assert.Equal(t, "/path/to/file", params.ActivityFile)
+}
+
+func TestLoad_OfflineFilter(t *testing.T) {
+	v := viper.New()
+	v.Set("offline-entity", `\.go$`)
+	v.Set("offline-project", "wakatime-cli")
+	v.Set("offline-start", 1592868367.5)
+	v.Set("offline-end", 1592868400)
+
+	filter, err := paramscmd.LoadOfflineFilterParams(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, regex.MustCompile(`\.go$`).String(), filter.Entity.String())
+	assert.Equal(t, "wakatime-cli", filter.Project)
+	assert.Equal(t, time.Unix(1592868367, 500000000), filter.Start)
+	assert.Equal(t, time.Unix(1592868400, 0), filter.End)
+}
+
+func TestLoad_OfflineFilter_Empty(t *testing.T) {
+	filter, err := paramscmd.LoadOfflineFilterParams(viper.New())
+	require.NoError(t, err)
+
+	assert.Equal(t, offline.Filter{}, filter)
+}
+
+func TestLoad_OfflineFilter_Err(t *testing.T) {
+	tests := map[string]map[string]any{
+		"invalid entity regex": {
+			"offline-entity": "(invalid",
+		},
+		"start after end": {
+			"offline-start": 1592868400,
+			"offline-end":   1592868367,
+		},
+	}
+
+	for name, values := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := viper.New()
+
+			for key, value := range values {
+				v.Set(key, value)
+			}
+
+			_, err := paramscmd.LoadOfflineFilterParams(v)
+			require.Error(t, err)
+		})
+	}
+}
+
 func TestLoad_OfflineQueueFile(t *testing.T) {
 	v := viper.New()
 	v.Set("offline-queue-file", "/path/to/file")
@@ -1685,6 +2084,62 @@ func TestLoad_OfflineQueueFile(t *testing.T) {
 	assert.Equal(t, "/path/to/file", params.QueueFile)
 }
 
+func TestLoad_OfflineRetention(t *testing.T) {
+	v := viper.New()
+	v.Set("settings.offline_compaction_window", "120")
+	v.Set("settings.offline_max_age", "30")
+	v.Set("settings.offline_max_count", "5000")
+	v.Set("settings.offline_max_size", "10")
+
+	params := paramscmd.LoadOfflineParams(v)
+
+	assert.Equal(t, 2*time.Minute, params.CompactionWindow)
+	assert.Equal(t, offline.Retention{
+		MaxAge:   30 * 24 * time.Hour,
+		MaxCount: 5000,
+		MaxSize:  10 * 1024 * 1024,
+	}, params.Retention)
+}
+
+func TestLoad_OfflineRetention_Invalid(t *testing.T) {
+	v := viper.New()
+	v.Set("settings.offline_compaction_window", "two minutes")
+	v.Set("settings.offline_max_age", "-1")
+	v.Set("settings.offline_max_count", "1.5")
+
+	params := paramscmd.LoadOfflineParams(v)
+
+	assert.Zero(t, params.CompactionWindow)
+	assert.True(t, params.Retention.IsZero())
+}
+
+func TestLoad_OfflineSyncConcurrency(t *testing.T) {
+	v := viper.New()
+	v.Set("settings.offline_sync_concurrency", "4")
+
+	params := paramscmd.LoadOfflineParams(v)
+
+	assert.Equal(t, 4, params.SyncConcurrency)
+}
+
+func TestLoad_OfflineSyncConcurrency_Default(t *testing.T) {
+	for name, value := range map[string]string{
+		"unset":    "",
+		"zero":     "0",
+		"negative": "-2",
+		"invalid":  "invalid",
+	} {
+		t.Run(name, func(t *testing.T) {
+			v := viper.New()
+			v.Set("settings.offline_sync_concurrency", value)
+
+			params := paramscmd.LoadOfflineParams(v)
+
+			assert.Equal(t, 1, params.SyncConcurrency)
+		})
+	}
+}
+
 func TestLoad_OfflineSyncMax(t *testing.T) {
 	v := viper.New()
 	v.Set("sync-offline-activity", 42)
@@ -1730,6 +2185,62 @@ func TestLoad_OfflineSyncMax_NonIntegerValue(t *testing.T) {
 	assert.Equal(t, 0, params.SyncMax)
 }
 
+func TestLoad_Sinks(t *testing.T) {
+	v := viper.New()
+	v.Set("sinks.webhook", "https://example.com/webhook")
+	v.Set("sinks.local", "file:///var/log/heartbeats.jsonl")
+	v.Set("sinks.invalid", "ftp://example.com")
+	v.Set("internal.sink_webhook_backoff_at", "2021-08-30T18:50:42-03:00")
+	v.Set("internal.sink_webhook_backoff_retries", "3")
+
+	backoffAt, err := time.Parse(inipkg.DateFormat, "2021-08-30T18:50:42-03:00")
+	require.NoError(t, err)
+
+	sinks := paramscmd.LoadSinkParams(v)
+
+	assert.Equal(t, []sink.Config{
+		{
+			Name:   "local",
+			Type:   sink.JSONLType,
+			Target: "/var/log/heartbeats.jsonl",
+		},
+		{
+			Backoff: backoff.State{
+				At:      backoffAt,
+				Retries: 3,
+			},
+			Name:   "webhook",
+			Type:   sink.WebhookType,
+			Target: "https://example.com/webhook",
+		},
+	}, sinks)
+}
+
+func TestLoad_Serve(t *testing.T) {
+	v := viper.New()
+	v.Set("serve-address", "0.0.0.0:9000")
+	v.Set("serve-db-file", "/path/to/serve.bdb")
+	v.Set("serve_goals.daily", "3600")
+	v.Set("serve_goals.invalid", "one hour")
+
+	params := paramscmd.LoadServeParams(v)
+
+	assert.Equal(t, paramscmd.Serve{
+		Address: "0.0.0.0:9000",
+		DBFile:  "/path/to/serve.bdb",
+		Goals:   map[string]int{"daily": 3600},
+	}, params)
+}
+
+func TestLoad_Serve_Default(t *testing.T) {
+	params := paramscmd.LoadServeParams(viper.New())
+
+	assert.Equal(t, paramscmd.Serve{
+		Address: "localhost:8080",
+		Goals:   map[string]int{},
+	}, params)
+}
+
 func TestLoad_API_APIKey(t *testing.T) {
 	tests := map[string]struct {
 		ViperAPIKey          string
@@ -1873,6 +2384,27 @@ func TestLoadParams_ApiKey_FromVault_Err_Darwin(t *testing.T) {
 	assert.EqualError(t, err, "failed to read api key from vault: exit status 1")
 }
 
+func TestLoad_API_WithoutKey(t *testing.T) {
+	tests := map[string]string{
+		"unset":          "",
+		"invalid format": "not-uuid",
+	}
+
+	for name, value := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := viper.New()
+			v.Set("key", value)
+			v.Set("api-url", "http://localhost:8080")
+
+			params, err := paramscmd.LoadAPIParamsWithoutKey(v)
+			require.NoError(t, err)
+
+			assert.Empty(t, params.Key)
+			assert.Equal(t, "http://localhost:8080", params.URL)
+		})
+	}
+}
+
 func TestLoad_API_APIKeyFromEnv(t *testing.T) {
 	v := viper.New()
 
@@ -2021,12 +2553,26 @@ func TestLoad_APIUrl_InvalidFormat(t *testing.T) {
 	assert.EqualError(t, errauth, `invalid api url: parse "http://in valid": invalid character " " in host name`)
 }
 
-func TestLoad_API_BackoffAt(t *testing.T) {
+func TestLoad_API_Backoff(t *testing.T) {
+	var (
+		apiKey      = "00000000-0000-4000-8000-000000000000"
+		apiURL      = "https://api.wakatime.com/api/v1"
+		patternKey  = "00000000-0000-4000-8000-000000000001"
+		defaultName = backoff.EndpointName(apiURL, apiKey)
+		patternName = backoff.EndpointName(apiURL, patternKey)
+	)
+
 	v := viper.New()
-	v.Set("hostname", "my-computer")
-	v.Set("key", "00000000-0000-4000-8000-000000000000")
-	v.Set("internal.backoff_at", "2021-08-30T18:50:42-03:00")
-	v.Set("internal.backoff_retries", "3")
+	v.Set("key", apiKey)
+	v.Set("settings.api_key", apiKey)
+	v.Set("project_api_key.^/pattern/", patternKey)
+	v.Set("internal."+defaultName+"_backoff_at", "2021-08-30T18:50:42-03:00")
+	v.Set("internal."+defaultName+"_backoff_retries", "3")
+	v.Set("internal."+defaultName+"_backoff_until", "2021-08-30T18:52:42-03:00")
+	v.Set("internal."+patternName+"_backoff_at", "2021-08-30")
+	v.Set("internal."+patternName+"_backoff_retries", "2")
+	// the former global backoff state is ignored
+	v.Set("internal.backoff_retries", "5")
 
 	params, err := paramscmd.LoadAPIParams(v)
 	require.NoError(t, err)
@@ -2034,32 +2580,16 @@ func TestLoad_API_BackoffAt(t *testing.T) {
 	backoffAt, err := time.Parse(inipkg.DateFormat, "2021-08-30T18:50:42-03:00")
 	require.NoError(t, err)
 
-	assert.Equal(t, paramscmd.API{
-		BackoffAt:      backoffAt,
-		BackoffRetries: 3,
-		Key:            "00000000-0000-4000-8000-000000000000",
-		URL:            "https://api.wakatime.com/api/v1",
-		Hostname:       "my-computer",
-	}, params)
-}
-
-func TestLoad_API_BackoffAtErr(t *testing.T) {
-	v := viper.New()
-	v.Set("hostname", "my-computer")
-	v.Set("key", "00000000-0000-4000-8000-000000000000")
-	v.Set("internal.backoff_at", "2021-08-30")
-	v.Set("internal.backoff_retries", "2")
-
-	params, err := paramscmd.LoadAPIParams(v)
-	require.NoError(t, err)
-
-	assert.Equal(t, paramscmd.API{
-		BackoffAt:      time.Time{},
-		BackoffRetries: 2,
-		Key:            "00000000-0000-4000-8000-000000000000",
-		URL:            "https://api.wakatime.com/api/v1",
-		Hostname:       "my-computer",
-	}, params)
+	assert.Equal(t, map[string]backoff.State{
+		apiKey: {
+			At:      backoffAt,
+			Retries: 3,
+			Until:   backoffAt.Add(2 * time.Minute),
+		},
+		patternKey: {
+			Retries: 2,
+		},
+	}, params.Backoff)
 }
 
 func TestLoad_API_Plugin(t *testing.T) {
@@ -2406,8 +2936,10 @@ func TestAPI_String(t *testing.T) {
 	require.NoError(t, err)
 
 	api := paramscmd.API{
-		BackoffAt:        backoffat,
-		BackoffRetries:   5,
+		Backoff: map[string]backoff.State{
+			"00000000-0000-4000-8000-000000000000": {At: backoffat, Retries: 5},
+			"00000000-0000-4000-8000-000000000001": {},
+		},
 		DisableSSLVerify: true,
 		Hostname:         "my-machine",
 		Key:              "00000000-0000-4000-8000-000000000000",
@@ -2426,8 +2958,8 @@ func TestAPI_String(t *testing.T) {
 
 	assert.Equal(
 		t,
-		"api key: '<hidden>0000', api url: 'https://example.org:23', backoff at: '2021-08-30T18:50:42-03:00',"+
-			" backoff retries: 5, hostname: 'my-machine', key patterns: '[{<hidden>0001 ^/api/v1/}]', plugin: 'my-plugin',"+
+		"api key: '<hidden>0000', api url: 'https://example.org:23', endpoints with backoff: 1,"+
+			" hostname: 'my-machine', key patterns: '[{<hidden>0001 ^/api/v1/}]', plugin: 'my-plugin',"+
 			" proxy url: 'https://example.org:23', timeout: 10s, disable ssl verify: true,"+
 			" ssl cert filepath: '/path/to/cert.pem'",
 		api.String(),
@@ -2452,49 +2984,74 @@ func TestFilterParams_String(t *testing.T) {
 
 func TestHeartbeat_String(t *testing.T) {
 	heartbeat := paramscmd.Heartbeat{
-		Category:        heartbeat.CodingCategory,
-		CursorPosition:  heartbeat.PointerTo(15),
-		Entity:          "path/to/entity.go",
-		EntityType:      heartbeat.FileType,
-		ExtraHeartbeats: make([]heartbeat.Heartbeat, 3),
-		GuessLanguage:   true,
-		IsUnsavedEntity: true,
-		IsWrite:         heartbeat.PointerTo(true),
-		Language:        heartbeat.PointerTo("Golang"),
-		LineAdditions:   heartbeat.PointerTo(123),
-		LineDeletions:   heartbeat.PointerTo(456),
-		LineNumber:      heartbeat.PointerTo(4),
-		LinesInFile:     heartbeat.PointerTo(56),
-		Time:            1585598059,
+		AILineChanges:    heartbeat.PointerTo(78),
+		Category:         heartbeat.CodingCategory,
+		CursorPosition:   heartbeat.PointerTo(15),
+		DetectionCache:   true,
+		DryRun:           true,
+		Entity:           "path/to/entity.go",
+		EntityType:       heartbeat.FileType,
+		ExtraHeartbeats:  make([]heartbeat.Heartbeat, 3),
+		GuessLanguage:    true,
+		HumanLineChanges: heartbeat.PointerTo(90),
+		IsUnsavedEntity:  true,
+		IsWrite:          heartbeat.PointerTo(true),
+		Language:         heartbeat.PointerTo("Golang"),
+		LineAdditions:    heartbeat.PointerTo(123),
+		LineDeletions:    heartbeat.PointerTo(456),
+		LineNumber:       heartbeat.PointerTo(4),
+		LinesInFile:      heartbeat.PointerTo(56),
+		RemoteHeadOnly:   true,
+		Time:             1585598059,
+		CategoryInference: paramscmd.CategoryInferenceParams{
+			Conventions: true,
+		},
+		Scrub: paramscmd.ScrubParams{
+			Detectors: true,
+		},
 	}
 
 	assert.Equal(
 		t,
-		"category: 'coding', cursor position: '15', entity: 'path/to/entity.go', entity type: 'file',"+
-			" num extra heartbeats: 3, guess language: true, is unsaved entity: true, is write: true,"+
+		"ai line changes: '78', category: 'coding', cursor position: '15', detection cache: true, dry run: true,"+
+			" entity: 'path/to/entity.go', entity type: 'file', num extra heartbeats: 3, guess language: true,"+
+			" human line changes: '90', is unsaved entity: true, is write: true,"+
 			" language: 'Golang', line additions: '123', line deletions: '456', line number: '4',"+
-			" lines in file: '56', time: 1585598059.00000, filter params: (exclude: '[]',"+
+			" lines in file: '56', manifest dependencies: false, remote head only: true, time: 1585598059.00000,"+
+			" category inference params: (conventions: true, map patterns: '[]'), filter params: (exclude: '[]',"+
 			" exclude unknown project: false, include: '[]', include only with"+
 			" project file: false), project params: (alternate: '', branch alternate: '', map patterns:"+
-			" '[]', override: '', git submodules disabled: '[]', git submodule project map: '[]'), sanitize"+
+			" '[]', override: '', git preferred remote: '', git submodules disabled: '[]',"+
+			" git submodule project map: '[]', subprojects: '[]'), sanitize"+
 			" params: (hide branch names: '[]', hide project folder: false, hide file names: '[]',"+
-			" hide project names: '[]', project path override: '')",
+			" hide project names: '[]', project path override: '', privacy profiles: '[]', pseudonymize: false),"+
+			" scrub params: (detectors: true, patterns: '[]')",
 		heartbeat.String(),
 	)
 }
 
 func TestOffline_String(t *testing.T) {
-	offline := paramscmd.Offline{
-		Disabled:  true,
-		PrintMax:  6,
-		QueueFile: "/path/to/queue.file",
-		SyncMax:   12,
+	params := paramscmd.Offline{
+		ActivityFile:     "/path/to/activity.file",
+		CompactionWindow: 2 * time.Minute,
+		Disabled:         true,
+		PrintMax:         6,
+		QueueFile:        "/path/to/queue.file",
+		Retention: offline.Retention{
+			MaxAge:   24 * time.Hour,
+			MaxCount: 1000,
+			MaxSize:  1048576,
+		},
+		SyncConcurrency: 4,
+		SyncMax:         12,
 	}
 
 	assert.Equal(
 		t,
-		"disabled: true, print max: 6, queue file: '/path/to/queue.file', num sync max: 12",
-		offline.String(),
+		"activity file: '/path/to/activity.file', compaction window: 2m0s, disabled: true, print max: 6,"+
+			" queue file: '/path/to/queue.file', retention: (max age: 24h0m0s, max count: 1000, max size: 1048576),"+
+			" sync concurrency: 4, num sync max: 12",
+		params.String(),
 	)
 }
 
@@ -2504,15 +3061,18 @@ func TestProjectParams_String(t *testing.T) {
 		BranchAlternate:      "branch-alternate",
 		MapPatterns:          []project.MapPattern{{Name: "project-1", Regex: regex.MustCompile("^/regex")}},
 		Override:             "override",
+		PreferredGitRemote:   "upstream",
 		SubmodulesDisabled:   []regex.Regex{regexp.MustCompile(".*")},
 		SubmoduleMapPatterns: []project.MapPattern{{Name: "awesome-project", Regex: regex.MustCompile("^/regex")}},
+		Subprojects:          []project.SubprojectPattern{{Glob: "services/*", Name: "{repo}/{name}"}},
 	}
 
 	assert.Equal(
 		t,
 		"alternate: 'alternate', branch alternate: 'branch-alternate',"+
-			" map patterns: '[{project-1 ^/regex}]', override: 'override',"+
-			" git submodules disabled: '[.*]', git submodule project map: '[{awesome-project ^/regex}]'",
+			" map patterns: '[{project-1 ^/regex}]', override: 'override', git preferred remote: 'upstream',"+
+			" git submodules disabled: '[.*]', git submodule project map: '[{awesome-project ^/regex}]',"+
+			" subprojects: '[{services/* {repo}/{name}}]'",
 		projectparams.String(),
 	)
 }
@@ -2528,6 +3088,17 @@ func TestLoadParams_ProjectFromGitRemote(t *testing.T) {
 	assert.True(t, params.Project.ProjectFromGitRemote)
 }
 
+func TestLoadParams_PreferredGitRemote(t *testing.T) {
+	v := viper.New()
+	v.Set("git.preferred_remote", "upstream")
+	v.Set("entity", "/path/to/file")
+
+	params, err := paramscmd.LoadHeartbeatParams(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, "upstream", params.Project.PreferredGitRemote)
+}
+
 func TestSanitizeParams_String(t *testing.T) {
 	sanitizeparams := paramscmd.SanitizeParams{
 		HideBranchNames:     []regex.Regex{regex.MustCompile("^/hide")},
@@ -2535,12 +3106,21 @@ func TestSanitizeParams_String(t *testing.T) {
 		HideFileNames:       []regex.Regex{regex.MustCompile("^/hide")},
 		HideProjectNames:    []regex.Regex{regex.MustCompile("^/hide")},
 		ProjectPathOverride: "path/to/project",
+		PrivacyProfiles: []heartbeat.PrivacyProfile{
+			{
+				Name:     "client",
+				Patterns: []regex.Regex{regex.MustCompile("^/clients/")},
+			},
+		},
+		Pseudonymize:    true,
+		PseudonymSecret: []byte("secret"),
 	}
 
 	assert.Equal(
 		t,
 		"hide branch names: '[^/hide]', hide project folder: true, hide file names: '[^/hide]',"+
-			" hide project names: '[^/hide]', project path override: 'path/to/project'",
+			" hide project names: '[^/hide]', project path override: 'path/to/project',"+
+			" privacy profiles: '[client]', pseudonymize: true",
 		sanitizeparams.String(),
 	)
 }
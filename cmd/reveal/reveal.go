@@ -0,0 +1,49 @@
+package reveal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/pseudonym"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
+
+	"github.com/spf13/viper"
+)
+
+// Run executes the reveal command.
+func Run(v *viper.Viper) (int, error) {
+	fp, err := pseudonym.Filepath()
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf("failed to get pseudonyms filepath: %s", err)
+	}
+
+	output, err := Reveal(vipertools.GetString(v, "reveal"), fp)
+	if err != nil {
+		return exitcode.ErrGeneric, err
+	}
+
+	fmt.Println(output)
+
+	return exitcode.Success, nil
+}
+
+// Reveal returns text with all pseudonyms recorded in the passed in db file
+// replaced by their original names.
+func Reveal(text, dbFilepath string) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return "", errors.New("nothing to reveal")
+	}
+
+	revealed, count, err := pseudonym.Reveal(dbFilepath, text)
+	if err != nil {
+		return "", fmt.Errorf("failed to reveal pseudonyms: %s", err)
+	}
+
+	if count == 0 {
+		return "", errors.New("no known pseudonym found")
+	}
+
+	return revealed, nil
+}
@@ -0,0 +1,39 @@
+package reveal_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/cmd/reveal"
+	"github.com/wakatime/wakatime-cli/pkg/pseudonym"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReveal(t *testing.T) {
+	dbFilepath := filepath.Join(t.TempDir(), "pseudonyms.bdb")
+
+	token := pseudonym.New([]byte("secret"), dbFilepath).Pseudonymize("/path/to/main")
+
+	output, err := reveal.Reveal(token+".go", dbFilepath)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/path/to/main.go", output)
+}
+
+func TestReveal_NoKnownPseudonym(t *testing.T) {
+	dbFilepath := filepath.Join(t.TempDir(), "pseudonyms.bdb")
+
+	_ = pseudonym.New([]byte("secret"), dbFilepath).Pseudonymize("/path/to/main.go")
+
+	_, err := reveal.Reveal("HIDDEN.go", dbFilepath)
+
+	assert.EqualError(t, err, "no known pseudonym found")
+}
+
+func TestReveal_Empty(t *testing.T) {
+	_, err := reveal.Reveal("  ", filepath.Join(t.TempDir(), "pseudonyms.bdb"))
+
+	assert.EqualError(t, err, "nothing to reveal")
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	cmdapi "github.com/wakatime/wakatime-cli/cmd/api"
+	"github.com/wakatime/wakatime-cli/cmd/backoffstatus"
 	"github.com/wakatime/wakatime-cli/cmd/configread"
 	"github.com/wakatime/wakatime-cli/cmd/configwrite"
 	"github.com/wakatime/wakatime-cli/cmd/fileexperts"
@@ -17,9 +18,16 @@ import (
 	"github.com/wakatime/wakatime-cli/cmd/logfile"
 	cmdoffline "github.com/wakatime/wakatime-cli/cmd/offline"
 	"github.com/wakatime/wakatime-cli/cmd/offlinecount"
+	"github.com/wakatime/wakatime-cli/cmd/offlinedelete"
+	"github.com/wakatime/wakatime-cli/cmd/offlineexport"
+	"github.com/wakatime/wakatime-cli/cmd/offlineimport"
+	"github.com/wakatime/wakatime-cli/cmd/offlinelist"
 	"github.com/wakatime/wakatime-cli/cmd/offlineprint"
 	"github.com/wakatime/wakatime-cli/cmd/offlinesync"
 	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/cmd/reveal"
+	"github.com/wakatime/wakatime-cli/cmd/serve"
+	cmdsummary "github.com/wakatime/wakatime-cli/cmd/summary"
 	"github.com/wakatime/wakatime-cli/cmd/today"
 	"github.com/wakatime/wakatime-cli/cmd/todaygoal"
 	"github.com/wakatime/wakatime-cli/pkg/diagnostic"
@@ -101,6 +109,12 @@ func Run(cmd *cobra.Command, v *viper.Viper) {
 		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, runVersion, shutdown)
 	}
 
+	if v.GetBool("backoff-status") {
+		log.Debugln("command: backoff-status")
+
+		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, backoffstatus.Run, shutdown)
+	}
+
 	if v.IsSet("config-read") {
 		log.Debugln("command: config-read")
 
@@ -113,12 +127,24 @@ func Run(cmd *cobra.Command, v *viper.Viper) {
 		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, configwrite.Run, shutdown)
 	}
 
+	if v.IsSet("reveal") {
+		log.Debugln("command: reveal")
+
+		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, reveal.Run, shutdown)
+	}
+
 	if v.GetBool("today") {
 		log.Debugln("command: today")
 
 		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, today.Run, shutdown)
 	}
 
+	if v.GetBool("summary") {
+		log.Debugln("command: summary")
+
+		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, cmdsummary.Run, shutdown)
+	}
+
 	if v.IsSet("today-goal") {
 		log.Debugln("command: today-goal")
 
@@ -131,6 +157,18 @@ func Run(cmd *cobra.Command, v *viper.Viper) {
 		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, fileexperts.Run, shutdown)
 	}
 
+	if v.GetBool("serve") {
+		log.Debugln("command: serve")
+
+		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, serve.Run, shutdown)
+	}
+
+	if v.GetBool("explain") {
+		log.Debugln("command: explain")
+
+		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, cmdheartbeat.RunExplain, shutdown)
+	}
+
 	if v.IsSet("entity") {
 		log.Debugln("command: heartbeat")
 
@@ -149,6 +187,30 @@ func Run(cmd *cobra.Command, v *viper.Viper) {
 		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, offlinecount.Run, shutdown)
 	}
 
+	if v.GetBool("offline-list") {
+		log.Debugln("command: offline-list")
+
+		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, offlinelist.Run, shutdown)
+	}
+
+	if v.IsSet("offline-delete") {
+		log.Debugln("command: offline-delete")
+
+		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, offlinedelete.Run, shutdown)
+	}
+
+	if v.IsSet("offline-export") {
+		log.Debugln("command: offline-export")
+
+		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, offlineexport.Run, shutdown)
+	}
+
+	if v.IsSet("offline-import") {
+		log.Debugln("command: offline-import")
+
+		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, offlineimport.Run, shutdown)
+	}
+
 	if v.IsSet("print-offline-heartbeats") {
 		log.Debugln("command: print-offline-heartbeats")
 
@@ -156,11 +218,19 @@ func Run(cmd *cobra.Command, v *viper.Viper) {
 	}
 
 	log.Warnf("one of the following parameters has to be provided: %s", strings.Join([]string{
+		"--backoff-status",
 		"--config-read",
 		"--config-write",
 		"--entity",
 		"--offline-count",
+		"--offline-delete",
+		"--offline-export",
+		"--offline-import",
+		"--offline-list",
 		"--print-offline-heartbeats",
+		"--reveal",
+		"--serve",
+		"--summary",
 		"--sync-offline-activity",
 		"--today",
 		"--today-goal",
@@ -276,10 +346,11 @@ func RunCmd(v *viper.Viper, verbose bool, sendDiagsOnErrors bool, cmd cmdFn, shu
 
 // RunCmdWithOfflineSync runs a command function and exits with the exit code
 // returned by the command function. If command run was successful, it will execute
-// offline sync command afterwards. Will send diagnostic on any errors or panics.
+// offline sync command afterwards, except for dry runs. Will send diagnostic on any
+// errors or panics.
 func RunCmdWithOfflineSync(v *viper.Viper, verbose bool, sendDiagsOnErrors bool, cmd cmdFn, shutdown shutdownFn) {
 	exitCode := runCmd(v, verbose, sendDiagsOnErrors, cmd)
-	if exitCode != exitcode.Success {
+	if exitCode != exitcode.Success || v.GetBool("dry-run") {
 		shutdown()
 
 		os.Exit(exitCode)
@@ -8,15 +8,18 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 	"time"
 
 	cmdheartbeat "github.com/wakatime/wakatime-cli/cmd/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/backoff"
 	"github.com/wakatime/wakatime-cli/pkg/exitcode"
 	"github.com/wakatime/wakatime-cli/pkg/ini"
 	"github.com/wakatime/wakatime-cli/pkg/version"
 
+	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -143,8 +146,10 @@ func TestRunCmd_BackoffLoggedWithVerbose(t *testing.T) {
 	v.Set("key", "00000000-0000-4000-8000-000000000000")
 	v.Set("log-file", logFile.Name())
 	v.Set("offline-queue-file", offlineQueueFile.Name())
-	v.Set("internal.backoff_at", time.Now().Add(10*time.Minute).Format(ini.DateFormat))
-	v.Set("internal.backoff_retries", "1")
+	v.Set("activity-file", filepath.Join(tmpDir, "activity.bdb"))
+	backoffName := backoff.EndpointName(testServerURL, "00000000-0000-4000-8000-000000000000")
+	v.Set("internal."+backoffName+"_backoff_at", time.Now().Add(10*time.Minute).Format(ini.DateFormat))
+	v.Set("internal."+backoffName+"_backoff_retries", "1")
 	v.Set("verbose", verbose)
 
 	SetupLogging(v)
@@ -201,8 +206,10 @@ func TestRunCmd_BackoffNotLogged(t *testing.T) {
 	v.Set("key", "00000000-0000-4000-8000-000000000000")
 	v.Set("log-file", logFile.Name())
 	v.Set("offline-queue-file", offlineQueueFile.Name())
-	v.Set("internal.backoff_at", time.Now().Add(10*time.Minute).Format(ini.DateFormat))
-	v.Set("internal.backoff_retries", "1")
+	v.Set("activity-file", filepath.Join(tmpDir, "activity.bdb"))
+	backoffName := backoff.EndpointName(testServerURL, "00000000-0000-4000-8000-000000000000")
+	v.Set("internal."+backoffName+"_backoff_at", time.Now().Add(10*time.Minute).Format(ini.DateFormat))
+	v.Set("internal."+backoffName+"_backoff_retries", "1")
 	v.Set("verbose", verbose)
 
 	SetupLogging(v)
@@ -252,3 +259,21 @@ func setupTestServer() (string, *http.ServeMux, func()) {
 
 	return srv.URL, router, func() { srv.Close() }
 }
+
+func TestSetFlags_OfflineDelete(t *testing.T) {
+	v := viper.New()
+	cmd := &cobra.Command{}
+
+	setFlags(cmd, v)
+
+	err := cmd.ParseFlags([]string{
+		"--offline-delete", "1592868367.219124-file-coding-wakatime-cli-heartbeat-/tmp/a,b.go-true",
+		"--offline-delete", "1592868386.079084-file-debugging-wakatime-cli-heartbeat-/tmp/main.go-false",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"1592868367.219124-file-coding-wakatime-cli-heartbeat-/tmp/a,b.go-true",
+		"1592868386.079084-file-debugging-wakatime-cli-heartbeat-/tmp/main.go-false",
+	}, v.GetStringSlice("offline-delete"))
+}
@@ -0,0 +1,113 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/server"
+
+	"github.com/spf13/viper"
+)
+
+// shutdownTimeout is the maximum time to wait for pending requests on shutdown.
+const shutdownTimeout = 10 * time.Second
+
+// Run executes the serve command. It blocks until the process receives an
+// interrupt or terminate signal.
+func Run(v *viper.Viper) (int, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := Serve(ctx, v); err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf(
+			"serve failed: %s",
+			err,
+		)
+	}
+
+	log.Debugln("successfully shut down stand-in api server")
+
+	return exitcode.Success, nil
+}
+
+// Serve runs a stand-in api server, serving the endpoints used by the api
+// client from a local bolt db, until the passed in context is done.
+func Serve(ctx context.Context, v *viper.Viper) error {
+	paramServe := params.LoadServeParams(v)
+
+	dbFilepath := paramServe.DBFile
+	if dbFilepath == "" {
+		fp, err := server.Filepath()
+		if err != nil {
+			return fmt.Errorf("failed to load db filepath: %s", err)
+		}
+
+		dbFilepath = fp
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dbFilepath), 0750); err != nil {
+		return fmt.Errorf("failed to create db folder: %s", err)
+	}
+
+	srv, err := server.New(server.Config{
+		DBFilepath: dbFilepath,
+		Goals:      paramServe.Goals,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize server: %s", err)
+	}
+
+	defer func() {
+		if err := srv.Close(); err != nil {
+			log.Warnf("failed to close db file: %s", err)
+		}
+	}()
+
+	listener, err := net.Listen("tcp", paramServe.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %q: %s", paramServe.Address, err)
+	}
+
+	httpServer := &http.Server{
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- httpServer.Serve(listener)
+	}()
+
+	fmt.Printf("Serving api at http://%s%s\n", listener.Addr(), server.BasePath)
+
+	select {
+	case err := <-errs:
+		return fmt.Errorf("failed to serve: %s", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down: %s", err)
+	}
+
+	if err := <-errs; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package serve_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/cmd/serve"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServe(t *testing.T) {
+	dbFile := filepath.Join(t.TempDir(), "nested", "serve.bdb")
+
+	v := viper.New()
+	v.Set("serve-address", "127.0.0.1:0")
+	v.Set("serve-db-file", dbFile)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err := serve.Serve(ctx, v)
+	require.NoError(t, err)
+
+	assert.FileExists(t, dbFile)
+}
+
+func TestServe_InvalidAddress(t *testing.T) {
+	v := viper.New()
+	v.Set("serve-address", "invalid:address:0")
+	v.Set("serve-db-file", filepath.Join(t.TempDir(), "serve.bdb"))
+
+	err := serve.Serve(context.Background(), v)
+	require.Error(t, err)
+
+	assert.Contains(t, err.Error(), "failed to listen on \"invalid:address:0\"")
+}
@@ -0,0 +1,66 @@
+package sink
+
+import (
+	cmdapi "github.com/wakatime/wakatime-cli/cmd/api"
+	paramscmd "github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/apikey"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/sink"
+
+	"github.com/spf13/viper"
+)
+
+// NewSinks initializes all configured additional heartbeat sinks following
+// the passed in parameters. Sinks failing to initialize are skipped.
+func NewSinks(v *viper.Viper, params paramscmd.Params) []sink.Sink {
+	var sinks []sink.Sink
+
+	for _, config := range params.Sinks {
+		sender, err := newSender(params.API, config)
+		if err != nil {
+			log.Warnf("failed to initialize sink %q: %s", config.Name, err)
+			continue
+		}
+
+		queueFilepath, err := sink.QueueFilepath(config.Name)
+		if err != nil {
+			log.Warnf("failed to load offline queue filepath of sink %q: %s", config.Name, err)
+			continue
+		}
+
+		sinks = append(sinks, sink.Sink{
+			Config:    config,
+			QueueFile: queueFilepath,
+			Sender:    sender,
+			SyncOptions: []heartbeat.HandleOption{
+				apikey.WithReplacing(apikey.Config{
+					DefaultAPIKey: params.API.Key,
+					MapPatterns:   params.API.KeyPatterns,
+				}),
+			},
+			V: v,
+		})
+	}
+
+	return sinks
+}
+
+func newSender(paramAPI paramscmd.API, config sink.Config) (heartbeat.Sender, error) {
+	if paramAPI.Timeout <= 0 || paramAPI.Timeout > sink.MaxTimeout {
+		paramAPI.Timeout = sink.MaxTimeout
+	}
+
+	switch config.Type {
+	case sink.APIType:
+		paramAPI.URL = config.Target
+
+		return cmdapi.NewClientWithoutAuth(paramAPI)
+	case sink.JSONLType:
+		return sink.NewJSONL(config.Target), nil
+	case sink.SocketType:
+		return sink.NewSocket(config.Target, paramAPI.Timeout), nil
+	default:
+		return sink.NewWebhook(config.Target, paramAPI.Timeout), nil
+	}
+}
@@ -0,0 +1,65 @@
+package summary
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/activity"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/summary"
+
+	"github.com/spf13/viper"
+)
+
+// Run executes the summary command.
+func Run(v *viper.Viper) (int, error) {
+	output, err := Summary(v)
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf(
+			"summary failed: %s",
+			err,
+		)
+	}
+
+	log.Debugln("successfully computed summary from local activity history")
+	fmt.Println(output)
+
+	return exitcode.Success, nil
+}
+
+// Summary returns a rendered summary of today's coding activity, computed
+// from the local activity history without connecting to the api.
+func Summary(v *viper.Viper) (string, error) {
+	activityFilepath, err := activity.Filepath()
+	if err != nil {
+		return "", fmt.Errorf("failed to load activity filepath: %s", err)
+	}
+
+	if p := params.LoadOfflineParams(v); p.ActivityFile != "" {
+		activityFilepath = p.ActivityFile
+	}
+
+	paramStatusBar, err := params.LoadStatusBarParams(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to load status bar parameters: %w", err)
+	}
+
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	hh, err := activity.Read(activityFilepath, start, now)
+	if err != nil {
+		return "", fmt.Errorf("failed to read activity history: %s", err)
+	}
+
+	s := summary.Compute(hh, start, now)
+
+	output, err := summary.RenderToday(s, paramStatusBar.HideCategories, paramStatusBar.Output)
+	if err != nil {
+		return "", fmt.Errorf("failed generating summary output: %s", err)
+	}
+
+	return output, nil
+}
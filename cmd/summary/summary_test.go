@@ -0,0 +1,100 @@
+package summary_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	cmdsummary "github.com/wakatime/wakatime-cli/cmd/summary"
+	"github.com/wakatime/wakatime-cli/pkg/activity"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "activity.bdb")
+
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	if now.Sub(start) < 5*time.Minute {
+		t.Skip("Skipping because too close to midnight.")
+	}
+
+	// keep heartbeats inside of today, regardless of the time of day the test runs
+	base := float64(start.Unix())
+	if now.Sub(start) > time.Hour {
+		base = float64(now.Add(-time.Hour).Unix())
+	}
+
+	err := activity.Store(fp, []heartbeat.Heartbeat{
+		{
+			Category: heartbeat.CodingCategory,
+			Entity:   "/tmp/main.go",
+			Time:     base,
+		},
+		{
+			Category: heartbeat.CodingCategory,
+			Entity:   "/tmp/main.go",
+			Time:     base + 60,
+		},
+		{
+			Category: heartbeat.DebuggingCategory,
+			Entity:   "/tmp/main.go",
+			Time:     base + 180,
+		},
+		{
+			Category: heartbeat.DebuggingCategory,
+			Entity:   "/tmp/main.go",
+			Time:     base + 181,
+		},
+	})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		HideCategories string
+		Output         string
+		Expected       string
+	}{
+		"categories": {
+			Expected: "3 mins Coding, 1 sec Debugging",
+		},
+		"hide categories": {
+			HideCategories: "true",
+			Expected:       "3 mins",
+		},
+		"json": {
+			Output:   "json",
+			Expected: `{"text":"3 mins","has_team_features":false}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := viper.New()
+			v.Set("activity-file", fp)
+			v.Set("summary", true)
+			v.Set("today-hide-categories", test.HideCategories)
+			v.Set("output", test.Output)
+
+			output, err := cmdsummary.Summary(v)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, output)
+		})
+	}
+}
+
+func TestSummary_Empty(t *testing.T) {
+	v := viper.New()
+	v.Set("activity-file", filepath.Join(t.TempDir(), "activity.bdb"))
+	v.Set("summary", true)
+
+	output, err := cmdsummary.Summary(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, "0 secs", output)
+}
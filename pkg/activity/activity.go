@@ -0,0 +1,164 @@
+package activity
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/ini"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// dbFilename is the default bolt db filename.
+	dbFilename = "activity.bdb"
+	// dbBucket is the standard bolt db bucket name.
+	dbBucket = "activity"
+)
+
+// WithStore initializes and returns a heartbeat handle option, which can be
+// used in a heartbeat processing pipeline to record every heartbeat passing
+// through into the local activity history. Recording happens before sending,
+// so heartbeats are kept regardless of the API being reachable. Failing to
+// record never aborts the pipeline.
+func WithStore(filepath string) heartbeat.HandleOption {
+	return func(next heartbeat.Handle) heartbeat.Handle {
+		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			log.Debugf("execute activity store with file %s", filepath)
+
+			if len(hh) > 0 {
+				if err := Store(filepath, hh); err != nil {
+					log.Warnf("failed to store heartbeats in activity history: %s", err)
+				}
+			}
+
+			return next(hh)
+		}
+	}
+}
+
+// Filepath returns the path for the local activity history db file.
+func Filepath() (string, error) {
+	folder, err := ini.WakaResourcesDir()
+	if err != nil {
+		return "", fmt.Errorf("failed getting resource directory: %s", err)
+	}
+
+	return filepath.Join(folder, dbFilename), nil
+}
+
+// Store appends heartbeats to the local activity history. Storing the
+// same heartbeat twice keeps a single record.
+func Store(filepath string, hh []heartbeat.Heartbeat) error {
+	db, close, err := openDB(filepath)
+	if err != nil {
+		return err
+	}
+
+	defer close()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(dbBucket))
+		if err != nil {
+			return fmt.Errorf("failed to create/load bucket: %s", err)
+		}
+
+		for _, h := range hh {
+			data, err := json.Marshal(h)
+			if err != nil {
+				return fmt.Errorf("failed to json marshal heartbeat: %s", err)
+			}
+
+			if err := b.Put(key(h), data); err != nil {
+				return fmt.Errorf("failed to store heartbeat with id %q: %s", h.ID(), err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Read returns all heartbeats from the local activity history with a time
+// in range [start, end), ordered by time.
+func Read(filepath string, start, end time.Time) ([]heartbeat.Heartbeat, error) {
+	db, close, err := openDB(filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer close()
+
+	var heartbeats = make([]heartbeat.Heartbeat, 0)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(dbBucket))
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+		max := timePrefix(toSeconds(end))
+
+		for k, v := c.Seek(timePrefix(toSeconds(start))); k != nil; k, v = c.Next() {
+			if string(k[:len(max)]) >= string(max) {
+				break
+			}
+
+			var h heartbeat.Heartbeat
+
+			if err := json.Unmarshal(v, &h); err != nil {
+				log.Warnf("failed to json unmarshal heartbeat data: %s", err)
+				continue
+			}
+
+			heartbeats = append(heartbeats, h)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read heartbeats: %s", err)
+	}
+
+	return heartbeats, nil
+}
+
+// key returns a lexicographically time ordered key for a heartbeat.
+func key(h heartbeat.Heartbeat) []byte {
+	return append(timePrefix(h.Time), []byte("-"+h.ID())...)
+}
+
+// timePrefix returns a zero padded representation of the passed in unix
+// timestamp, which keeps the bolt cursor order equal to the time order.
+func timePrefix(secs float64) []byte {
+	return []byte(fmt.Sprintf("%017.6f", secs))
+}
+
+func toSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / 1000000000
+}
+
+// openDB opens a connection to the activity db.
+// It returns the pointer to bolt.DB, a function to close the connection and an error.
+func openDB(filepath string) (db *bolt.DB, _ func(), err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panicked: %v", r)
+		}
+	}()
+
+	db, err = bolt.Open(filepath, 0600, &bolt.Options{Timeout: 30 * time.Second})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open db file: %s", err)
+	}
+
+	return db, func() {
+		if err := db.Close(); err != nil {
+			log.Debugf("failed to close db file: %s", err)
+		}
+	}, err
+}
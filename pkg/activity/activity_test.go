@@ -0,0 +1,149 @@
+package activity_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/activity"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithStore(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "activity.bdb")
+
+	opt := activity.WithStore(fp)
+
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Len(t, hh, 2)
+
+		return []heartbeat.Result{
+			{
+				Status: 201,
+			},
+		}, nil
+	})
+
+	results, err := handle(testHeartbeats())
+	require.NoError(t, err)
+
+	assert.Equal(t, []heartbeat.Result{
+		{
+			Status: 201,
+		},
+	}, results)
+
+	stored, err := activity.Read(fp, time.Unix(1592868300, 0), time.Unix(1592868400, 0))
+	require.NoError(t, err)
+
+	assert.Equal(t, testHeartbeats(), stored)
+}
+
+func TestWithStore_InvalidFilepath(t *testing.T) {
+	opt := activity.WithStore(filepath.Join(t.TempDir(), "missing", "activity.bdb"))
+
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Len(t, hh, 2)
+
+		return []heartbeat.Result{}, nil
+	})
+
+	_, err := handle(testHeartbeats())
+	require.NoError(t, err)
+}
+
+func TestStore_Duplicate(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "activity.bdb")
+
+	err := activity.Store(fp, testHeartbeats())
+	require.NoError(t, err)
+
+	err = activity.Store(fp, testHeartbeats())
+	require.NoError(t, err)
+
+	stored, err := activity.Read(fp, time.Unix(1592868300, 0), time.Unix(1592868400, 0))
+	require.NoError(t, err)
+
+	assert.Len(t, stored, 2)
+}
+
+func TestRead_Range(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "activity.bdb")
+
+	hh := testHeartbeats()
+
+	// store unordered to verify records are returned by time
+	err := activity.Store(fp, []heartbeat.Heartbeat{hh[1], hh[0]})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		Start    time.Time
+		End      time.Time
+		Expected []heartbeat.Heartbeat
+	}{
+		"all": {
+			Start:    time.Unix(1592868300, 0),
+			End:      time.Unix(1592868400, 0),
+			Expected: hh,
+		},
+		"end exclusive": {
+			Start:    time.Unix(1592868300, 0),
+			End:      time.Unix(1592868386, 79084000),
+			Expected: hh[:1],
+		},
+		"start inclusive": {
+			Start:    time.Unix(1592868386, 79084000),
+			End:      time.Unix(1592868400, 0),
+			Expected: hh[1:],
+		},
+		"empty": {
+			Start:    time.Unix(1592868400, 0),
+			End:      time.Unix(1592868500, 0),
+			Expected: []heartbeat.Heartbeat{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			stored, err := activity.Read(fp, test.Start, test.End)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, stored)
+		})
+	}
+}
+
+func TestRead_NoBucket(t *testing.T) {
+	stored, err := activity.Read(filepath.Join(t.TempDir(), "activity.bdb"), time.Unix(0, 0), time.Now())
+	require.NoError(t, err)
+
+	assert.Empty(t, stored)
+}
+
+func testHeartbeats() []heartbeat.Heartbeat {
+	return []heartbeat.Heartbeat{
+		{
+			Branch:     heartbeat.PointerTo("heartbeat"),
+			Category:   heartbeat.CodingCategory,
+			Entity:     "/tmp/main.go",
+			EntityType: heartbeat.FileType,
+			Language:   heartbeat.PointerTo("Go"),
+			Project:    heartbeat.PointerTo("wakatime-cli"),
+			Time:       1592868367.219124,
+			UserAgent:  "wakatime/13.0.6 (linux-4.19.0-9-amd64-x86_64) go1.20.4 vim/8.2",
+		},
+		{
+			Branch:     heartbeat.PointerTo("summary"),
+			Category:   heartbeat.DebuggingCategory,
+			Entity:     "/tmp/main.py",
+			EntityType: heartbeat.FileType,
+			Language:   heartbeat.PointerTo("Python"),
+			Project:    heartbeat.PointerTo("wakatime"),
+			Time:       1592868386.079084,
+			UserAgent:  "wakatime/13.0.6 (linux-4.19.0-9-amd64-x86_64) go1.20.4 vscode/1.78.2",
+		},
+	}
+}
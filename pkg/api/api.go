@@ -24,7 +24,12 @@ const (
 // Client communicates with the wakatime api.
 type Client struct {
 	baseURL string
-	client  *http.Client
+	// batchSize is the maximum number of heartbeats sent in a single request.
+	// Zero means no limit.
+	batchSize int
+	client    *http.Client
+	// concurrency is the maximum number of concurrent heartbeat requests.
+	concurrency int
 	// doFunc allows api client options to manipulate request/response handling.
 	// default function will be set in constructor.
 	//
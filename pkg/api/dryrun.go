@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// DryRun is a heartbeat sender, which writes the requests an api client would
+// send for heartbeats instead of sending them. Api keys are masked.
+type DryRun struct {
+	client *Client
+	w      io.Writer
+}
+
+// NewDryRun creates a new DryRun, which writes the requests of the passed in
+// api client to w.
+func NewDryRun(c *Client, w io.Writer) *DryRun {
+	return &DryRun{
+		client: c,
+		w:      w,
+	}
+}
+
+// SendHeartbeats writes the requests, which SendHeartbeats of the api client
+// would send, grouped by api key and split into batches the same way. Every
+// heartbeat gets a result with status 201 Created.
+func (d *DryRun) SendHeartbeats(heartbeats []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	url := d.client.baseURL + "/users/current/heartbeats.bulk"
+
+	log.Debugf("dry run, not sending %d heartbeat(s) to api at %s", len(heartbeats), url)
+
+	grouped := groupByAPIKey(heartbeats)
+
+	for _, k := range sortKeys(grouped) {
+		for _, batch := range splitByHostname(heartbeats, batches(grouped[k], d.client.batchSize)) {
+			hh := make([]heartbeat.Heartbeat, len(batch))
+			for i, n := range batch {
+				hh[i] = heartbeats[n]
+			}
+
+			data, err := json.Marshal(hh)
+			if err != nil {
+				return nil, fmt.Errorf("failed to json encode body: %s", err)
+			}
+
+			var hostname string
+			if hh[0].Hostname != "" {
+				hostname = fmt.Sprintf("X-Machine-Name: %s\n", hh[0].Hostname)
+			}
+
+			_, err = fmt.Fprintf(
+				d.w,
+				"%s %s\nAuthorization: Basic (api key %s)\nContent-Type: application/json\n%s\n%s\n\n",
+				http.MethodPost,
+				url,
+				maskAPIKey(k),
+				hostname,
+				data,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to write request: %s", err)
+			}
+		}
+	}
+
+	results := make([]heartbeat.Result, len(heartbeats))
+	for n, h := range heartbeats {
+		results[n] = heartbeat.Result{
+			Status:    http.StatusCreated,
+			Heartbeat: h,
+		}
+	}
+
+	return results, nil
+}
+
+// maskAPIKey hides all but the last 4 chars of an api key.
+func maskAPIKey(apiKey string) string {
+	if len(apiKey) <= 4 {
+		return "<hidden>"
+	}
+
+	return fmt.Sprintf("<hidden>%s", apiKey[len(apiKey)-4:])
+}
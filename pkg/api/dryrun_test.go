@@ -0,0 +1,120 @@
+package api_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/api"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRun_SendHeartbeats(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	router.HandleFunc("/users/current/heartbeats.bulk", func(_ http.ResponseWriter, _ *http.Request) {
+		t.Fatal("no request expected")
+	})
+
+	hh := []heartbeat.Heartbeat{
+		{
+			APIKey:     "00000000-0000-4000-8000-000000000001",
+			Category:   heartbeat.CodingCategory,
+			Entity:     "/tmp/main.go",
+			EntityType: heartbeat.FileType,
+			Time:       1585598059,
+		},
+		{
+			APIKey:     "00000000-0000-4000-8000-000000000000",
+			Category:   heartbeat.CodingCategory,
+			Entity:     "/tmp/main.py",
+			EntityType: heartbeat.FileType,
+			Time:       1585598060,
+		},
+		{
+			APIKey:     "00000000-0000-4000-8000-000000000000",
+			Category:   heartbeat.CodingCategory,
+			Entity:     "/tmp/main.rs",
+			EntityType: heartbeat.FileType,
+			Time:       1585598061,
+		},
+	}
+
+	var b strings.Builder
+
+	c := api.NewClient(url, api.WithBatchSize(1))
+
+	results, err := api.NewDryRun(c, &b).SendHeartbeats(hh)
+	require.NoError(t, err)
+
+	assert.Equal(t, []heartbeat.Result{
+		{Status: http.StatusCreated, Heartbeat: hh[0]},
+		{Status: http.StatusCreated, Heartbeat: hh[1]},
+		{Status: http.StatusCreated, Heartbeat: hh[2]},
+	}, results)
+
+	request := func(apiKey, body string) string {
+		return "POST " + url + "/users/current/heartbeats.bulk\n" +
+			"Authorization: Basic (api key <hidden>" + apiKey + ")\n" +
+			"Content-Type: application/json\n\n" +
+			body + "\n\n"
+	}
+
+	assert.Equal(
+		t,
+		request("0000", `[{"category":"coding","entity":"/tmp/main.py","type":"file","time":1585598060,"user_agent":""}]`)+
+			request("0000", `[{"category":"coding","entity":"/tmp/main.rs","type":"file","time":1585598061,"user_agent":""}]`)+
+			request("0001", `[{"category":"coding","entity":"/tmp/main.go","type":"file","time":1585598059,"user_agent":""}]`),
+		b.String(),
+	)
+}
+
+func TestDryRun_SendHeartbeats_Hostname(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	router.HandleFunc("/users/current/heartbeats.bulk", func(_ http.ResponseWriter, _ *http.Request) {
+		t.Fatal("no request expected")
+	})
+
+	hh := []heartbeat.Heartbeat{
+		{
+			APIKey:     "00000000-0000-4000-8000-000000000000",
+			Category:   heartbeat.CodingCategory,
+			Entity:     "/tmp/main.go",
+			EntityType: heartbeat.FileType,
+			Hostname:   "0140090ff45909b5",
+			Time:       1585598059,
+		},
+		{
+			APIKey:     "00000000-0000-4000-8000-000000000000",
+			Category:   heartbeat.CodingCategory,
+			Entity:     "/tmp/main.py",
+			EntityType: heartbeat.FileType,
+			Time:       1585598060,
+		},
+	}
+
+	var b strings.Builder
+
+	_, err := api.NewDryRun(api.NewClient(url), &b).SendHeartbeats(hh)
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		"POST "+url+"/users/current/heartbeats.bulk\n"+
+			"Authorization: Basic (api key <hidden>0000)\n"+
+			"Content-Type: application/json\n"+
+			"X-Machine-Name: 0140090ff45909b5\n\n"+
+			`[{"category":"coding","entity":"/tmp/main.go","type":"file","time":1585598059,"user_agent":""}]`+"\n\n"+
+			"POST "+url+"/users/current/heartbeats.bulk\n"+
+			"Authorization: Basic (api key <hidden>0000)\n"+
+			"Content-Type: application/json\n\n"+
+			`[{"category":"coding","entity":"/tmp/main.py","type":"file","time":1585598060,"user_agent":""}]`+"\n\n",
+		b.String(),
+	)
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/wakatime/wakatime-cli/pkg/exitcode"
 	"github.com/wakatime/wakatime-cli/pkg/wakaerror"
@@ -10,6 +11,9 @@ import (
 // Err represents a general api error.
 type Err struct {
 	Err error
+	// RetryAfter is the delay before retrying, as requested by the api
+	// via Retry-After header.
+	RetryAfter time.Duration
 }
 
 var _ wakaerror.Error = Err{}
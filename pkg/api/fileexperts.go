@@ -63,7 +63,7 @@ func (c *Client) FileExperts(heartbeats []heartbeat.Heartbeat) ([]heartbeat.Resu
 	case http.StatusBadRequest:
 		return nil, ErrBadRequest{fmt.Errorf("bad request at %q", url)}
 	default:
-		return nil, Err{fmt.Errorf(
+		return nil, Err{Err: fmt.Errorf(
 			"invalid response status from %q. got: %d, want: %d. body: %q",
 			url,
 			resp.StatusCode,
@@ -7,8 +7,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/log"
@@ -18,6 +23,12 @@ import (
 // The API does not guarantuee the setting of the Heartbeat property of the result.
 // On certain errors, like 429/too many heartbeats, this is omitted and not set.
 //
+// Heartbeats are grouped by api key and every group is split into batches.
+// Groups are sent concurrently, batches of a group one after another, which
+// keeps the order per api key. Results are returned in the order of the passed
+// in heartbeats. If only some batches fail, the heartbeats of failed and skipped
+// batches get a result without status, including the error message.
+//
 // ErrRequest is returned upon request failure with no received response from api.
 // ErrAuth is returned upon receiving a 401 Unauthorized api response.
 // Err is returned on any other api response related error.
@@ -26,20 +37,80 @@ func (c *Client) SendHeartbeats(heartbeats []heartbeat.Heartbeat) ([]heartbeat.R
 
 	log.Debugf("sending %d heartbeat(s) to api at %s", len(heartbeats), url)
 
-	var results []heartbeat.Result
-
 	grouped := groupByAPIKey(heartbeats)
 	keys := sortKeys(grouped)
 
+	var (
+		errs      = make([]error, len(heartbeats))
+		results   = make([]heartbeat.Result, len(heartbeats))
+		sem       = make(chan struct{}, max(c.concurrency, 1))
+		succeeded atomic.Bool
+		wg        sync.WaitGroup
+	)
+
 	for _, k := range keys {
-		res, err := c.sendHeartbeats(url, grouped[k])
-		if err != nil {
-			return nil, err
+		// acquire before starting the goroutine to send groups in key order
+		sem <- struct{}{}
+
+		wg.Add(1)
+
+		go func(indexes []int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			for _, batch := range splitByHostname(heartbeats, batches(indexes, c.batchSize)) {
+				hh := make([]heartbeat.Heartbeat, len(batch))
+				for i, n := range batch {
+					hh[i] = heartbeats[n]
+				}
+
+				res, err := c.sendHeartbeats(url, hh)
+				if err != nil {
+					// skip remaining batches to keep the order per api key
+					for _, n := range indexes[slices.Index(indexes, batch[0]):] {
+						errs[n] = err
+					}
+
+					return
+				}
+
+				succeeded.Store(true)
+
+				for i, n := range batch {
+					if i < len(res) {
+						results[n] = res[i]
+					}
+				}
+			}
+		}(grouped[k])
+	}
+
+	wg.Wait()
+
+	var firstErr error
+
+	for _, k := range keys {
+		if err := errs[grouped[k][len(grouped[k])-1]]; err != nil && firstErr == nil {
+			firstErr = err
 		}
+	}
+
+	if firstErr == nil {
+		return results, nil
+	}
+
+	if !succeeded.Load() {
+		return nil, firstErr
+	}
 
-		results = append(results, res...)
+	for n, err := range errs {
+		if err != nil {
+			results[n] = heartbeat.Result{Errors: []string{err.Error()}}
+		}
 	}
 
+	log.Warnf("failed to send some heartbeats to api: %s", firstErr)
+
 	return results, nil
 }
 
@@ -61,6 +132,11 @@ func (c *Client) sendHeartbeats(url string, heartbeats []heartbeat.Heartbeat) ([
 	// set auth header here for every request due to multiple api key support
 	setAuthHeader(req, heartbeats[0].APIKey)
 
+	// batches only contain heartbeats with the same hostname, which may be hashed by privacy profiles
+	if heartbeats[0].Hostname != "" {
+		setHostnameHeader(req, heartbeats[0].Hostname)
+	}
+
 	resp, err := c.Do(req)
 	if err != nil {
 		return nil, Err{Err: fmt.Errorf("failed making request to %q: %s", url, err)}
@@ -79,14 +155,17 @@ func (c *Client) sendHeartbeats(url string, heartbeats []heartbeat.Heartbeat) ([
 	case http.StatusBadRequest:
 		return nil, ErrBadRequest{Err: fmt.Errorf("bad request at %q", url)}
 	default:
-		return nil, Err{Err: fmt.Errorf(
-			"invalid response status from %q. got: %d, want: %d/%d. body: %q",
-			url,
-			resp.StatusCode,
-			http.StatusCreated,
-			http.StatusAccepted,
-			string(body),
-		)}
+		return nil, Err{
+			Err: fmt.Errorf(
+				"invalid response status from %q. got: %d, want: %d/%d. body: %q",
+				url,
+				resp.StatusCode,
+				http.StatusCreated,
+				http.StatusAccepted,
+				string(body),
+			),
+			RetryAfter: parseRetryAfter(resp),
+		}
 	}
 
 	results, err := ParseHeartbeatResponses(body)
@@ -213,16 +292,81 @@ func parseHeartbeatResponseError(data json.RawMessage) ([]string, error) {
 	return errs, nil
 }
 
-func groupByAPIKey(hh []heartbeat.Heartbeat) map[string][]heartbeat.Heartbeat {
-	var grouped = make(map[string][]heartbeat.Heartbeat, 0)
+// parseRetryAfter parses the Retry-After header of 429 Too Many Requests and
+// 503 Service Unavailable responses. The header value is either a number of
+// seconds or a http date. Zero is returned, if missing or invalid.
+func parseRetryAfter(resp *http.Response) time.Duration {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0
+	}
+
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
 
-	for _, h := range hh {
-		grouped[h.APIKey] = append(grouped[h.APIKey], h)
+	if secs, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(secs, 0)) * time.Second
+	}
+
+	at, err := http.ParseTime(value)
+	if err != nil {
+		log.Debugf("failed to parse Retry-After header %q: %s", value, err)
+		return 0
+	}
+
+	return max(time.Until(at), 0)
+}
+
+// splitByHostname splits batches into runs of heartbeats with the same
+// hostname, as the hostname is sent once per request.
+func splitByHostname(hh []heartbeat.Heartbeat, batches [][]int) [][]int {
+	var split [][]int
+
+	for _, batch := range batches {
+		start := 0
+
+		for i := 1; i < len(batch); i++ {
+			if hh[batch[i]].Hostname != hh[batch[start]].Hostname {
+				split = append(split, batch[start:i])
+				start = i
+			}
+		}
+
+		split = append(split, batch[start:])
+	}
+
+	return split
+}
+
+// groupByAPIKey returns the indexes of the passed in heartbeats per api key.
+func groupByAPIKey(hh []heartbeat.Heartbeat) map[string][]int {
+	var grouped = make(map[string][]int, 0)
+
+	for n, h := range hh {
+		grouped[h.APIKey] = append(grouped[h.APIKey], n)
 	}
 
 	return grouped
 }
 
+// batches splits indexes into batches of at most size elements. A size of
+// zero or less returns a single batch.
+func batches(indexes []int, size int) [][]int {
+	if size <= 0 || len(indexes) <= size {
+		return [][]int{indexes}
+	}
+
+	var split [][]int
+
+	for len(indexes) > size {
+		split = append(split, indexes[:size])
+		indexes = indexes[size:]
+	}
+
+	return append(split, indexes)
+}
+
 func sortKeys[K string, V any](m map[K]V) []K {
 	keys := make([]K, len(m))
 	i := 0
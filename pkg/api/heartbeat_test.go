@@ -1,10 +1,14 @@
 package api_test
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -133,6 +137,186 @@ func TestClient_SendHeartbeats_MultipleApiKey(t *testing.T) {
 	assert.Eventually(t, func() bool { return numCalls == 2 }, time.Second, 50*time.Millisecond)
 }
 
+func TestClient_SendHeartbeats_Hostname(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	var (
+		hostnames []string
+		mu        sync.Mutex
+	)
+
+	router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		hostnames = append(hostnames, req.Header.Get("X-Machine-Name"))
+		mu.Unlock()
+
+		// write response
+		f, err := os.Open("testdata/api_heartbeats_response.json")
+		require.NoError(t, err)
+
+		w.WriteHeader(http.StatusCreated)
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	c := api.NewClient(url, api.WithHostname("my-computer"))
+
+	hh := testHeartbeats()
+	hh[1].Hostname = "0140090ff45909b5"
+
+	_, err := c.SendHeartbeats(hh)
+	require.NoError(t, err)
+
+	// heartbeats with a different hostname are sent in separate requests
+	assert.Equal(t, []string{"my-computer", "0140090ff45909b5"}, hostnames)
+}
+
+func TestClient_SendHeartbeats_Concurrency(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	var (
+		inFlight    atomic.Int32
+		maxInFlight atomic.Int32
+		mu          sync.Mutex
+		sent        = make(map[string][]float64)
+	)
+
+	router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, req *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for {
+			current := maxInFlight.Load()
+			if n <= current || maxInFlight.CompareAndSwap(current, n) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+
+		hh := echoHeartbeats(t, w, req)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		auth := req.Header.Get("Authorization")
+
+		for _, h := range hh {
+			sent[auth] = append(sent[auth], h.Time)
+		}
+	})
+
+	var hh []heartbeat.Heartbeat
+
+	// interleave heartbeats of 4 api keys
+	for i := 0; i < 12; i++ {
+		h := testHeartbeats()[0]
+		h.APIKey = fmt.Sprintf("00000000-0000-4000-8000-00000000000%d", i%4)
+		h.Time = 1585598059 + float64(i)
+
+		hh = append(hh, h)
+	}
+
+	c := api.NewClient(url, api.WithBatchSize(2), api.WithConcurrency(2))
+
+	results, err := c.SendHeartbeats(hh)
+	require.NoError(t, err)
+
+	require.Len(t, results, len(hh))
+
+	for i, result := range results {
+		assert.Equal(t, http.StatusCreated, result.Status)
+		assert.Equal(t, hh[i].Time, result.Heartbeat.Time)
+	}
+
+	require.Len(t, sent, 4)
+
+	for _, times := range sent {
+		assert.Len(t, times, 3)
+		assert.IsIncreasing(t, times)
+	}
+
+	assert.Equal(t, int32(2), maxInFlight.Load())
+}
+
+func TestClient_SendHeartbeats_PartialFailure(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	var numCalls atomic.Int32
+
+	router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, req *http.Request) {
+		numCalls.Add(1)
+
+		var hh []heartbeat.Heartbeat
+
+		err := json.NewDecoder(req.Body).Decode(&hh)
+		require.NoError(t, err)
+
+		// fail the second batch of the first api key
+		if hh[0].Time == 1585598061 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		writeHeartbeatsResponse(t, w, hh)
+	})
+
+	var hh []heartbeat.Heartbeat
+
+	for i := 0; i < 6; i++ {
+		h := testHeartbeats()[0]
+		h.APIKey = fmt.Sprintf("00000000-0000-4000-8000-00000000000%d", i/3)
+		h.Time = 1585598060 + float64(i)
+
+		hh = append(hh, h)
+	}
+
+	c := api.NewClient(url, api.WithBatchSize(1), api.WithConcurrency(2))
+
+	results, err := c.SendHeartbeats(hh)
+	require.NoError(t, err)
+
+	require.Len(t, results, len(hh))
+
+	// first api key: first batch succeeded, remaining batches failed or skipped
+	assert.Equal(t, http.StatusCreated, results[0].Status)
+	assert.Zero(t, results[1].Status)
+	assert.Len(t, results[1].Errors, 1)
+	assert.Zero(t, results[2].Status)
+	assert.Len(t, results[2].Errors, 1)
+
+	// second api key: all batches succeeded
+	for _, result := range results[3:] {
+		assert.Equal(t, http.StatusCreated, result.Status)
+	}
+
+	assert.Equal(t, int32(5), numCalls.Load())
+}
+
+func TestClient_SendHeartbeats_AllFailed(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	hh := testHeartbeats()
+	hh[1].APIKey = "00000000-0000-4000-8000-000000000001"
+
+	c := api.NewClient(url, api.WithConcurrency(2))
+
+	results, err := c.SendHeartbeats(hh)
+
+	var errapi api.Err
+
+	assert.ErrorAs(t, err, &errapi)
+	assert.Nil(t, results)
+}
+
 func TestClient_SendHeartbeats_Err(t *testing.T) {
 	url, router, close := setupTestServer()
 	defer close()
@@ -155,6 +339,54 @@ func TestClient_SendHeartbeats_Err(t *testing.T) {
 	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
 }
 
+func TestClient_SendHeartbeats_ErrRetryAfter(t *testing.T) {
+	tests := map[string]struct {
+		Status     int
+		RetryAfter string
+		Expected   time.Duration
+	}{
+		"too many requests": {
+			Status:     http.StatusTooManyRequests,
+			RetryAfter: "120",
+			Expected:   2 * time.Minute,
+		},
+		"service unavailable": {
+			Status:     http.StatusServiceUnavailable,
+			RetryAfter: time.Now().Add(time.Hour).UTC().Format(http.TimeFormat),
+			Expected:   time.Hour,
+		},
+		"invalid": {
+			Status:     http.StatusTooManyRequests,
+			RetryAfter: "soon",
+		},
+		"other status": {
+			Status:     http.StatusInternalServerError,
+			RetryAfter: "120",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			url, router, close := setupTestServer()
+			defer close()
+
+			router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Retry-After", test.RetryAfter)
+				w.WriteHeader(test.Status)
+			})
+
+			c := api.NewClient(url)
+			_, err := c.SendHeartbeats(testHeartbeats())
+
+			var errapi api.Err
+
+			require.ErrorAs(t, err, &errapi)
+
+			assert.InDelta(t, test.Expected, errapi.RetryAfter, float64(2*time.Second))
+		})
+	}
+}
+
 func TestClient_SendHeartbeats_ErrAuth(t *testing.T) {
 	url, router, close := setupTestServer()
 	defer close()
@@ -298,6 +530,31 @@ func TestParseHeartbeatResponses_Errors(t *testing.T) {
 	}, results[1])
 }
 
+// echoHeartbeats responds to a bulk request with a created result for every
+// sent heartbeat and returns the sent heartbeats.
+func echoHeartbeats(t *testing.T, w http.ResponseWriter, req *http.Request) []heartbeat.Heartbeat {
+	var hh []heartbeat.Heartbeat
+
+	err := json.NewDecoder(req.Body).Decode(&hh)
+	require.NoError(t, err)
+
+	writeHeartbeatsResponse(t, w, hh)
+
+	return hh
+}
+
+func writeHeartbeatsResponse(t *testing.T, w http.ResponseWriter, hh []heartbeat.Heartbeat) {
+	responses := make([][]any, len(hh))
+	for i, h := range hh {
+		responses[i] = []any{map[string]heartbeat.Heartbeat{"data": h}, http.StatusCreated}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+
+	err := json.NewEncoder(w).Encode(map[string]any{"responses": responses})
+	require.NoError(t, err)
+}
+
 func testHeartbeats() []heartbeat.Heartbeat {
 	return []heartbeat.Heartbeat{
 		{
@@ -34,6 +34,33 @@ func WithAuth(auth BasicAuth) (Option, error) {
 	}, nil
 }
 
+// WithBatchSize limits the number of heartbeats sent in a single request.
+func WithBatchSize(size int) Option {
+	return func(c *Client) {
+		c.batchSize = size
+	}
+}
+
+// WithConcurrency sets the maximum number of concurrent heartbeat requests.
+// Heartbeats of different api keys are sent concurrently. The connection
+// limits of the transport are raised accordingly.
+func WithConcurrency(concurrency int) Option {
+	return func(c *Client) {
+		c.concurrency = concurrency
+
+		if concurrency <= 1 {
+			return
+		}
+
+		transport := LazyCreateNewTransport(c)
+		transport.MaxConnsPerHost = concurrency
+		transport.MaxIdleConns = concurrency
+		transport.MaxIdleConnsPerHost = concurrency
+
+		c.client.Transport = transport
+	}
+}
+
 // WithDisableSSLVerify disables verification of insecure certificates.
 func WithDisableSSLVerify() Option {
 	return func(c *Client) {
@@ -150,19 +177,26 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
-// WithHostname sets the X-Machine-Name header to the passed in hostname.
+// WithHostname sets the X-Machine-Name header to the passed in hostname, unless
+// the request already sets a hostname.
 func WithHostname(hostname string) Option {
 	return func(c *Client) {
 		next := c.doFunc
 		c.doFunc = func(c *Client, req *http.Request) (*http.Response, error) {
-			hostname = url.QueryEscape(hostname)
-			req.Header.Set("X-Machine-Name", hostname)
+			if req.Header.Get("X-Machine-Name") == "" {
+				setHostnameHeader(req, hostname)
+			}
 
 			return next(c, req)
 		}
 	}
 }
 
+// setHostnameHeader sets the X-Machine-Name header to the passed in hostname.
+func setHostnameHeader(req *http.Request, hostname string) {
+	req.Header.Set("X-Machine-Name", url.QueryEscape(hostname))
+}
+
 // WithTimezone sets the TimeZone header to the passed in timezone.
 func WithTimezone(timezone string) Option {
 	return func(c *Client) {
@@ -19,7 +19,7 @@ func (c *Client) Today() (*summary.Summary, error) {
 
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
-		return nil, Err{fmt.Errorf("failed to create request: %s", err)}
+		return nil, Err{Err: fmt.Errorf("failed to create request: %s", err)}
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -29,14 +29,14 @@ func (c *Client) Today() (*summary.Summary, error) {
 
 	resp, err := c.Do(req)
 	if err != nil {
-		return nil, Err{fmt.Errorf("failed to make request to %q: %s", url, err)}
+		return nil, Err{Err: fmt.Errorf("failed to make request to %q: %s", url, err)}
 	}
 
 	defer resp.Body.Close() // nolint:errcheck,gosec
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, Err{fmt.Errorf("failed to read response body from %q: %s", url, err)}
+		return nil, Err{Err: fmt.Errorf("failed to read response body from %q: %s", url, err)}
 	}
 
 	switch resp.StatusCode {
@@ -46,7 +46,7 @@ func (c *Client) Today() (*summary.Summary, error) {
 	case http.StatusBadRequest:
 		return nil, ErrBadRequest{fmt.Errorf("bad request at %q", url)}
 	default:
-		return nil, Err{fmt.Errorf(
+		return nil, Err{Err: fmt.Errorf(
 			"invalid response status from %q. got: %d, want: %d. body: %q",
 			url,
 			resp.StatusCode,
@@ -3,6 +3,7 @@ package apikey
 import (
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/project"
 	"github.com/wakatime/wakatime-cli/pkg/regex"
 )
 
@@ -24,7 +25,8 @@ type MapPattern struct {
 
 // WithReplacing initializes and returns a heartbeat handle option, which
 // can be used in a heartbeat processing pipeline to replace default api key
-// for a heartbeat following the provided configurations.
+// for a heartbeat following the provided configurations. An api key set in a
+// .wakatime-project file is used, if no pattern matches.
 func WithReplacing(config Config) heartbeat.HandleOption {
 	return func(next heartbeat.Handle) heartbeat.Handle {
 		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
@@ -38,6 +40,12 @@ func WithReplacing(config Config) heartbeat.HandleOption {
 					continue
 				}
 
+				if result, ok := projectFileAPIKey(h); ok {
+					hh[n].APIKey = result
+
+					continue
+				}
+
 				hh[n].APIKey = config.DefaultAPIKey
 			}
 
@@ -59,3 +67,25 @@ func MatchPattern(fp string, patterns []MapPattern) (string, bool) {
 
 	return "", false
 }
+
+// projectFileAPIKey returns the api key set in the .wakatime-project file of
+// a local file entity.
+func projectFileAPIKey(h heartbeat.Heartbeat) (string, bool) {
+	if h.EntityType != heartbeat.FileType || h.IsRemote() {
+		return "", false
+	}
+
+	config, ok, err := project.ReadFileConfig(h.Entity)
+	if err != nil {
+		log.Warnf("failed to read .wakatime-project file: %s", err)
+		return "", false
+	}
+
+	if !ok || config.APIKey == "" {
+		return "", false
+	}
+
+	log.Debugf("using api key of .wakatime-project file at %q", config.Folder)
+
+	return config.APIKey, true
+}
@@ -65,6 +65,49 @@ func TestWithReplacing(t *testing.T) {
 	}, result)
 }
 
+func TestWithReplacing_ProjectFile(t *testing.T) {
+	tmpDir, err := realpath.Realpath(t.TempDir())
+	require.NoError(t, err)
+
+	err = os.WriteFile(
+		filepath.Join(tmpDir, ".wakatime-project"),
+		[]byte("project = wakatime-cli\napi_key = 00000000-0000-4000-8000-000000000002\n"),
+		0600,
+	)
+	require.NoError(t, err)
+
+	entity := filepath.Join(tmpDir, "main.go")
+
+	config := apikey.Config{
+		DefaultAPIKey: "00000000-0000-4000-8000-000000000000",
+	}
+
+	opt := apikey.WithReplacing(config)
+	h := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Equal(t, []heartbeat.Heartbeat{
+			{
+				APIKey:     "00000000-0000-4000-8000-000000000002",
+				Entity:     entity,
+				EntityType: heartbeat.FileType,
+			},
+		}, hh)
+
+		return []heartbeat.Result{
+			{
+				Status: 201,
+			},
+		}, nil
+	})
+
+	_, err = h([]heartbeat.Heartbeat{
+		{
+			Entity:     entity,
+			EntityType: heartbeat.FileType,
+		},
+	})
+	require.NoError(t, err)
+}
+
 func TestApiKey_MatchPattern(t *testing.T) {
 	wd, err := os.Getwd()
 	require.NoError(t, err)
@@ -1,9 +1,12 @@
 package backoff
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math"
+	"math/rand/v2"
 	"strconv"
 	"time"
 
@@ -28,10 +31,32 @@ type Config struct {
 	At time.Time
 	// Retries is the number of attempts to connect.
 	Retries int
+	// Until is the time until which to back off. If zero, it is calculated
+	// from At and Retries.
+	Until time.Time
 	// V is an instance of Viper.
 	V *viper.Viper
 	// HasProxy is true when using a proxy
 	HasProxy bool
+	// Name optionally identifies a separate backoff state, e.g. of an additional
+	// heartbeat sink. Empty for the WakaTime API.
+	Name string
+	// Endpoint optionally describes the endpoint of the backoff state, as
+	// shown by the backoff status command.
+	Endpoint string
+}
+
+// EndpointConfig defines backoff data of the WakaTime API. Backoff state is
+// kept per endpoint, which is the combination of api url and api key.
+type EndpointConfig struct {
+	// HasProxy is true when using a proxy
+	HasProxy bool
+	// States contains the backoff state per api key.
+	States map[string]State
+	// URL is the api url.
+	URL string
+	// V is an instance of Viper.
+	V *viper.Viper
 }
 
 // WithBackoff initializes and returns a heartbeat handle option, which
@@ -42,49 +67,225 @@ func WithBackoff(config Config) heartbeat.HandleOption {
 		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 			log.Debugln("execute heartbeat backoff algorithm")
 
-			if shouldBackoff(config.Retries, config.At) {
-				if config.HasProxy {
-					return nil, api.ErrBackoff{Err: errors.New("won't send heartbeat due to backoff with proxy")}
-				}
-
-				return nil, api.ErrBackoff{Err: errors.New("won't send heartbeat due to backoff without proxy")}
+			if shouldBackoff(config.Retries, config.At, config.Until) {
+				return nil, errBackoff(config.HasProxy)
 			}
 
 			results, err := next(hh)
 			if err != nil {
-				// error response, increment backoff
-				if updateErr := updateBackoffSettings(config.V, config.Retries+1, time.Now()); updateErr != nil {
-					log.Warnf("failed to update backoff settings: %s", updateErr)
-				}
+				config.fail(err)
 
 				return nil, err
 			}
 
-			// success response, reset backoff
-			if config.Retries > 0 || !config.At.IsZero() {
-				if resetErr := updateBackoffSettings(config.V, 0, time.Time{}); resetErr != nil {
-					log.Warnf("failed to reset backoff settings: %s", resetErr)
+			config.reset()
+
+			return results, nil
+		}
+	}
+}
+
+// WithEndpointBackoff initializes and returns a heartbeat handle option, which
+// can be used in a heartbeat processing pipeline to prevent trying to send
+// heartbeats to an unresponsive endpoint. Heartbeats are sent separately per
+// api key, so an endpoint in backoff does not block heartbeats of other api keys.
+// Heartbeats of failed or skipped endpoints get a result without status,
+// unless no heartbeats were sent successfully at all.
+func WithEndpointBackoff(config EndpointConfig) heartbeat.HandleOption {
+	return func(next heartbeat.Handle) heartbeat.Handle {
+		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			log.Debugln("execute heartbeat endpoint backoff algorithm")
+
+			var (
+				firstErr  error
+				results   = make([]heartbeat.Result, len(hh))
+				succeeded bool
+			)
+
+			keys, grouped := groupByAPIKey(hh)
+
+			for _, key := range keys {
+				indexes := grouped[key]
+				endpoint := config.endpoint(key)
+
+				err := sendEndpoint(next, endpoint, hh, indexes, results)
+				if err != nil {
+					for _, n := range indexes {
+						results[n] = heartbeat.Result{Errors: []string{err.Error()}}
+					}
+
+					if firstErr == nil {
+						firstErr = err
+					}
+
+					continue
 				}
+
+				succeeded = true
+			}
+
+			if firstErr == nil {
+				return results, nil
 			}
 
+			if !succeeded {
+				return nil, firstErr
+			}
+
+			log.Warnf("failed to send heartbeats to some endpoints: %s", firstErr)
+
 			return results, nil
 		}
 	}
 }
 
+// sendEndpoint sends the heartbeats at the passed in indexes to a single
+// endpoint, following its backoff state, and stores their results.
+func sendEndpoint(
+	next heartbeat.Handle,
+	config Config,
+	hh []heartbeat.Heartbeat,
+	indexes []int,
+	results []heartbeat.Result,
+) error {
+	if shouldBackoff(config.Retries, config.At, config.Until) {
+		log.Debugf("skip sending to %s due to backoff", config.Endpoint)
+
+		return errBackoff(config.HasProxy)
+	}
+
+	group := make([]heartbeat.Heartbeat, len(indexes))
+	for i, n := range indexes {
+		group[i] = hh[n]
+	}
+
+	res, err := next(group)
+	if err != nil {
+		config.fail(err)
+
+		return err
+	}
+
+	config.reset()
+
+	for i, n := range indexes {
+		if i < len(res) {
+			results[n] = res[i]
+		}
+	}
+
+	return nil
+}
+
+// EndpointName returns the name of the backoff state of the endpoint with
+// the passed in api url and api key. The api key is not part of the name.
+func EndpointName(url, apiKey string) string {
+	sum := sha256.Sum256([]byte(url + "\n" + apiKey))
+
+	return "api_" + hex.EncodeToString(sum[:])[:12]
+}
+
+// endpoint returns the backoff config of the endpoint with the passed in api key.
+func (c EndpointConfig) endpoint(apiKey string) Config {
+	state := c.States[apiKey]
+	name := EndpointName(c.URL, apiKey)
+
+	if len(apiKey) > 4 {
+		// only show last 4 chars of api key
+		apiKey = fmt.Sprintf("<hidden>%s", apiKey[len(apiKey)-4:])
+	}
+
+	return Config{
+		At:       state.At,
+		Retries:  state.Retries,
+		Until:    state.Until,
+		V:        c.V,
+		HasProxy: c.HasProxy,
+		Name:     name,
+		Endpoint: fmt.Sprintf("%s (api key %s)", c.URL, apiKey),
+	}
+}
+
+// fail increments the backoff state after a failed attempt to send.
+func (c Config) fail(err error) {
+	now := time.Now()
+	retries := c.Retries + 1
+
+	state := State{
+		At:      now,
+		Retries: retries,
+		Until:   nextRetry(retries, now, retryAfter(err)),
+	}
+
+	if updateErr := updateBackoffSettings(c.V, c.Name, c.Endpoint, state); updateErr != nil {
+		log.Warnf("failed to update backoff settings: %s", updateErr)
+	}
+}
+
+// reset resets the backoff state after a successful attempt to send.
+func (c Config) reset() {
+	if (State{At: c.At, Retries: c.Retries, Until: c.Until}).IsZero() {
+		return
+	}
+
+	if resetErr := updateBackoffSettings(c.V, c.Name, c.Endpoint, State{}); resetErr != nil {
+		log.Warnf("failed to reset backoff settings: %s", resetErr)
+	}
+}
+
+func errBackoff(hasProxy bool) error {
+	if hasProxy {
+		return api.ErrBackoff{Err: errors.New("won't send heartbeat due to backoff with proxy")}
+	}
+
+	return api.ErrBackoff{Err: errors.New("won't send heartbeat due to backoff without proxy")}
+}
+
+// groupByAPIKey returns the distinct api keys in order of appearance and the
+// indexes of the passed in heartbeats per api key.
+func groupByAPIKey(hh []heartbeat.Heartbeat) ([]string, map[string][]int) {
+	var (
+		grouped = make(map[string][]int)
+		keys    []string
+	)
+
+	for n, h := range hh {
+		if _, ok := grouped[h.APIKey]; !ok {
+			keys = append(keys, h.APIKey)
+		}
+
+		grouped[h.APIKey] = append(grouped[h.APIKey], n)
+	}
+
+	return keys, grouped
+}
+
 // shouldBackoff returns true if we should save heartbeats directly to offline
 // database and skip sending to API due to rate limiting from too many recent
 // networking errors.
-func shouldBackoff(retries int, at time.Time) bool {
+func shouldBackoff(retries int, at time.Time, until time.Time) bool {
 	if retries < 1 || at.IsZero() {
 		return false
 	}
 
-	backoffSeconds := float64(factor) * math.Pow(2, float64(retries))
+	if !until.IsZero() {
+		if until.Before(time.Now()) {
+			return false
+		}
+
+		log.Debugf(
+			"backoff tried %d times since %s, will retry again after %s",
+			retries,
+			at.Format(ini.DateFormat),
+			until.Format(ini.DateFormat),
+		)
+
+		return true
+	}
 
-	duration := time.Duration(backoffSeconds) * time.Second
+	duration := exponentialDelay(retries)
 
-	if backoffSeconds > maxBackoffSecs {
+	if duration > maxBackoffSecs*time.Second {
 		log.Debugf(
 			"exponential backoff tried %d times since %s, will reset because reached %s max backoff",
 			retries,
@@ -109,21 +310,72 @@ func shouldBackoff(retries int, at time.Time) bool {
 	return true
 }
 
-func updateBackoffSettings(v *viper.Viper, retries int, at time.Time) error {
+// nextRetry returns the time until which to back off after the passed in
+// number of failed attempts. The second half of the exponential delay is
+// randomized to spread retries. A longer delay requested by the api via
+// Retry-After header takes precedence. Zero is returned once the exponential
+// delay exceeds the max backoff and no delay was requested.
+func nextRetry(retries int, now time.Time, retryAfter time.Duration) time.Time {
+	maxDelay := maxBackoffSecs * time.Second
+
+	delay := exponentialDelay(retries)
+	if delay > maxDelay {
+		delay = 0
+	} else {
+		delay = delay/2 + rand.N(delay/2+1)
+	}
+
+	if retryAfter > delay {
+		delay = min(retryAfter, maxDelay)
+	}
+
+	if delay == 0 {
+		return time.Time{}
+	}
+
+	return now.Add(delay)
+}
+
+// exponentialDelay returns the backoff delay after the passed in number of
+// failed attempts, without jitter.
+func exponentialDelay(retries int) time.Duration {
+	return time.Duration(float64(factor)*math.Pow(2, float64(retries))) * time.Second
+}
+
+// retryAfter returns the delay requested by the api, if any.
+func retryAfter(err error) time.Duration {
+	var errapi api.Err
+	if errors.As(err, &errapi) {
+		return errapi.RetryAfter
+	}
+
+	return 0
+}
+
+func updateBackoffSettings(v *viper.Viper, name, endpoint string, state State) error {
 	w, err := ini.NewWriter(v, ini.InternalFilePath)
 	if err != nil {
 		return fmt.Errorf("failed to parse config file: %s", err)
 	}
 
+	keys := stateKeys(name)
+
 	keyValue := map[string]string{
-		"backoff_retries": strconv.Itoa(retries),
-		"backoff_at":      "",
+		keys.retries: strconv.Itoa(state.Retries),
+		keys.at:      "",
+		keys.until:   "",
 	}
 
-	if !at.IsZero() {
-		keyValue["backoff_at"] = at.Format(ini.DateFormat)
-	} else {
-		keyValue["backoff_at"] = ""
+	if !state.At.IsZero() {
+		keyValue[keys.at] = state.At.Format(ini.DateFormat)
+	}
+
+	if !state.Until.IsZero() {
+		keyValue[keys.until] = state.Until.Format(ini.DateFormat)
+	}
+
+	if endpoint != "" {
+		keyValue[keys.endpoint] = endpoint
 	}
 
 	if err := w.Write("internal", keyValue); err != nil {
@@ -15,7 +15,7 @@ import (
 func TestShouldBackoff(t *testing.T) {
 	at := time.Now().Add(time.Second * -1)
 
-	should := shouldBackoff(1, at)
+	should := shouldBackoff(1, at, time.Time{})
 
 	assert.True(t, should)
 }
@@ -23,7 +23,7 @@ func TestShouldBackoff(t *testing.T) {
 func TestShouldBackoff_AfterResetTime(t *testing.T) {
 	at := time.Now().Add(time.Second * -1)
 
-	should := shouldBackoff(8, at)
+	should := shouldBackoff(8, at, time.Time{})
 
 	assert.False(t, should)
 }
@@ -31,17 +31,48 @@ func TestShouldBackoff_AfterResetTime(t *testing.T) {
 func TestShouldBackoff_AfterResetTime_ZeroRetries(t *testing.T) {
 	at := time.Now().Add(maxBackoffSecs + 1*time.Second)
 
-	should := shouldBackoff(0, at)
+	should := shouldBackoff(0, at, time.Time{})
 
 	assert.False(t, should)
 }
 
+func TestShouldBackoff_Until(t *testing.T) {
+	at := time.Now().Add(-time.Minute)
+
+	assert.True(t, shouldBackoff(8, at, time.Now().Add(time.Minute)))
+	assert.False(t, shouldBackoff(1, at, time.Now().Add(-time.Second)))
+}
+
 func TestShouldBackoff_NegateBackoff(t *testing.T) {
-	should := shouldBackoff(0, time.Time{})
+	should := shouldBackoff(0, time.Time{}, time.Time{})
 
 	assert.False(t, should)
 }
 
+func TestNextRetry(t *testing.T) {
+	now := time.Now()
+
+	for i := 0; i < 100; i++ {
+		until := nextRetry(2, now, 0)
+
+		// 60 seconds with the second half randomized
+		assert.GreaterOrEqual(t, until.Sub(now), 30*time.Second)
+		assert.LessOrEqual(t, until.Sub(now), 60*time.Second)
+	}
+}
+
+func TestNextRetry_RetryAfter(t *testing.T) {
+	now := time.Now()
+
+	assert.Equal(t, now.Add(10*time.Minute), nextRetry(1, now, 10*time.Minute))
+	assert.Equal(t, now.Add(time.Hour), nextRetry(1, now, 2*time.Hour))
+	assert.Equal(t, now.Add(10*time.Minute), nextRetry(8, now, 10*time.Minute))
+}
+
+func TestNextRetry_MaxBackoff(t *testing.T) {
+	assert.Zero(t, nextRetry(8, time.Now(), 0))
+}
+
 func TestUpdateBackoffSettings(t *testing.T) {
 	v := viper.New()
 
@@ -55,7 +86,7 @@ func TestUpdateBackoffSettings(t *testing.T) {
 
 	at := time.Now().Add(time.Second * -1)
 
-	err = updateBackoffSettings(v, 2, at)
+	err = updateBackoffSettings(v, "", "", State{At: at, Retries: 2})
 	require.NoError(t, err)
 
 	writer, err := ini.NewWriter(v, func(vp *viper.Viper) (string, error) {
@@ -81,7 +112,7 @@ func TestUpdateBackoffSettings_NotInBackoff(t *testing.T) {
 	v.Set("config", tmpFile.Name())
 	v.Set("internal-config", tmpFile.Name())
 
-	err = updateBackoffSettings(v, 0, time.Time{})
+	err = updateBackoffSettings(v, "", "", State{})
 	require.NoError(t, err)
 
 	writer, err := ini.NewWriter(v, func(vp *viper.Viper) (string, error) {
@@ -93,3 +124,33 @@ func TestUpdateBackoffSettings_NotInBackoff(t *testing.T) {
 	assert.Empty(t, writer.File.Section("internal").Key("backoff_at").String())
 	assert.Equal(t, "0", writer.File.Section("internal").Key("backoff_retries").String())
 }
+
+func TestUpdateBackoffSettings_Name(t *testing.T) {
+	v := viper.New()
+
+	tmpFile, err := os.CreateTemp(t.TempDir(), "wakatime")
+	require.NoError(t, err)
+
+	defer tmpFile.Close()
+
+	v.Set("config", tmpFile.Name())
+	v.Set("internal-config", tmpFile.Name())
+
+	at := time.Now().Add(time.Second * -1)
+
+	err = updateBackoffSettings(v, "sink_local", "sink local", State{At: at, Retries: 3})
+	require.NoError(t, err)
+
+	writer, err := ini.NewWriter(v, func(vp *viper.Viper) (string, error) {
+		assert.Equal(t, v, vp)
+		return tmpFile.Name(), nil
+	})
+	require.NoError(t, err)
+
+	backoffAt := writer.File.Section("internal").Key("sink_local_backoff_at").MustTimeFormat(ini.DateFormat)
+
+	assert.WithinDuration(t, time.Now(), backoffAt, 15*time.Second)
+	assert.Equal(t, "3", writer.File.Section("internal").Key("sink_local_backoff_retries").String())
+	assert.Equal(t, "sink local", writer.File.Section("internal").Key("sink_local_backoff_endpoint").String())
+	assert.False(t, writer.File.Section("internal").HasKey("backoff_retries"))
+}
@@ -372,3 +372,182 @@ func TestWithBackoff_ShouldRetry(t *testing.T) {
 	assert.Empty(t, v.GetString("internal.backoff_at"))
 	assert.Equal(t, "0", v.GetString("internal.backoff_retries"))
 }
+
+func TestWithEndpointBackoff(t *testing.T) {
+	v := viper.New()
+
+	tmpFile, err := os.CreateTemp(t.TempDir(), "wakatime")
+	require.NoError(t, err)
+
+	defer tmpFile.Close()
+
+	v.Set("internal-config", tmpFile.Name())
+
+	var (
+		url       = "https://api.wakatime.com/api/v1"
+		backedOff = "00000000-0000-4000-8000-000000000001"
+		failing   = "00000000-0000-4000-8000-000000000002"
+	)
+
+	opt := backoff.WithEndpointBackoff(backoff.EndpointConfig{
+		States: map[string]backoff.State{
+			backedOff: {
+				At:      time.Now().Add(-time.Second),
+				Retries: 1,
+				Until:   time.Now().Add(time.Minute),
+			},
+		},
+		URL: url,
+		V:   v,
+	})
+
+	var sent []string
+
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		sent = append(sent, hh[0].APIKey)
+
+		if hh[0].APIKey == failing {
+			return nil, api.Err{Err: errors.New("unavailable"), RetryAfter: 10 * time.Minute}
+		}
+
+		results := make([]heartbeat.Result, len(hh))
+		for i, h := range hh {
+			results[i] = heartbeat.Result{Status: 201, Heartbeat: h}
+		}
+
+		return results, nil
+	})
+
+	results, err := handle([]heartbeat.Heartbeat{
+		{APIKey: "00000000-0000-4000-8000-000000000000", Entity: "a"},
+		{APIKey: backedOff, Entity: "b"},
+		{APIKey: failing, Entity: "c"},
+		{APIKey: "00000000-0000-4000-8000-000000000000", Entity: "d"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"00000000-0000-4000-8000-000000000000", failing}, sent)
+
+	require.Len(t, results, 4)
+	assert.Equal(t, 201, results[0].Status)
+	assert.Equal(t, "a", results[0].Heartbeat.Entity)
+	assert.Equal(t, []string{"won't send heartbeat due to backoff without proxy"}, results[1].Errors)
+	assert.Equal(t, []string{"unavailable"}, results[2].Errors)
+	assert.Equal(t, 201, results[3].Status)
+	assert.Equal(t, "d", results[3].Heartbeat.Entity)
+
+	err = ini.ReadInConfig(v, tmpFile.Name())
+	require.NoError(t, err)
+
+	// only the failing endpoint's state was written
+	states := backoff.LoadEndpointStates(v)
+	require.Len(t, states, 1)
+
+	assert.Equal(t, backoff.EndpointName(url, failing), states[0].Name)
+	assert.Equal(t, url+" (api key <hidden>0002)", states[0].Endpoint)
+	assert.Equal(t, 1, states[0].Retries)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), states[0].Until, 15*time.Second)
+	assert.True(t, states[0].InBackoff())
+}
+
+func TestWithEndpointBackoff_AllInBackoff(t *testing.T) {
+	apiKey := "00000000-0000-4000-8000-000000000000"
+
+	opt := backoff.WithEndpointBackoff(backoff.EndpointConfig{
+		HasProxy: true,
+		States: map[string]backoff.State{
+			apiKey: {
+				At:      time.Now().Add(-time.Second),
+				Retries: 1,
+			},
+		},
+		URL: "https://api.wakatime.com/api/v1",
+	})
+
+	handle := opt(func(_ []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		return nil, errors.New("should not be called")
+	})
+
+	_, err := handle([]heartbeat.Heartbeat{{APIKey: apiKey}})
+
+	var errbackoff api.ErrBackoff
+
+	assert.ErrorAs(t, err, &errbackoff)
+	assert.Equal(t, "won't send heartbeat due to backoff with proxy", err.Error())
+}
+
+func TestWithEndpointBackoff_Reset(t *testing.T) {
+	v := viper.New()
+
+	tmpFile, err := os.CreateTemp(t.TempDir(), "wakatime")
+	require.NoError(t, err)
+
+	defer tmpFile.Close()
+
+	v.Set("internal-config", tmpFile.Name())
+
+	var (
+		apiKey = "00000000-0000-4000-8000-000000000000"
+		url    = "https://api.wakatime.com/api/v1"
+	)
+
+	opt := backoff.WithEndpointBackoff(backoff.EndpointConfig{
+		States: map[string]backoff.State{
+			apiKey: {
+				At:      time.Now().Add(-time.Minute),
+				Retries: 2,
+				Until:   time.Now().Add(-time.Second),
+			},
+		},
+		URL: url,
+		V:   v,
+	})
+
+	handle := opt(func(_ []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		return []heartbeat.Result{{Status: 201}}, nil
+	})
+
+	_, err = handle([]heartbeat.Heartbeat{{APIKey: apiKey}})
+	require.NoError(t, err)
+
+	err = ini.ReadInConfig(v, tmpFile.Name())
+	require.NoError(t, err)
+
+	assert.Equal(t, backoff.State{}, backoff.LoadState(v, backoff.EndpointName(url, apiKey)))
+}
+
+func TestLoadEndpointStates(t *testing.T) {
+	v := viper.New()
+	v.Set("internal.backoff_retries", "1")
+	v.Set("internal.sink_local_backoff_at", "2021-08-30T18:50:42-03:00")
+	v.Set("internal.sink_local_backoff_retries", "3")
+	v.Set("internal.api_0123456789ab_backoff_at", "2021-08-30T18:50:42-03:00")
+	v.Set("internal.api_0123456789ab_backoff_endpoint", "https://api.wakatime.com/api/v1 (api key <hidden>0000)")
+	v.Set("internal.api_0123456789ab_backoff_retries", "2")
+	v.Set("internal.api_0123456789ab_backoff_until", "2021-08-30T18:51:42-03:00")
+
+	at, err := time.Parse(ini.DateFormat, "2021-08-30T18:50:42-03:00")
+	require.NoError(t, err)
+
+	states := backoff.LoadEndpointStates(v)
+
+	assert.Equal(t, []backoff.EndpointState{
+		{
+			State: backoff.State{
+				At:      at,
+				Retries: 2,
+				Until:   at.Add(time.Minute),
+			},
+			Endpoint: "https://api.wakatime.com/api/v1 (api key <hidden>0000)",
+			Name:     "api_0123456789ab",
+		},
+		{
+			State: backoff.State{
+				At:      at,
+				Retries: 3,
+			},
+			Endpoint: "sink_local",
+			Name:     "sink_local",
+		},
+	}, states)
+}
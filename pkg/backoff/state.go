@@ -0,0 +1,177 @@
+package backoff
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/ini"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
+
+	"github.com/spf13/viper"
+)
+
+// State is the backoff state of a single endpoint.
+type State struct {
+	// At is the time when the last failure happened.
+	At time.Time
+	// Retries is the number of failed attempts to send.
+	Retries int
+	// Until is the time until which to back off. If zero, it is calculated
+	// from At and Retries.
+	Until time.Time
+}
+
+// IsZero returns true if no failure is recorded.
+func (s State) IsZero() bool {
+	return s.Retries == 0 && s.At.IsZero() && s.Until.IsZero()
+}
+
+// InBackoff returns true if sending to the endpoint is currently skipped.
+func (s State) InBackoff() bool {
+	return shouldBackoff(s.Retries, s.At, s.Until)
+}
+
+// RetryAt returns the time after which sending to the endpoint is attempted
+// again. Zero if not in backoff.
+func (s State) RetryAt() time.Time {
+	if !s.InBackoff() {
+		return time.Time{}
+	}
+
+	if !s.Until.IsZero() {
+		return s.Until
+	}
+
+	return s.At.Add(exponentialDelay(s.Retries))
+}
+
+// String implements fmt.Stringer interface.
+func (s State) String() string {
+	return fmt.Sprintf(
+		"at: '%s', retries: %d, until: '%s'",
+		formatTime(s.At),
+		s.Retries,
+		formatTime(s.Until),
+	)
+}
+
+// EndpointState is the backoff state of an endpoint, as stored in the
+// internal config.
+type EndpointState struct {
+	State
+	// Endpoint describes the endpoint. Falls back to Name, if not stored.
+	Endpoint string
+	// Name is the name of the backoff state.
+	Name string
+}
+
+// keys contains the internal config keys of a backoff state.
+type keys struct {
+	at       string
+	endpoint string
+	retries  string
+	until    string
+}
+
+// stateKeys returns the internal config keys of the backoff state with the
+// passed in name.
+func stateKeys(name string) keys {
+	prefix := ""
+	if name != "" {
+		prefix = name + "_"
+	}
+
+	return keys{
+		at:       prefix + "backoff_at",
+		endpoint: prefix + "backoff_endpoint",
+		retries:  prefix + "backoff_retries",
+		until:    prefix + "backoff_until",
+	}
+}
+
+// LoadState loads the backoff state with the passed in name from the internal config.
+func LoadState(v *viper.Viper, name string) State {
+	keys := stateKeys(name)
+
+	return State{
+		At:      parseTime(v, keys.at),
+		Retries: parseRetries(v, keys.retries),
+		Until:   parseTime(v, keys.until),
+	}
+}
+
+// LoadEndpointStates loads all named backoff states from the internal config,
+// sorted by name. This includes the states of the WakaTime API endpoints and
+// of additional heartbeat sinks.
+func LoadEndpointStates(v *viper.Viper) []EndpointState {
+	var states []EndpointState
+
+	for _, key := range v.AllKeys() {
+		name, ok := strings.CutPrefix(key, "internal.")
+		if !ok {
+			continue
+		}
+
+		name, ok = strings.CutSuffix(name, "_backoff_retries")
+		if !ok || name == "" {
+			continue
+		}
+
+		endpoint := vipertools.GetString(v, "internal."+stateKeys(name).endpoint)
+		if endpoint == "" {
+			endpoint = name
+		}
+
+		states = append(states, EndpointState{
+			State:    LoadState(v, name),
+			Endpoint: endpoint,
+			Name:     name,
+		})
+	}
+
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+
+	return states
+}
+
+func parseTime(v *viper.Viper, key string) time.Time {
+	str := vipertools.GetString(v, "internal."+key)
+	if str == "" {
+		return time.Time{}
+	}
+
+	parsed, err := time.Parse(ini.DateFormat, str)
+	if err != nil {
+		log.Warnf("failed to parse %s: %s", key, err)
+		return time.Time{}
+	}
+
+	return parsed
+}
+
+func parseRetries(v *viper.Viper, key string) int {
+	str := vipertools.GetString(v, "internal."+key)
+	if str == "" {
+		return 0
+	}
+
+	parsed, err := strconv.Atoi(str)
+	if err != nil {
+		log.Warnf("failed to parse %s: %s", key, err)
+		return 0
+	}
+
+	return parsed
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(ini.DateFormat)
+}
@@ -0,0 +1,290 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/ini"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// dbFilename is the default bolt db filename.
+	dbFilename = "detection.bdb"
+	// DefaultMaxAge is the default maximum age of a cache entry. It bounds
+	// staleness for changes, which are not covered by the recorded files.
+	DefaultMaxAge = 30 * time.Minute
+	// maxEntries is the number of entries per bucket, above which expired
+	// entries are removed.
+	maxEntries = 10000
+	// openTimeout is the time to wait for the db file lock. The cache is
+	// skipped, if another process holds the lock for longer.
+	openTimeout = time.Second
+)
+
+// Cache is an on-disk cache of detection results across invocations. Every
+// entry records the modification time and size of files and directories it
+// depends on and is invalid once any of them changed. A nil Cache is valid
+// and never caches anything.
+type Cache struct {
+	filepath string
+	maxAge   time.Duration
+}
+
+// Option is a functional option for Cache.
+type Option func(*Cache)
+
+// WithMaxAge sets the maximum age of cache entries.
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(c *Cache) {
+		c.maxAge = maxAge
+	}
+}
+
+// New creates a new Cache stored in the passed in db file.
+func New(filepath string, opts ...Option) *Cache {
+	c := &Cache{
+		filepath: filepath,
+		maxAge:   DefaultMaxAge,
+	}
+
+	for _, option := range opts {
+		option(c)
+	}
+
+	return c
+}
+
+// NewDefault creates a new Cache stored in the default db file. Returns nil,
+// if the default db file cannot be determined.
+func NewDefault(opts ...Option) *Cache {
+	fp, err := Filepath()
+	if err != nil {
+		log.Warnf("failed to get detection cache filepath: %s", err)
+		return nil
+	}
+
+	return New(fp, opts...)
+}
+
+// Filepath returns the path for the detection cache db file.
+func Filepath() (string, error) {
+	folder, err := ini.WakaResourcesDir()
+	if err != nil {
+		return "", fmt.Errorf("failed getting resource directory: %s", err)
+	}
+
+	return filepath.Join(folder, dbFilename), nil
+}
+
+// entry is a cache entry as stored in the db.
+type entry struct {
+	Created time.Time       `json:"created"`
+	Files   []fileState     `json:"files"`
+	Value   json.RawMessage `json:"value"`
+}
+
+// fileState contains the state of a file or directory at the time of caching.
+type fileState struct {
+	Path    string    `json:"path"`
+	Exists  bool      `json:"exists"`
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+}
+
+// Get reads the value cached for key in bucket into value. Returns false,
+// if there is no valid entry.
+func (c *Cache) Get(bucket, key string, value any) bool {
+	if c == nil {
+		return false
+	}
+
+	if _, err := os.Stat(c.filepath); err != nil {
+		return false
+	}
+
+	db, close, err := openDB(c.filepath, true)
+	if err != nil {
+		log.Debugf("failed to open detection cache: %s", err)
+		return false
+	}
+
+	defer close()
+
+	var data []byte
+
+	err = db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+
+		// copy, as data is only valid during the transaction
+		data = append(data, b.Get([]byte(key))...)
+
+		return nil
+	})
+	if err != nil || len(data) == 0 {
+		return false
+	}
+
+	var e entry
+
+	if err := json.Unmarshal(data, &e); err != nil {
+		log.Debugf("failed to json unmarshal detection cache entry: %s", err)
+		return false
+	}
+
+	if !c.valid(e) {
+		return false
+	}
+
+	if err := json.Unmarshal(e.Value, value); err != nil {
+		log.Debugf("failed to json unmarshal detection cache value: %s", err)
+		return false
+	}
+
+	log.Debugf("detection cache hit for %q in %s", key, bucket)
+
+	return true
+}
+
+// Set caches value for key in bucket. The entry is invalidated, once one of
+// the passed in files or directories is created, removed or modified.
+func (c *Cache) Set(bucket, key string, value any, files ...string) {
+	if c == nil {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Debugf("failed to json marshal detection cache value: %s", err)
+		return
+	}
+
+	e := entry{
+		Created: time.Now(),
+		Value:   data,
+	}
+
+	for _, fp := range files {
+		e.Files = append(e.Files, statFile(fp))
+	}
+
+	data, err = json.Marshal(e)
+	if err != nil {
+		log.Debugf("failed to json marshal detection cache entry: %s", err)
+		return
+	}
+
+	db, close, err := openDB(c.filepath, false)
+	if err != nil {
+		log.Debugf("failed to open detection cache: %s", err)
+		return
+	}
+
+	defer close()
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return fmt.Errorf("failed to create/load bucket: %s", err)
+		}
+
+		if b.Stats().KeyN >= maxEntries {
+			if err := c.prune(b); err != nil {
+				return err
+			}
+		}
+
+		return b.Put([]byte(key), data)
+	})
+	if err != nil {
+		log.Debugf("failed to store detection cache entry: %s", err)
+	}
+}
+
+// prune removes expired entries from a bucket.
+func (c *Cache) prune(b *bolt.Bucket) error {
+	var keys [][]byte
+
+	err := b.ForEach(func(k, v []byte) error {
+		var e entry
+
+		if err := json.Unmarshal(v, &e); err != nil || time.Since(e.Created) > c.maxAge {
+			keys = append(keys, append([]byte{}, k...))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to iterate bucket: %s", err)
+	}
+
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return fmt.Errorf("failed to delete expired entry: %s", err)
+		}
+	}
+
+	log.Debugf("removed %d expired detection cache entries", len(keys))
+
+	return nil
+}
+
+// valid returns true if the entry is not expired and none of its files changed.
+func (c *Cache) valid(e entry) bool {
+	if time.Since(e.Created) > c.maxAge {
+		return false
+	}
+
+	for _, state := range e.Files {
+		current := statFile(state.Path)
+		if current.Exists != state.Exists || !current.ModTime.Equal(state.ModTime) || current.Size != state.Size {
+			return false
+		}
+	}
+
+	return true
+}
+
+func statFile(fp string) fileState {
+	info, err := os.Stat(fp)
+	if err != nil {
+		return fileState{Path: fp}
+	}
+
+	return fileState{
+		Path:    fp,
+		Exists:  true,
+		ModTime: info.ModTime(),
+		Size:    info.Size(),
+	}
+}
+
+// openDB opens a connection to the detection cache db. A read only connection
+// shares the file lock with other readers.
+// It returns the pointer to bolt.DB, a function to close the connection and an error.
+func openDB(filepath string, readOnly bool) (db *bolt.DB, _ func(), err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panicked: %v", r)
+		}
+	}()
+
+	db, err = bolt.Open(filepath, 0600, &bolt.Options{ReadOnly: readOnly, Timeout: openTimeout})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open db file: %s", err)
+	}
+
+	return db, func() {
+		if err := db.Close(); err != nil {
+			log.Debugf("failed to close db file: %s", err)
+		}
+	}, err
+}
@@ -0,0 +1,102 @@
+package cache_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	fp := filepath.Join(tmpDir, "main.go")
+
+	err := os.WriteFile(fp, []byte("package main"), 0600)
+	require.NoError(t, err)
+
+	c := cache.New(filepath.Join(tmpDir, "detection.bdb"))
+
+	var value string
+
+	assert.False(t, c.Get("language", fp, &value))
+
+	c.Set("language", fp, "Go", fp)
+
+	require.True(t, c.Get("language", fp, &value))
+	assert.Equal(t, "Go", value)
+
+	assert.False(t, c.Get("project", fp, &value))
+}
+
+func TestCache_FileChanged(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	fp := filepath.Join(tmpDir, "main.go")
+
+	err := os.WriteFile(fp, []byte("package main"), 0600)
+	require.NoError(t, err)
+
+	c := cache.New(filepath.Join(tmpDir, "detection.bdb"))
+
+	c.Set("language", fp, "Go", fp)
+
+	err = os.Chtimes(fp, time.Now(), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+
+	var value string
+
+	assert.False(t, c.Get("language", fp, &value))
+}
+
+func TestCache_FileCreated(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	fp := filepath.Join(tmpDir, ".wakatime-project")
+
+	c := cache.New(filepath.Join(tmpDir, "detection.bdb"))
+
+	c.Set("project", "key", "wakatime-cli", fp)
+
+	err := os.WriteFile(fp, []byte("billing"), 0600)
+	require.NoError(t, err)
+
+	var value string
+
+	assert.False(t, c.Get("project", "key", &value))
+}
+
+func TestCache_Expired(t *testing.T) {
+	c := cache.New(filepath.Join(t.TempDir(), "detection.bdb"), cache.WithMaxAge(-time.Second))
+
+	c.Set("language", "key", "Go")
+
+	var value string
+
+	assert.False(t, c.Get("language", "key", &value))
+}
+
+func TestCache_Nil(t *testing.T) {
+	var c *cache.Cache
+
+	c.Set("language", "key", "Go")
+
+	var value string
+
+	assert.False(t, c.Get("language", "key", &value))
+}
+
+func TestCache_InvalidFilepath(t *testing.T) {
+	c := cache.New(filepath.Join(t.TempDir(), "missing", "detection.bdb"))
+
+	c.Set("language", "key", "Go")
+
+	var value string
+
+	assert.False(t, c.Get("language", "key", &value))
+}
@@ -0,0 +1,140 @@
+package category
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/regex"
+)
+
+// Config contains category inference configurations.
+type Config struct {
+	// Conventions enables inferring categories from built-in test, docs,
+	// build file and code review conventions.
+	Conventions bool
+	// MapPatterns are matched against the entity before any convention.
+	MapPatterns []MapPattern
+}
+
+// MapPattern contains [category_map] data.
+type MapPattern struct {
+	// Category is the category assigned to matching heartbeats.
+	Category heartbeat.Category
+	// Regex is the regular expression matched against the entity.
+	Regex regex.Regex
+}
+
+// WithInference initializes and returns a heartbeat handle option, which
+// can be used in a heartbeat processing pipeline to infer the category of
+// heartbeats. Only heartbeats with the default coding category are changed.
+func WithInference(config Config) heartbeat.HandleOption {
+	return func(next heartbeat.Handle) heartbeat.Handle {
+		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			log.Debugln("execute category inference")
+
+			for n, h := range hh {
+				if h.Category != heartbeat.CodingCategory {
+					continue
+				}
+
+				category, reason, ok := Infer(h, config)
+				if !ok {
+					continue
+				}
+
+				hh[n].Category = category
+
+				h.Explanation.Add("category", "category %q inferred by %s", category, reason)
+			}
+
+			return next(hh)
+		}
+	}
+}
+
+// Infer returns the category of a heartbeat following the passed in
+// configurations and the reason for it. Map patterns take precedence over
+// conventions.
+func Infer(h heartbeat.Heartbeat, config Config) (heartbeat.Category, string, bool) {
+	for _, pattern := range config.MapPatterns {
+		if pattern.Regex.MatchString(h.Entity) {
+			return pattern.Category, fmt.Sprintf("category_map pattern %q", pattern.Regex.String()), true
+		}
+	}
+
+	if !config.Conventions {
+		return 0, "", false
+	}
+
+	switch h.EntityType {
+	case heartbeat.FileType:
+		return inferFileCategory(h)
+	case heartbeat.DomainType:
+		if match(h.Entity, reviewPatterns) {
+			return heartbeat.CodeReviewingCategory, "code review domain", true
+		}
+	}
+
+	return 0, "", false
+}
+
+// inferFileCategory infers the category of a file heartbeat from test,
+// build file and docs naming conventions.
+func inferFileCategory(h heartbeat.Heartbeat) (heartbeat.Category, string, bool) {
+	fp := projectRelativePath(h)
+
+	var language string
+	if h.Language != nil {
+		language = *h.Language
+	}
+
+	if match(fp, testPatterns[language]) {
+		return heartbeat.WritingTestsCategory, language + " test file naming convention", true
+	}
+
+	if match(fp, genericTestPatterns) {
+		return heartbeat.WritingTestsCategory, "test folder naming convention", true
+	}
+
+	if match(fp, buildPatterns) {
+		return heartbeat.BuildingCategory, "build file naming convention", true
+	}
+
+	if match(fp, docsPatterns) {
+		return heartbeat.WritingDocsCategory, "docs naming convention", true
+	}
+
+	return 0, "", false
+}
+
+// projectRelativePath returns the entity relative to the project folder, so
+// that folders above the project, like a home folder at /home/tests, do not
+// match conventions. The entity is returned as is, if not inside the project
+// folder.
+func projectRelativePath(h heartbeat.Heartbeat) string {
+	fp := strings.ReplaceAll(h.Entity, `\`, "/")
+
+	if h.ProjectPath == "" {
+		return fp
+	}
+
+	folder := strings.TrimSuffix(strings.ReplaceAll(h.ProjectPath, `\`, "/"), "/") + "/"
+
+	if relative, ok := strings.CutPrefix(fp, folder); ok {
+		return relative
+	}
+
+	return fp
+}
+
+func match(s string, patterns []regex.Regex) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(s) {
+			return true
+		}
+	}
+
+	return false
+}
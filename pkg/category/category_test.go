@@ -0,0 +1,262 @@
+package category_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/category"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/regex"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithInference(t *testing.T) {
+	opt := category.WithInference(category.Config{Conventions: true})
+
+	h := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Equal(t, []heartbeat.Heartbeat{
+			{
+				Category:   heartbeat.WritingTestsCategory,
+				Entity:     "/path/to/pkg/main_test.go",
+				EntityType: heartbeat.FileType,
+				Language:   heartbeat.PointerTo(heartbeat.LanguageGo.String()),
+			},
+			{
+				Category:   heartbeat.DebuggingCategory,
+				Entity:     "/path/to/pkg/main_test.go",
+				EntityType: heartbeat.FileType,
+				Language:   heartbeat.PointerTo(heartbeat.LanguageGo.String()),
+			},
+			{
+				Category:   heartbeat.CodingCategory,
+				Entity:     "/path/to/pkg/main.go",
+				EntityType: heartbeat.FileType,
+				Language:   heartbeat.PointerTo(heartbeat.LanguageGo.String()),
+			},
+		}, hh)
+
+		return []heartbeat.Result{
+			{
+				Status: 201,
+			},
+		}, nil
+	})
+
+	result, err := h([]heartbeat.Heartbeat{
+		{
+			Category:   heartbeat.CodingCategory,
+			Entity:     "/path/to/pkg/main_test.go",
+			EntityType: heartbeat.FileType,
+			Language:   heartbeat.PointerTo(heartbeat.LanguageGo.String()),
+		},
+		{
+			Category:   heartbeat.DebuggingCategory,
+			Entity:     "/path/to/pkg/main_test.go",
+			EntityType: heartbeat.FileType,
+			Language:   heartbeat.PointerTo(heartbeat.LanguageGo.String()),
+		},
+		{
+			Category:   heartbeat.CodingCategory,
+			Entity:     "/path/to/pkg/main.go",
+			EntityType: heartbeat.FileType,
+			Language:   heartbeat.PointerTo(heartbeat.LanguageGo.String()),
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []heartbeat.Result{
+		{
+			Status: 201,
+		},
+	}, result)
+}
+
+func TestInfer(t *testing.T) {
+	tests := map[string]struct {
+		Entity      string
+		EntityType  heartbeat.EntityType
+		Language    *string
+		ProjectPath string
+		Expected    heartbeat.Category
+	}{
+		"go test file": {
+			Entity:     "/path/to/pkg/main_test.go",
+			EntityType: heartbeat.FileType,
+			Language:   heartbeat.PointerTo(heartbeat.LanguageGo.String()),
+			Expected:   heartbeat.WritingTestsCategory,
+		},
+		"python test file": {
+			Entity:     "/path/to/project/test_main.py",
+			EntityType: heartbeat.FileType,
+			Language:   heartbeat.PointerTo(heartbeat.LanguagePython.String()),
+			Expected:   heartbeat.WritingTestsCategory,
+		},
+		"typescript spec file": {
+			Entity:     "/path/to/src/app.spec.ts",
+			EntityType: heartbeat.FileType,
+			Language:   heartbeat.PointerTo(heartbeat.LanguageTypeScript.String()),
+			Expected:   heartbeat.WritingTestsCategory,
+		},
+		"test folder": {
+			Entity:     "/path/to/project/tests/fixtures.json",
+			EntityType: heartbeat.FileType,
+			Expected:   heartbeat.WritingTestsCategory,
+		},
+		"test folder in project": {
+			Entity:      "/path/to/project/tests/fixtures.json",
+			EntityType:  heartbeat.FileType,
+			ProjectPath: "/path/to/project",
+			Expected:    heartbeat.WritingTestsCategory,
+		},
+		"windows test folder": {
+			Entity:     `C:\path\to\project\tests\fixtures.json`,
+			EntityType: heartbeat.FileType,
+			Expected:   heartbeat.WritingTestsCategory,
+		},
+		"windows test folder in project": {
+			Entity:      `C:\path\to\project\tests\fixtures.json`,
+			EntityType:  heartbeat.FileType,
+			ProjectPath: `C:\path\to\project`,
+			Expected:    heartbeat.WritingTestsCategory,
+		},
+		"makefile": {
+			Entity:     "/path/to/project/Makefile",
+			EntityType: heartbeat.FileType,
+			Expected:   heartbeat.BuildingCategory,
+		},
+		"github workflow": {
+			Entity:     "/path/to/project/.github/workflows/on_push.yml",
+			EntityType: heartbeat.FileType,
+			Expected:   heartbeat.BuildingCategory,
+		},
+		"readme": {
+			Entity:     "/path/to/project/README.md",
+			EntityType: heartbeat.FileType,
+			Expected:   heartbeat.WritingDocsCategory,
+		},
+		"docs folder": {
+			Entity:     "/path/to/project/docs/index.html",
+			EntityType: heartbeat.FileType,
+			Expected:   heartbeat.WritingDocsCategory,
+		},
+		"github pull request": {
+			Entity:     "https://github.com/wakatime/wakatime-cli/pull/1",
+			EntityType: heartbeat.DomainType,
+			Expected:   heartbeat.CodeReviewingCategory,
+		},
+		"gitlab merge request": {
+			Entity:     "https://gitlab.com/wakatime/wakatime-cli/-/merge_requests/1",
+			EntityType: heartbeat.DomainType,
+			Expected:   heartbeat.CodeReviewingCategory,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			inferred, reason, ok := category.Infer(heartbeat.Heartbeat{
+				Entity:      test.Entity,
+				EntityType:  test.EntityType,
+				Language:    test.Language,
+				ProjectPath: test.ProjectPath,
+			}, category.Config{Conventions: true})
+			require.True(t, ok)
+
+			assert.Equal(t, test.Expected, inferred)
+			assert.NotEmpty(t, reason)
+		})
+	}
+}
+
+func TestInfer_NoMatch(t *testing.T) {
+	tests := map[string]struct {
+		Entity      string
+		EntityType  heartbeat.EntityType
+		ProjectPath string
+	}{
+		"source file": {
+			Entity:     "/path/to/project/main.go",
+			EntityType: heartbeat.FileType,
+		},
+		"test folder above project": {
+			Entity:      "/home/tests/project/main.go",
+			EntityType:  heartbeat.FileType,
+			ProjectPath: "/home/tests/project",
+		},
+		"docs folder above project": {
+			Entity:      "/home/docs/project/src/main.go",
+			EntityType:  heartbeat.FileType,
+			ProjectPath: "/home/docs/project/",
+		},
+		"github repository": {
+			Entity:     "https://github.com/wakatime/wakatime-cli",
+			EntityType: heartbeat.DomainType,
+		},
+		"app": {
+			Entity:     "Slack",
+			EntityType: heartbeat.AppType,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, ok := category.Infer(heartbeat.Heartbeat{
+				Entity:      test.Entity,
+				EntityType:  test.EntityType,
+				ProjectPath: test.ProjectPath,
+			}, category.Config{Conventions: true})
+
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestInfer_ConventionsDisabled(t *testing.T) {
+	_, _, ok := category.Infer(heartbeat.Heartbeat{
+		Entity:     "/path/to/pkg/main_test.go",
+		EntityType: heartbeat.FileType,
+		Language:   heartbeat.PointerTo(heartbeat.LanguageGo.String()),
+	}, category.Config{})
+
+	assert.False(t, ok)
+}
+
+func TestInfer_MapPattern(t *testing.T) {
+	config := category.Config{
+		Conventions: true,
+		MapPatterns: []category.MapPattern{
+			{
+				Category: heartbeat.PlanningCategory,
+				Regex:    regex.MustCompile("(?i)/docs/adr/"),
+			},
+		},
+	}
+
+	inferred, reason, ok := category.Infer(heartbeat.Heartbeat{
+		Entity:     "/path/to/project/docs/adr/0001-record.md",
+		EntityType: heartbeat.FileType,
+	}, config)
+	require.True(t, ok)
+
+	assert.Equal(t, heartbeat.PlanningCategory, inferred)
+	assert.Equal(t, `category_map pattern "(?i)/docs/adr/"`, reason)
+}
+
+func TestInfer_MapPattern_ConventionsDisabled(t *testing.T) {
+	config := category.Config{
+		MapPatterns: []category.MapPattern{
+			{
+				Category: heartbeat.ResearchingCategory,
+				Regex:    regex.MustCompile("(?i)^https://pkg.go.dev/"),
+			},
+		},
+	}
+
+	inferred, _, ok := category.Infer(heartbeat.Heartbeat{
+		Entity:     "https://pkg.go.dev/net/http",
+		EntityType: heartbeat.DomainType,
+	}, config)
+	require.True(t, ok)
+
+	assert.Equal(t, heartbeat.ResearchingCategory, inferred)
+}
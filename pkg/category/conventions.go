@@ -0,0 +1,117 @@
+package category
+
+import (
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/regex"
+)
+
+// testPatterns contains test file naming conventions per language. Patterns
+// are matched against the file path with forward slashes.
+// nolint:gochecknoglobals
+var testPatterns = map[string][]regex.Regex{
+	heartbeat.LanguageC.String(): {
+		regex.MustCompile(`_(unit)?test\.c$`),
+	},
+	heartbeat.LanguageCPP.String(): {
+		regex.MustCompile(`_(unit)?test\.(cc|cpp|cxx)$`),
+	},
+	heartbeat.LanguageCSharp.String(): {
+		regex.MustCompile(`Tests?\.cs$`),
+		regex.MustCompile(`\.Tests?/`),
+	},
+	heartbeat.LanguageDart.String(): {
+		regex.MustCompile(`_test\.dart$`),
+	},
+	heartbeat.LanguageElixir.String(): {
+		regex.MustCompile(`_test\.exs$`),
+	},
+	heartbeat.LanguageGo.String(): {
+		regex.MustCompile(`_test\.go$`),
+	},
+	heartbeat.LanguageGroovy.String(): {
+		regex.MustCompile(`(Test|Spec)\.groovy$`),
+		regex.MustCompile(`/src/test/`),
+	},
+	heartbeat.LanguageJava.String(): {
+		regex.MustCompile(`(Test|Tests|IT)\.java$`),
+		regex.MustCompile(`/src/test/`),
+	},
+	heartbeat.LanguageJavaScript.String(): {
+		regex.MustCompile(`\.(test|spec)\.[cm]?js$`),
+	},
+	heartbeat.LanguageJSX.String(): {
+		regex.MustCompile(`\.(test|spec)\.jsx$`),
+	},
+	heartbeat.LanguageKotlin.String(): {
+		regex.MustCompile(`Tests?\.kt$`),
+		regex.MustCompile(`/src/test/`),
+	},
+	heartbeat.LanguagePHP.String(): {
+		regex.MustCompile(`Test\.php$`),
+	},
+	heartbeat.LanguagePython.String(): {
+		regex.MustCompile(`(^|/)test_[^/]+\.py$`),
+		regex.MustCompile(`_test\.py$`),
+		regex.MustCompile(`(^|/)conftest\.py$`),
+	},
+	heartbeat.LanguageRuby.String(): {
+		regex.MustCompile(`_(spec|test)\.rb$`),
+	},
+	heartbeat.LanguageRust.String(): {
+		regex.MustCompile(`(^|/)tests/[^/]+\.rs$`),
+	},
+	heartbeat.LanguageScala.String(): {
+		regex.MustCompile(`(Spec|Suite|Test)\.scala$`),
+		regex.MustCompile(`/src/test/`),
+	},
+	heartbeat.LanguageSwift.String(): {
+		regex.MustCompile(`Tests?\.swift$`),
+	},
+	heartbeat.LanguageTSX.String(): {
+		regex.MustCompile(`\.(test|spec)\.tsx$`),
+	},
+	heartbeat.LanguageTypeScript.String(): {
+		regex.MustCompile(`\.(test|spec)\.[cm]?ts$`),
+	},
+}
+
+// genericTestPatterns contains test folder naming conventions shared by
+// many languages.
+// nolint:gochecknoglobals
+var genericTestPatterns = []regex.Regex{
+	regex.MustCompile(`(^|/)(__tests__|spec|tests?)/`),
+}
+
+// buildPatterns contains build file and continuous integration config naming
+// conventions.
+// nolint:gochecknoglobals
+var buildPatterns = []regex.Regex{
+	regex.MustCompile(`(?i)(^|/)(gnu)?makefile$`),
+	regex.MustCompile(`(?i)(^|/)(dockerfile|containerfile)(\.[^/]+)?$`),
+	regex.MustCompile(`(^|/)(CMakeLists\.txt|Jenkinsfile|Rakefile|Justfile|Earthfile|Vagrantfile)$`),
+	regex.MustCompile(`(^|/)(BUILD|BUILD\.bazel|WORKSPACE|WORKSPACE\.bazel|MODULE\.bazel|meson\.build)$`),
+	regex.MustCompile(`(^|/)(build|settings)\.gradle(\.kts)?$`),
+	regex.MustCompile(`(^|/)(pom\.xml|build\.xml|build\.sbt|Taskfile\.ya?ml|\.gitlab-ci\.yml)$`),
+	regex.MustCompile(`\.(mk|cmake|bzl)$`),
+	regex.MustCompile(`(^|/)\.(github/workflows|circleci|buildkite)/`),
+}
+
+// docsPatterns contains documentation naming conventions.
+// nolint:gochecknoglobals
+var docsPatterns = []regex.Regex{
+	regex.MustCompile(`(?i)(^|/)(docs?|documentation)/`),
+	regex.MustCompile(`(?i)\.(md|mdx|markdown|rst|adoc|asciidoc)$`),
+	regex.MustCompile(`(?i)(^|/)(readme|changelog|contributing)(\.[^/]+)?$`),
+}
+
+// reviewPatterns contains code review tool domains and urls of pull or
+// merge requests.
+// nolint:gochecknoglobals
+var reviewPatterns = []regex.Regex{
+	regex.MustCompile(`(?i)^(https?://)?(www\.)?github\.com/[^/]+/[^/]+/pull/`),
+	regex.MustCompile(`(?i)^(https?://)?[^/]*gitlab\.[^/]+/.+/-/merge_requests`),
+	regex.MustCompile(`(?i)^(https?://)?(www\.)?bitbucket\.org/[^/]+/[^/]+/pull-requests`),
+	regex.MustCompile(`(?i)^(https?://)?dev\.azure\.com/.+/pullrequest/`),
+	regex.MustCompile(`(?i)^(https?://)?(www\.)?reviewable\.io(/|$)`),
+	regex.MustCompile(`(?i)^(https?://)?(gerrit|phabricator|reviews?|reviewboard|codereview)\.[^/]+`),
+}
@@ -0,0 +1,99 @@
+package deps
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// cargoTables are the tables of a Cargo.toml file declaring dependencies.
+// nolint:gochecknoglobals
+var cargoTables = []string{"dependencies", "dev-dependencies", "build-dependencies"}
+
+// ParserCargo is a dependency parser for Cargo.toml manifest files.
+// It is not thread safe.
+type ParserCargo struct {
+	Output []string
+}
+
+// Parse parses the dependencies of all dependency tables from a Cargo.toml file,
+// including target specific ones. Renamed dependencies resolve to the crate name.
+func (p *ParserCargo) Parse(filepath string) ([]string, error) {
+	reader, err := file.OpenNoLock(filepath) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	p.init()
+	defer p.init()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from reader: %s", err)
+	}
+
+	var manifest map[string]any
+	if err := toml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse toml: %s", err)
+	}
+
+	p.processTables(manifest)
+
+	if targets, ok := manifest["target"].(map[string]any); ok {
+		for _, name := range sortedKeys(targets) {
+			if target, ok := targets[name].(map[string]any); ok {
+				p.processTables(target)
+			}
+		}
+	}
+
+	return p.Output, nil
+}
+
+func (p *ParserCargo) init() {
+	p.Output = nil
+}
+
+func (p *ParserCargo) processTables(parent map[string]any) {
+	for _, table := range cargoTables {
+		deps, ok := parent[table].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		for _, name := range sortedKeys(deps) {
+			// renamed dependency, e.g. foo = { package = "bar" }
+			if spec, ok := deps[name].(map[string]any); ok {
+				if pkg, ok := spec["package"].(string); ok && pkg != "" {
+					name = pkg
+				}
+			}
+
+			p.Output = append(p.Output, name)
+		}
+	}
+}
+
+// sortedKeys returns the keys of a toml table in alphabetical order, because
+// the order of the file is not retained.
+func sortedKeys[T any](table map[string]T) []string {
+	keys := make([]string, 0, len(table))
+	for k := range table {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,26 @@
+package deps_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/deps"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserCargo_Parse(t *testing.T) {
+	parser := deps.ParserCargo{}
+
+	dependencies, err := parser.Parse("testdata/manifests/Cargo.toml")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"serde_json",
+		"serde",
+		"tokio",
+		"criterion",
+		"cc",
+		"winapi",
+	}, dependencies)
+}
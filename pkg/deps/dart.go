@@ -0,0 +1,129 @@
+package deps
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// StateDart is a token parsing state.
+type StateDart int
+
+const (
+	// StateDartUnknown represents an unknown token parsing state.
+	StateDartUnknown StateDart = iota
+	// StateDartImport means we are in import section during token parsing.
+	StateDartImport
+)
+
+// ParserDart is a dependency parser for the Dart programming language.
+// It is not thread safe.
+type ParserDart struct {
+	State  StateDart
+	Output []string
+}
+
+// Parse parses dependencies from Dart file content using the chroma Dart lexer.
+func (p *ParserDart) Parse(filepath string) ([]string, error) {
+	reader, err := file.OpenNoLock(filepath) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	p.init()
+	defer p.init()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from reader: %s", err)
+	}
+
+	l := lexers.Get(heartbeat.LanguageDart.String())
+	if l == nil {
+		return nil, fmt.Errorf("failed to get lexer for %s", heartbeat.LanguageDart.String())
+	}
+
+	iter, err := l.Tokenise(nil, string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to tokenize file content: %s", err)
+	}
+
+	for _, token := range iter.Tokens() {
+		p.processToken(token)
+	}
+
+	return p.Output, nil
+}
+
+func (p *ParserDart) append(dep string) {
+	// only package imports are dependencies, skip dart sdk and relative imports
+	dep, ok := strings.CutPrefix(dep, "package:")
+	if !ok {
+		return
+	}
+
+	// select package name
+	dep = strings.Split(dep, "/")[0]
+
+	// trim whitespaces
+	dep = strings.TrimSpace(dep)
+
+	p.Output = append(p.Output, dep)
+}
+
+func (p *ParserDart) init() {
+	p.State = StateDartUnknown
+	p.Output = []string{}
+}
+
+func (p *ParserDart) processToken(token chroma.Token) {
+	switch token.Type {
+	case chroma.Keyword:
+		p.processKeyword(token.Value)
+	case chroma.LiteralStringSingle, chroma.LiteralStringDouble:
+		p.processLiteralString(token.Value)
+	case chroma.Text, chroma.TextWhitespace:
+		// skip whitespaces
+	default:
+		p.State = StateDartUnknown
+	}
+}
+
+func (p *ParserDart) processKeyword(value string) {
+	switch value {
+	case "import":
+		p.State = StateDartImport
+	default:
+		p.State = StateDartUnknown
+	}
+}
+
+func (p *ParserDart) processLiteralString(value string) {
+	if p.State != StateDartImport {
+		return
+	}
+
+	value = strings.Trim(value, `'"`)
+
+	// skip opening quote token
+	if value == "" {
+		return
+	}
+
+	p.append(value)
+
+	p.State = StateDartUnknown
+}
@@ -0,0 +1,23 @@
+package deps_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/deps"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserDart_Parse(t *testing.T) {
+	parser := deps.ParserDart{}
+
+	dependencies, err := parser.Parse("testdata/dart.dart")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"flutter",
+		"http",
+		"provider",
+	}, dependencies)
+}
@@ -2,6 +2,7 @@ package deps
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/log"
@@ -21,6 +22,9 @@ type Config struct {
 	// FilePatterns will be matched against a file entities name and if matching, will skip
 	// dependency scanning.
 	FilePatterns []regex.Regex
+	// Manifests enables reading dependencies from the manifest files of the project,
+	// like go.mod or package.json, in addition to the imports of the entity file.
+	Manifests bool
 }
 
 // DependencyParser is a dependency parser for a programming language.
@@ -31,12 +35,15 @@ type DependencyParser interface {
 // WithDetection initializes and returns a heartbeat handle option, which
 // can be used in a heartbeat processing pipeline to detect dependencies
 // inside the entity file of heartbeats of type FileType. Will prioritize
-// local file if available.
+// local file if available. Optionally, dependencies declared in the project's
+// manifest files are detected as well.
 func WithDetection(c Config) heartbeat.HandleOption {
 	return func(next heartbeat.Handle) heartbeat.Handle {
 		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 			log.Debugln("execute dependency detection")
 
+			manifestCache := make(map[string][]string)
+
 			for n, h := range hh {
 				if h.EntityType != heartbeat.FileType {
 					continue
@@ -51,6 +58,8 @@ func WithDetection(c Config) heartbeat.HandleOption {
 				}
 
 				if heartbeat.ShouldSanitize(h.Entity, c.FilePatterns) {
+					h.Explanation.Add("dependencies", "skipped, as the file name is hidden")
+
 					continue
 				}
 
@@ -68,10 +77,25 @@ func WithDetection(c Config) heartbeat.HandleOption {
 				dependencies, err := Detect(filepath, language)
 				if err != nil {
 					log.Debugf("error detecting dependencies: %s", err)
-					continue
+
+					if !c.Manifests {
+						h.Explanation.Add("dependencies", "failed to detect dependencies: %s", err)
+
+						continue
+					}
+				}
+
+				if c.Manifests {
+					dependencies = filterDependencies(append(dependencies, detectManifests(filepath, manifestCache)...))
 				}
 
 				hh[n].Dependencies = dependencies
+
+				if len(dependencies) == 0 {
+					h.Explanation.Add("dependencies", "no dependencies found")
+				} else {
+					h.Explanation.Add("dependencies", "found dependencies %s", strings.Join(dependencies, ", "))
+				}
 			}
 
 			return next(hh)
@@ -90,6 +114,10 @@ func Detect(filepath string, language heartbeat.Language) ([]string, error) {
 		parser = &ParserCPP{}
 	case heartbeat.LanguageCSharp:
 		parser = &ParserCSharp{}
+	case heartbeat.LanguageDart:
+		parser = &ParserDart{}
+	case heartbeat.LanguageElixir:
+		parser = &ParserElixir{}
 	case heartbeat.LanguageElm:
 		parser = &ParserElm{}
 	case heartbeat.LanguageGo:
@@ -106,14 +134,24 @@ func Detect(filepath string, language heartbeat.Language) ([]string, error) {
 		parser = &ParserJavaScript{}
 	case heartbeat.LanguageJSON:
 		parser = &ParserJSON{}
+	case heartbeat.LanguageJulia:
+		parser = &ParserJulia{}
 	case heartbeat.LanguageKotlin:
 		parser = &ParserKotlin{}
+	case heartbeat.LanguageLua:
+		parser = &ParserLua{}
 	case heartbeat.LanguageObjectiveC:
 		parser = &ParserObjectiveC{}
+	case heartbeat.LanguagePerl:
+		parser = &ParserPerl{}
 	case heartbeat.LanguagePHP:
 		parser = &ParserPHP{}
 	case heartbeat.LanguagePython:
 		parser = &ParserPython{}
+	case heartbeat.LanguageR:
+		parser = &ParserR{}
+	case heartbeat.LanguageRuby:
+		parser = &ParserRuby{}
 	case heartbeat.LanguageRust:
 		parser = &ParserRust{}
 	case heartbeat.LanguageScala:
@@ -122,6 +160,8 @@ func Detect(filepath string, language heartbeat.Language) ([]string, error) {
 		parser = &ParserSwift{}
 	case heartbeat.LanguageVBNet:
 		parser = &ParserVbNet{}
+	case heartbeat.LanguageZig:
+		parser = &ParserZig{}
 	default:
 		parser = &ParserUnknown{}
 	}
@@ -117,6 +117,50 @@ func TestWithDetection_LocalFile(t *testing.T) {
 	}, result)
 }
 
+func TestWithDetection_Manifests(t *testing.T) {
+	opt := deps.WithDetection(deps.Config{
+		Manifests: true,
+	})
+
+	h := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		require.Len(t, hh, 2)
+
+		assert.Equal(t, []string{"os", "github.com/spf13/cobra", "github.com/spf13/viper"}, hh[0].Dependencies[:3])
+		assert.Contains(t, hh[0].Dependencies, "react")
+		assert.Contains(t, hh[0].Dependencies, "com.google.guava:guava")
+		assert.NotContains(t, hh[0].Dependencies, "github.com/davecgh/go-spew")
+
+		// manifests are detected even if the entity file cannot be parsed
+		assert.Equal(t, hh[0].Dependencies[1:], hh[1].Dependencies)
+
+		return []heartbeat.Result{
+			{
+				Status: 201,
+			},
+		}, nil
+	})
+
+	result, err := h([]heartbeat.Heartbeat{
+		{
+			Entity:     "testdata/manifests/src/main.go",
+			EntityType: heartbeat.FileType,
+			Language:   heartbeat.PointerTo("Go"),
+		},
+		{
+			Entity:     "testdata/manifests/src/nonexisting.go",
+			EntityType: heartbeat.FileType,
+			Language:   heartbeat.PointerTo("Go"),
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []heartbeat.Result{
+		{
+			Status: 201,
+		},
+	}, result)
+}
+
 func TestWithDetection_NonFileType(t *testing.T) {
 	opt := deps.WithDetection(deps.Config{})
 
@@ -169,6 +213,16 @@ func TestDetect(t *testing.T) {
 			Language:     heartbeat.LanguageCSharp,
 			Dependencies: []string{"WakaTime"},
 		},
+		"dart": {
+			Filepath:     "testdata/dart_minimal.dart",
+			Language:     heartbeat.LanguageDart,
+			Dependencies: []string{"flutter"},
+		},
+		"elixir": {
+			Filepath:     "testdata/elixir_minimal.ex",
+			Language:     heartbeat.LanguageElixir,
+			Dependencies: []string{"GenServer"},
+		},
 		"elm": {
 			Filepath:     "testdata/elm_minimal.elm",
 			Language:     heartbeat.LanguageElm,
@@ -212,16 +266,31 @@ func TestDetect(t *testing.T) {
 			Language:     heartbeat.LanguageJSON,
 			Dependencies: []string{"bootstrap"},
 		},
+		"julia": {
+			Filepath:     "testdata/julia_minimal.jl",
+			Language:     heartbeat.LanguageJulia,
+			Dependencies: []string{"DataFrames"},
+		},
 		"kotlin": {
 			Filepath:     "testdata/kotlin_minimal.kt",
 			Language:     heartbeat.LanguageKotlin,
 			Dependencies: []string{"alpha.time"},
 		},
+		"lua": {
+			Filepath:     "testdata/lua_minimal.lua",
+			Language:     heartbeat.LanguageLua,
+			Dependencies: []string{"cjson"},
+		},
 		"objective-c": {
 			Filepath:     "testdata/objective_c_minimal.m",
 			Language:     heartbeat.LanguageObjectiveC,
 			Dependencies: []string{"Foundation"},
 		},
+		"perl": {
+			Filepath:     "testdata/perl_minimal.pl",
+			Language:     heartbeat.LanguagePerl,
+			Dependencies: []string{"LWP::UserAgent"},
+		},
 		"php": {
 			Filepath:     "testdata/php_minimal.php",
 			Language:     heartbeat.LanguagePHP,
@@ -232,6 +301,16 @@ func TestDetect(t *testing.T) {
 			Language:     heartbeat.LanguagePython,
 			Dependencies: []string{"flask", "simplejson"},
 		},
+		"r": {
+			Filepath:     "testdata/r_minimal.r",
+			Language:     heartbeat.LanguageR,
+			Dependencies: []string{"dplyr"},
+		},
+		"ruby": {
+			Filepath:     "testdata/ruby_minimal.rb",
+			Language:     heartbeat.LanguageRuby,
+			Dependencies: []string{"sinatra"},
+		},
 		"rust": {
 			Filepath:     "testdata/rust_minimal.rs",
 			Language:     heartbeat.LanguageRust,
@@ -262,6 +341,11 @@ func TestDetect(t *testing.T) {
 			Language:     heartbeat.LanguageVBNet,
 			Dependencies: []string{"WakaTime"},
 		},
+		"zig": {
+			Filepath:     "testdata/zig_minimal.zig",
+			Language:     heartbeat.LanguageZig,
+			Dependencies: []string{"std"},
+		},
 	}
 
 	for name, test := range tests {
@@ -0,0 +1,123 @@
+package deps
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// StateElixir is a token parsing state.
+type StateElixir int
+
+const (
+	// StateElixirUnknown represents an unknown token parsing state.
+	StateElixirUnknown StateElixir = iota
+	// StateElixirImport means we are in alias, import or use section during token parsing.
+	StateElixirImport
+)
+
+// ParserElixir is a dependency parser for the Elixir programming language.
+// It is not thread safe.
+type ParserElixir struct {
+	State  StateElixir
+	Output []string
+}
+
+// Parse parses dependencies from Elixir file content using the chroma Elixir lexer.
+func (p *ParserElixir) Parse(filepath string) ([]string, error) {
+	reader, err := file.OpenNoLock(filepath) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	p.init()
+	defer p.init()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from reader: %s", err)
+	}
+
+	l := lexers.Get(heartbeat.LanguageElixir.String())
+	if l == nil {
+		return nil, fmt.Errorf("failed to get lexer for %s", heartbeat.LanguageElixir.String())
+	}
+
+	iter, err := l.Tokenise(nil, string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to tokenize file content: %s", err)
+	}
+
+	for _, token := range iter.Tokens() {
+		p.processToken(token)
+	}
+
+	return p.Output, nil
+}
+
+func (p *ParserElixir) append(dep string) {
+	// if dot separated module name, select first element
+	dep = strings.Split(dep, ".")[0]
+
+	// trim whitespaces
+	dep = strings.TrimSpace(dep)
+
+	p.Output = append(p.Output, dep)
+}
+
+func (p *ParserElixir) init() {
+	p.State = StateElixirUnknown
+	p.Output = []string{}
+}
+
+func (p *ParserElixir) processToken(token chroma.Token) {
+	switch token.Type {
+	case chroma.KeywordNamespace:
+		p.processKeywordNamespace(token.Value)
+	case chroma.NameClass:
+		p.processNameClass(token.Value)
+	case chroma.Punctuation:
+		p.processPunctuation(token.Value)
+	case chroma.Text, chroma.TextWhitespace:
+		// skip whitespaces
+	default:
+		p.State = StateElixirUnknown
+	}
+}
+
+func (p *ParserElixir) processKeywordNamespace(value string) {
+	switch value {
+	case "alias", "import", "use":
+		p.State = StateElixirImport
+	default:
+		p.State = StateElixirUnknown
+	}
+}
+
+func (p *ParserElixir) processNameClass(value string) {
+	if p.State == StateElixirImport {
+		p.append(value)
+	}
+
+	p.State = StateElixirUnknown
+}
+
+func (p *ParserElixir) processPunctuation(value string) {
+	// the lexer emits empty punctuation tokens in front of module names
+	if value != "" {
+		p.State = StateElixirUnknown
+	}
+}
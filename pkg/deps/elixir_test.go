@@ -0,0 +1,25 @@
+package deps_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/deps"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserElixir_Parse(t *testing.T) {
+	parser := deps.ParserElixir{}
+
+	dependencies, err := parser.Parse("testdata/elixir.ex")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"GenServer",
+		"Phoenix",
+		"Ecto",
+		"Wakatime",
+		"Plug",
+	}, dependencies)
+}
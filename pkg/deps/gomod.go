@@ -0,0 +1,100 @@
+package deps
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// StateGoMod is a line parsing state.
+type StateGoMod int
+
+const (
+	// StateGoModUnknown represents an unknown line parsing state.
+	StateGoModUnknown StateGoMod = iota
+	// StateGoModRequireBlock means we are in a require block during line parsing.
+	StateGoModRequireBlock
+)
+
+// ParserGoMod is a dependency parser for go.mod manifest files.
+// It is not thread safe.
+type ParserGoMod struct {
+	State  StateGoMod
+	Output []string
+}
+
+// Parse parses the required modules from a go.mod file. Indirect requirements are skipped.
+func (p *ParserGoMod) Parse(filepath string) ([]string, error) {
+	reader, err := file.OpenNoLock(filepath) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	p.init()
+	defer p.init()
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		p.processLine(scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read from reader: %s", err)
+	}
+
+	return p.Output, nil
+}
+
+func (p *ParserGoMod) init() {
+	p.State = StateGoModUnknown
+	p.Output = nil
+}
+
+func (p *ParserGoMod) processLine(line string) {
+	line, comment, _ := strings.Cut(line, "//")
+	fields := strings.Fields(line)
+
+	if p.State == StateGoModRequireBlock {
+		if len(fields) > 0 && fields[0] == ")" {
+			p.State = StateGoModUnknown
+			return
+		}
+
+		p.processRequirement(fields, comment)
+
+		return
+	}
+
+	if len(fields) == 0 || fields[0] != "require" {
+		return
+	}
+
+	if len(fields) > 1 && fields[1] == "(" {
+		p.State = StateGoModRequireBlock
+		return
+	}
+
+	p.processRequirement(fields[1:], comment)
+}
+
+func (p *ParserGoMod) processRequirement(fields []string, comment string) {
+	// expect module path and version
+	if len(fields) != 2 {
+		return
+	}
+
+	if strings.TrimSpace(comment) == "indirect" {
+		return
+	}
+
+	p.Output = append(p.Output, strings.Trim(fields[0], `"`))
+}
@@ -0,0 +1,23 @@
+package deps_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/deps"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserGoMod_Parse(t *testing.T) {
+	parser := deps.ParserGoMod{}
+
+	dependencies, err := parser.Parse("testdata/manifests/go.mod")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"github.com/spf13/cobra",
+		"github.com/spf13/viper",
+		"golang.org/x/net",
+	}, dependencies)
+}
@@ -0,0 +1,144 @@
+package deps
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// StateJulia is a token parsing state.
+type StateJulia int
+
+const (
+	// StateJuliaUnknown represents an unknown token parsing state.
+	StateJuliaUnknown StateJulia = iota
+	// StateJuliaImport means we are in using or import section, expecting a module name.
+	StateJuliaImport
+	// StateJuliaModule means we are after a module name in using or import section.
+	StateJuliaModule
+	// StateJuliaSubmodule means we are in a dotted submodule or relative module name,
+	// which is not a dependency by itself.
+	StateJuliaSubmodule
+)
+
+// ParserJulia is a dependency parser for the Julia programming language.
+// It is not thread safe.
+type ParserJulia struct {
+	State  StateJulia
+	Output []string
+}
+
+// Parse parses dependencies from Julia file content using the chroma Julia lexer.
+func (p *ParserJulia) Parse(filepath string) ([]string, error) {
+	reader, err := file.OpenNoLock(filepath) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	p.init()
+	defer p.init()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from reader: %s", err)
+	}
+
+	l := lexers.Get(heartbeat.LanguageJulia.String())
+	if l == nil {
+		return nil, fmt.Errorf("failed to get lexer for %s", heartbeat.LanguageJulia.String())
+	}
+
+	iter, err := l.Tokenise(nil, string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to tokenize file content: %s", err)
+	}
+
+	for _, token := range iter.Tokens() {
+		p.processToken(token)
+	}
+
+	return p.Output, nil
+}
+
+func (p *ParserJulia) append(dep string) {
+	// trim whitespaces
+	dep = strings.TrimSpace(dep)
+
+	p.Output = append(p.Output, dep)
+}
+
+func (p *ParserJulia) init() {
+	p.State = StateJuliaUnknown
+	p.Output = []string{}
+}
+
+func (p *ParserJulia) processToken(token chroma.Token) {
+	switch token.Type {
+	case chroma.Keyword:
+		p.processKeyword(token.Value)
+	case chroma.Name:
+		p.processName(token.Value)
+	case chroma.Operator:
+		p.processOperator(token.Value)
+	case chroma.Punctuation:
+		p.processPunctuation(token.Value)
+	case chroma.Text, chroma.TextWhitespace:
+		// skip whitespaces
+	default:
+		p.State = StateJuliaUnknown
+	}
+}
+
+func (p *ParserJulia) processKeyword(value string) {
+	switch value {
+	case "using", "import":
+		p.State = StateJuliaImport
+	default:
+		p.State = StateJuliaUnknown
+	}
+}
+
+func (p *ParserJulia) processName(value string) {
+	switch p.State {
+	case StateJuliaImport:
+		p.append(value)
+		p.State = StateJuliaModule
+	case StateJuliaSubmodule:
+		p.State = StateJuliaModule
+	default:
+		p.State = StateJuliaUnknown
+	}
+}
+
+func (p *ParserJulia) processOperator(value string) {
+	switch {
+	case value == "." && p.State != StateJuliaUnknown:
+		// relative module or submodule
+		p.State = StateJuliaSubmodule
+	default:
+		// names after colon are imported from the module
+		p.State = StateJuliaUnknown
+	}
+}
+
+func (p *ParserJulia) processPunctuation(value string) {
+	if p.State == StateJuliaModule && value == "," {
+		p.State = StateJuliaImport
+		return
+	}
+
+	p.State = StateJuliaUnknown
+}
@@ -0,0 +1,25 @@
+package deps_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/deps"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserJulia_Parse(t *testing.T) {
+	parser := deps.ParserJulia{}
+
+	dependencies, err := parser.Parse("testdata/julia.jl")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"LinearAlgebra",
+		"DataFrames",
+		"CSV",
+		"Base",
+		"JSON",
+	}, dependencies)
+}
@@ -0,0 +1,131 @@
+package deps
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// StateLua is a token parsing state.
+type StateLua int
+
+const (
+	// StateLuaUnknown represents an unknown token parsing state.
+	StateLuaUnknown StateLua = iota
+	// StateLuaRequire means we are in require section during token parsing.
+	StateLuaRequire
+)
+
+// ParserLua is a dependency parser for the Lua programming language.
+// It is not thread safe.
+type ParserLua struct {
+	State  StateLua
+	Output []string
+}
+
+// Parse parses dependencies from Lua file content using the chroma Lua lexer.
+func (p *ParserLua) Parse(filepath string) ([]string, error) {
+	reader, err := file.OpenNoLock(filepath) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	p.init()
+	defer p.init()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from reader: %s", err)
+	}
+
+	l := lexers.Get(heartbeat.LanguageLua.String())
+	if l == nil {
+		return nil, fmt.Errorf("failed to get lexer for %s", heartbeat.LanguageLua.String())
+	}
+
+	iter, err := l.Tokenise(nil, string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to tokenize file content: %s", err)
+	}
+
+	for _, token := range iter.Tokens() {
+		p.processToken(token)
+	}
+
+	return p.Output, nil
+}
+
+func (p *ParserLua) append(dep string) {
+	// if dot separated module name, select first element
+	dep = strings.Split(dep, ".")[0]
+
+	// trim whitespaces
+	dep = strings.TrimSpace(dep)
+
+	p.Output = append(p.Output, dep)
+}
+
+func (p *ParserLua) init() {
+	p.State = StateLuaUnknown
+	p.Output = []string{}
+}
+
+func (p *ParserLua) processToken(token chroma.Token) {
+	switch token.Type {
+	case chroma.Name:
+		p.processName(token.Value)
+	case chroma.LiteralStringSingle, chroma.LiteralStringDouble:
+		p.processLiteralString(token.Value)
+	case chroma.Punctuation:
+		p.processPunctuation(token.Value)
+	case chroma.Text, chroma.TextWhitespace:
+		// skip whitespaces
+	default:
+		p.State = StateLuaUnknown
+	}
+}
+
+func (p *ParserLua) processName(value string) {
+	switch value {
+	case "require":
+		p.State = StateLuaRequire
+	default:
+		p.State = StateLuaUnknown
+	}
+}
+
+func (p *ParserLua) processLiteralString(value string) {
+	if p.State != StateLuaRequire {
+		return
+	}
+
+	value = strings.Trim(value, `'"`)
+
+	// skip opening quote token
+	if value == "" {
+		return
+	}
+
+	p.append(value)
+
+	p.State = StateLuaUnknown
+}
+
+func (p *ParserLua) processPunctuation(value string) {
+	if value != "(" {
+		p.State = StateLuaUnknown
+	}
+}
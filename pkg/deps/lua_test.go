@@ -0,0 +1,24 @@
+package deps_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/deps"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserLua_Parse(t *testing.T) {
+	parser := deps.ParserLua{}
+
+	dependencies, err := parser.Parse("testdata/lua.lua")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"cjson",
+		"socket",
+		"lfs",
+		"luarocks",
+	}, dependencies)
+}
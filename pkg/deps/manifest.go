@@ -0,0 +1,56 @@
+package deps
+
+import (
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/project"
+)
+
+// nolint:gochecknoglobals
+var manifests = []struct {
+	filename string
+	parser   func() DependencyParser
+}{
+	{"go.mod", func() DependencyParser { return &ParserGoMod{} }},
+	{"package.json", func() DependencyParser { return &ParserJSON{} }},
+	{"Cargo.toml", func() DependencyParser { return &ParserCargo{} }},
+	{"requirements.txt", func() DependencyParser { return &ParserRequirements{} }},
+	{"pyproject.toml", func() DependencyParser { return &ParserPyProject{} }},
+	{"Gemfile", func() DependencyParser { return &ParserRuby{} }},
+	{"pom.xml", func() DependencyParser { return &ParserPom{} }},
+}
+
+// DetectManifests parses the dependencies declared in the manifest files of the
+// project, the file at filepath belongs to. For every kind of manifest, the
+// nearest one found in the file's directory or its parent directories is used.
+func DetectManifests(filepath string) []string {
+	return detectManifests(filepath, make(map[string][]string))
+}
+
+// detectManifests parses the dependencies declared in manifest files. Parsed
+// manifests are stored in cache by their filepath and not parsed again.
+func detectManifests(filepath string, cache map[string][]string) []string {
+	var deps []string
+
+	for _, m := range manifests {
+		fp, ok := project.FindFileOrDirectory(filepath, m.filename)
+		if !ok {
+			continue
+		}
+
+		parsed, ok := cache[fp]
+		if !ok {
+			var err error
+
+			parsed, err = m.parser().Parse(fp)
+			if err != nil {
+				log.Debugf("failed to parse manifest %q: %s", fp, err)
+			}
+
+			cache[fp] = parsed
+		}
+
+		deps = append(deps, parsed...)
+	}
+
+	return deps
+}
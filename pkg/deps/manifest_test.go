@@ -0,0 +1,43 @@
+package deps_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/deps"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectManifests(t *testing.T) {
+	dependencies := deps.DetectManifests("testdata/manifests/src/main.go")
+
+	assert.Equal(t, []string{
+		"github.com/spf13/cobra",
+		"github.com/spf13/viper",
+		"golang.org/x/net",
+		"npm",
+		"react",
+		"typescript",
+		"serde_json",
+		"serde",
+		"tokio",
+		"criterion",
+		"cc",
+		"winapi",
+		"requests",
+		"Flask",
+		"simplejson",
+		"numpy",
+		"pytz",
+		"httpx",
+		"pydantic",
+		"pytest",
+		"django",
+		"black",
+		"rails",
+		"puma",
+		"rspec-rails",
+		"com.google.guava:guava",
+		"junit:junit",
+	}, dependencies)
+}
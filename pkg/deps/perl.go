@@ -0,0 +1,116 @@
+package deps
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// StatePerl is a token parsing state.
+type StatePerl int
+
+const (
+	// StatePerlUnknown represents an unknown token parsing state.
+	StatePerlUnknown StatePerl = iota
+	// StatePerlUse means we are in use section during token parsing.
+	StatePerlUse
+)
+
+// ParserPerl is a dependency parser for the Perl programming language.
+// It is not thread safe.
+type ParserPerl struct {
+	State  StatePerl
+	Output []string
+}
+
+// Parse parses dependencies from Perl file content using the chroma Perl lexer.
+func (p *ParserPerl) Parse(filepath string) ([]string, error) {
+	reader, err := file.OpenNoLock(filepath) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	p.init()
+	defer p.init()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from reader: %s", err)
+	}
+
+	l := lexers.Get(heartbeat.LanguagePerl.String())
+	if l == nil {
+		return nil, fmt.Errorf("failed to get lexer for %s", heartbeat.LanguagePerl.String())
+	}
+
+	iter, err := l.Tokenise(nil, string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to tokenize file content: %s", err)
+	}
+
+	for _, token := range iter.Tokens() {
+		p.processToken(token)
+	}
+
+	return p.Output, nil
+}
+
+func (p *ParserPerl) append(dep string) {
+	// trim whitespaces
+	dep = strings.TrimSpace(dep)
+
+	// skip pragmas like strict and warnings, which are lowercase by convention
+	if dep == "" || strings.ToLower(dep) == dep {
+		return
+	}
+
+	p.Output = append(p.Output, dep)
+}
+
+func (p *ParserPerl) init() {
+	p.State = StatePerlUnknown
+	p.Output = []string{}
+}
+
+func (p *ParserPerl) processToken(token chroma.Token) {
+	switch token.Type {
+	case chroma.Keyword:
+		p.processKeyword(token.Value)
+	case chroma.NameNamespace:
+		p.processNameNamespace(token.Value)
+	case chroma.Text, chroma.TextWhitespace:
+		// skip whitespaces
+	default:
+		p.State = StatePerlUnknown
+	}
+}
+
+func (p *ParserPerl) processKeyword(value string) {
+	switch value {
+	case "use":
+		p.State = StatePerlUse
+	default:
+		p.State = StatePerlUnknown
+	}
+}
+
+func (p *ParserPerl) processNameNamespace(value string) {
+	if p.State == StatePerlUse {
+		p.append(value)
+	}
+
+	p.State = StatePerlUnknown
+}
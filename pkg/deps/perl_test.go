@@ -0,0 +1,23 @@
+package deps_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/deps"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserPerl_Parse(t *testing.T) {
+	parser := deps.ParserPerl{}
+
+	dependencies, err := parser.Parse("testdata/perl.pl")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"LWP::UserAgent",
+		"JSON::PP",
+		"Data::Dumper",
+	}, dependencies)
+}
@@ -0,0 +1,65 @@
+package deps
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// ParserPom is a dependency parser for Maven pom.xml manifest files.
+// It is not thread safe.
+type ParserPom struct {
+	Output []string
+}
+
+// pomProject contains the dependency declarations of a pom.xml file.
+type pomProject struct {
+	Dependencies []struct {
+		GroupID    string `xml:"groupId"`
+		ArtifactID string `xml:"artifactId"`
+	} `xml:"dependencies>dependency"`
+}
+
+// Parse parses the dependencies of the project from a pom.xml file, formatted
+// as groupId:artifactId. Managed dependencies and plugins are skipped.
+func (p *ParserPom) Parse(filepath string) ([]string, error) {
+	reader, err := file.OpenNoLock(filepath) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	p.init()
+	defer p.init()
+
+	var project pomProject
+	if err := xml.NewDecoder(reader).Decode(&project); err != nil {
+		return nil, fmt.Errorf("failed to parse xml: %s", err)
+	}
+
+	for _, d := range project.Dependencies {
+		if d.ArtifactID == "" {
+			continue
+		}
+
+		if d.GroupID == "" {
+			p.Output = append(p.Output, d.ArtifactID)
+			continue
+		}
+
+		p.Output = append(p.Output, d.GroupID+":"+d.ArtifactID)
+	}
+
+	return p.Output, nil
+}
+
+func (p *ParserPom) init() {
+	p.Output = nil
+}
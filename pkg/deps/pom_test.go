@@ -0,0 +1,22 @@
+package deps_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/deps"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserPom_Parse(t *testing.T) {
+	parser := deps.ParserPom{}
+
+	dependencies, err := parser.Parse("testdata/manifests/pom.xml")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"com.google.guava:guava",
+		"junit:junit",
+	}, dependencies)
+}
@@ -0,0 +1,103 @@
+package deps
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// ParserPyProject is a dependency parser for pyproject.toml manifest files.
+// It is not thread safe.
+type ParserPyProject struct {
+	Output []string
+}
+
+// pyProject contains the dependency declarations of a pyproject.toml file,
+// as defined by PEP 621 and by Poetry.
+type pyProject struct {
+	Project struct {
+		Dependencies         []string            `toml:"dependencies"`
+		OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+	} `toml:"project"`
+	Tool struct {
+		Poetry struct {
+			Dependencies    map[string]any `toml:"dependencies"`
+			DevDependencies map[string]any `toml:"dev-dependencies"`
+			Group           map[string]struct {
+				Dependencies map[string]any `toml:"dependencies"`
+			} `toml:"group"`
+		} `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+// Parse parses the dependencies from a pyproject.toml file.
+func (p *ParserPyProject) Parse(filepath string) ([]string, error) {
+	reader, err := file.OpenNoLock(filepath) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	p.init()
+	defer p.init()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from reader: %s", err)
+	}
+
+	var manifest pyProject
+	if err := toml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse toml: %s", err)
+	}
+
+	p.processRequirements(manifest.Project.Dependencies)
+
+	for _, extra := range sortedKeys(manifest.Project.OptionalDependencies) {
+		p.processRequirements(manifest.Project.OptionalDependencies[extra])
+	}
+
+	poetry := manifest.Tool.Poetry
+
+	p.processPoetry(poetry.Dependencies)
+	p.processPoetry(poetry.DevDependencies)
+
+	for _, group := range sortedKeys(poetry.Group) {
+		p.processPoetry(poetry.Group[group].Dependencies)
+	}
+
+	return p.Output, nil
+}
+
+func (p *ParserPyProject) init() {
+	p.Output = nil
+}
+
+func (p *ParserPyProject) processRequirements(requirements []string) {
+	for _, r := range requirements {
+		if name := requirementName(r); name != "" {
+			p.Output = append(p.Output, name)
+		}
+	}
+}
+
+func (p *ParserPyProject) processPoetry(deps map[string]any) {
+	for _, name := range sortedKeys(deps) {
+		// the python version is no dependency
+		if strings.EqualFold(name, "python") {
+			continue
+		}
+
+		p.Output = append(p.Output, name)
+	}
+}
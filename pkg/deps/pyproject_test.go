@@ -0,0 +1,25 @@
+package deps_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/deps"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserPyProject_Parse(t *testing.T) {
+	parser := deps.ParserPyProject{}
+
+	dependencies, err := parser.Parse("testdata/manifests/pyproject.toml")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"httpx",
+		"pydantic",
+		"pytest",
+		"django",
+		"black",
+	}, dependencies)
+}
@@ -0,0 +1,141 @@
+package deps
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// StateR is a token parsing state.
+type StateR int
+
+const (
+	// StateRUnknown represents an unknown token parsing state.
+	StateRUnknown StateR = iota
+	// StateRLibrary means we are in library or require section during token parsing.
+	StateRLibrary
+	// StateRLibraryArgs means we are in the arguments of library or require during token parsing.
+	StateRLibraryArgs
+)
+
+// ParserR is a dependency parser for the R programming language.
+// It is not thread safe.
+type ParserR struct {
+	State  StateR
+	Output []string
+}
+
+// Parse parses dependencies from R file content using the chroma R lexer.
+func (p *ParserR) Parse(filepath string) ([]string, error) {
+	reader, err := file.OpenNoLock(filepath) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	p.init()
+	defer p.init()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from reader: %s", err)
+	}
+
+	l := lexers.Get(heartbeat.LanguageR.String())
+	if l == nil {
+		return nil, fmt.Errorf("failed to get lexer for %s", heartbeat.LanguageR.String())
+	}
+
+	iter, err := l.Tokenise(nil, string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to tokenize file content: %s", err)
+	}
+
+	for _, token := range iter.Tokens() {
+		p.processToken(token)
+	}
+
+	return p.Output, nil
+}
+
+func (p *ParserR) append(dep string) {
+	// trim quotes and whitespaces
+	dep = strings.TrimSpace(strings.Trim(dep, `'"`))
+
+	p.Output = append(p.Output, dep)
+}
+
+func (p *ParserR) init() {
+	p.State = StateRUnknown
+	p.Output = []string{}
+}
+
+func (p *ParserR) processToken(token chroma.Token) {
+	switch token.Type {
+	case chroma.NameFunction:
+		p.processNameFunction(token.Value)
+	case chroma.Name:
+		p.processName(token.Value)
+	case chroma.LiteralString:
+		p.processLiteralString(token.Value)
+	case chroma.Punctuation:
+		p.processPunctuation(token.Value)
+	case chroma.Text, chroma.TextWhitespace:
+		// skip whitespaces
+	default:
+		p.State = StateRUnknown
+	}
+}
+
+func (p *ParserR) processNameFunction(value string) {
+	switch value {
+	case "library", "require":
+		p.State = StateRLibrary
+	default:
+		p.State = StateRUnknown
+	}
+}
+
+func (p *ParserR) processName(value string) {
+	if p.State == StateRLibraryArgs {
+		p.append(value)
+	}
+
+	p.State = StateRUnknown
+}
+
+func (p *ParserR) processLiteralString(value string) {
+	if p.State != StateRLibraryArgs {
+		return
+	}
+
+	// skip opening quote token
+	if strings.Trim(value, `'"`) == "" {
+		return
+	}
+
+	p.append(value)
+
+	p.State = StateRUnknown
+}
+
+func (p *ParserR) processPunctuation(value string) {
+	if p.State == StateRLibrary && value == "(" {
+		p.State = StateRLibraryArgs
+		return
+	}
+
+	p.State = StateRUnknown
+}
@@ -0,0 +1,24 @@
+package deps_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/deps"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserR_Parse(t *testing.T) {
+	parser := deps.ParserR{}
+
+	dependencies, err := parser.Parse("testdata/r.r")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"dplyr",
+		"ggplot2",
+		"tidyr",
+		"data.table",
+	}, dependencies)
+}
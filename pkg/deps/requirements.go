@@ -0,0 +1,82 @@
+package deps
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// ParserRequirements is a dependency parser for pip requirements.txt manifest files.
+// It is not thread safe.
+type ParserRequirements struct {
+	Output []string
+}
+
+// Parse parses the required packages from a requirements.txt file. Options,
+// like -r or -e, and requirements given only by url are skipped.
+func (p *ParserRequirements) Parse(filepath string) ([]string, error) {
+	reader, err := file.OpenNoLock(filepath) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	p.init()
+	defer p.init()
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		p.processLine(scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read from reader: %s", err)
+	}
+
+	return p.Output, nil
+}
+
+func (p *ParserRequirements) init() {
+	p.Output = nil
+}
+
+func (p *ParserRequirements) processLine(line string) {
+	line, _, _ = strings.Cut(line, "#")
+	line = strings.TrimSpace(line)
+
+	if line == "" || strings.HasPrefix(line, "-") {
+		return
+	}
+
+	// requirement by url without package name, e.g. git+https://...
+	if strings.Contains(line, "://") && !strings.Contains(line, "@") {
+		return
+	}
+
+	if name := requirementName(line); name != "" {
+		p.Output = append(p.Output, name)
+	}
+}
+
+// requirementName returns the package name of a PEP 508 requirement,
+// e.g. requests for requests[security]>=2.8.1; python_version < "3.8".
+func requirementName(requirement string) string {
+	requirement = strings.TrimSpace(requirement)
+
+	end := strings.IndexFunc(requirement, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-')
+	})
+	if end == -1 {
+		return requirement
+	}
+
+	return requirement[:end]
+}
@@ -0,0 +1,25 @@
+package deps_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/deps"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserRequirements_Parse(t *testing.T) {
+	parser := deps.ParserRequirements{}
+
+	dependencies, err := parser.Parse("testdata/manifests/requirements.txt")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"requests",
+		"Flask",
+		"simplejson",
+		"numpy",
+		"pytz",
+	}, dependencies)
+}
@@ -0,0 +1,144 @@
+package deps
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// StateRuby is a token parsing state.
+type StateRuby int
+
+const (
+	// StateRubyUnknown represents an unknown token parsing state.
+	StateRubyUnknown StateRuby = iota
+	// StateRubyRequire means we are in require section during token parsing.
+	StateRubyRequire
+	// StateRubyGem means we are in gem section during token parsing.
+	StateRubyGem
+)
+
+// ParserRuby is a dependency parser for the Ruby programming language.
+// It is not thread safe.
+type ParserRuby struct {
+	State  StateRuby
+	Output []string
+}
+
+// Parse parses dependencies from Ruby file content using the chroma Ruby lexer.
+func (p *ParserRuby) Parse(filepath string) ([]string, error) {
+	reader, err := file.OpenNoLock(filepath) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	p.init()
+	defer p.init()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from reader: %s", err)
+	}
+
+	l := lexers.Get(heartbeat.LanguageRuby.String())
+	if l == nil {
+		return nil, fmt.Errorf("failed to get lexer for %s", heartbeat.LanguageRuby.String())
+	}
+
+	iter, err := l.Tokenise(nil, string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to tokenize file content: %s", err)
+	}
+
+	for _, token := range iter.Tokens() {
+		p.processToken(token)
+	}
+
+	return p.Output, nil
+}
+
+func (p *ParserRuby) append(dep string) {
+	// if slash separated require path, select first element
+	dep = strings.Split(dep, "/")[0]
+
+	// trim whitespaces
+	dep = strings.TrimSpace(dep)
+
+	p.Output = append(p.Output, dep)
+}
+
+func (p *ParserRuby) init() {
+	p.State = StateRubyUnknown
+	p.Output = []string{}
+}
+
+func (p *ParserRuby) processToken(token chroma.Token) {
+	switch token.Type {
+	case chroma.NameBuiltin:
+		p.processNameBuiltin(token.Value)
+	case chroma.Name:
+		p.processName(token.Value)
+	case chroma.LiteralStringSingle, chroma.LiteralStringDouble:
+		p.processLiteralString(token.Value)
+	case chroma.Punctuation:
+		p.processPunctuation(token.Value)
+	case chroma.Text, chroma.TextWhitespace:
+		// skip whitespaces
+	default:
+		p.State = StateRubyUnknown
+	}
+}
+
+func (p *ParserRuby) processNameBuiltin(value string) {
+	switch value {
+	case "require":
+		p.State = StateRubyRequire
+	default:
+		p.State = StateRubyUnknown
+	}
+}
+
+func (p *ParserRuby) processName(value string) {
+	switch value {
+	case "gem":
+		p.State = StateRubyGem
+	default:
+		p.State = StateRubyUnknown
+	}
+}
+
+func (p *ParserRuby) processLiteralString(value string) {
+	if p.State == StateRubyUnknown {
+		return
+	}
+
+	value = strings.Trim(value, `'"`)
+
+	// skip opening quote token
+	if value == "" {
+		return
+	}
+
+	p.append(value)
+
+	p.State = StateRubyUnknown
+}
+
+func (p *ParserRuby) processPunctuation(value string) {
+	if value != "(" {
+		p.State = StateRubyUnknown
+	}
+}
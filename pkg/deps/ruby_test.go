@@ -0,0 +1,24 @@
+package deps_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/deps"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserRuby_Parse(t *testing.T) {
+	parser := deps.ParserRuby{}
+
+	dependencies, err := parser.Parse("testdata/ruby.rb")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"json",
+		"net",
+		"active_support",
+		"rails",
+	}, dependencies)
+}
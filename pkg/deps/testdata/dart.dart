@@ -0,0 +1,12 @@
+import 'dart:async';
+import 'package:flutter/material.dart';
+import "package:http/http.dart" as http;
+import 'package:provider/provider.dart' show ChangeNotifierProvider;
+import 'src/local.dart';
+
+// import 'package:commented/commented.dart';
+
+void main() {
+  final greeting = "import 'package:string/string.dart'";
+  runApp(const MyApp());
+}
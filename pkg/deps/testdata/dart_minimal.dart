@@ -0,0 +1 @@
+import 'package:flutter/material.dart';
@@ -0,0 +1,15 @@
+defmodule Wakatime.Client do
+  use GenServer
+  use Phoenix.LiveView, layout: false
+  import Ecto.Query, only: [from: 2]
+  alias Wakatime.Repo
+  alias Plug.Conn.{Query, Status}
+  alias __MODULE__.State
+  require Logger
+
+  # use Commented
+
+  def init(state) do
+    {:ok, "use String"}
+  end
+end
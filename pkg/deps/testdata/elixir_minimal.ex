@@ -0,0 +1 @@
+use GenServer
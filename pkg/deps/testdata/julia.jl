@@ -0,0 +1,11 @@
+using LinearAlgebra
+using DataFrames, CSV
+import Base.Threads
+import JSON: parse, print
+using .LocalModule
+
+# using Commented
+
+function greet()
+    return "using String"
+end
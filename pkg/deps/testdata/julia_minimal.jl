@@ -0,0 +1 @@
+using DataFrames
@@ -0,0 +1,10 @@
+local json = require("cjson")
+local http = require "socket.http"
+local lfs = require('lfs')
+require "luarocks.loader"
+
+-- require("commented")
+
+local function greet()
+  return "require('string')"
+end
@@ -0,0 +1 @@
+local json = require("cjson")
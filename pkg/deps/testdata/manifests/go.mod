@@ -0,0 +1,13 @@
+module github.com/wakatime/example
+
+go 1.22
+
+require github.com/spf13/cobra v1.7.0
+
+require (
+	github.com/spf13/viper v1.16.0
+	"golang.org/x/net" v0.15.0
+	github.com/davecgh/go-spew v1.1.1 // indirect
+)
+
+replace github.com/spf13/viper => ../viper
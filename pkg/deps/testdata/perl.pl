@@ -0,0 +1,13 @@
+#!/usr/bin/perl
+use strict;
+use warnings;
+use 5.010;
+use LWP::UserAgent;
+use JSON::PP qw(decode_json);
+use Data::Dumper;
+use lib '/path/to/lib';
+
+# use Commented::Module;
+
+my $ua = LWP::UserAgent->new;
+print "use String::Module;\n";
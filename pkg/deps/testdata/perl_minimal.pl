@@ -0,0 +1 @@
+use LWP::UserAgent;
@@ -0,0 +1,10 @@
+library(dplyr)
+library("ggplot2")
+require(tidyr)
+suppressPackageStartupMessages(library(data.table))
+
+# library(commented)
+
+greet <- function() {
+  "library(string)"
+}
@@ -0,0 +1 @@
+library(dplyr)
@@ -0,0 +1,15 @@
+require 'json'
+require "net/http"
+require_relative 'lib/helper'
+require 'active_support/core_ext'
+gem 'rails', '~> 7.0'
+
+module Wakatime
+  # require 'commented'
+  class Client
+    def initialize
+      @client = Net::HTTP.new("api.wakatime.com")
+      @name = "require 'string'"
+    end
+  end
+end
@@ -0,0 +1,11 @@
+const std = @import("std");
+const builtin = @import("builtin");
+const clap = @import("clap");
+const utils = @import("utils.zig");
+
+// const commented = @import("commented");
+
+pub fn main() !void {
+    const greeting = "@import(\"string\")";
+    std.debug.print("{s}\n", .{greeting});
+}
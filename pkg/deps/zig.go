@@ -0,0 +1,133 @@
+package deps
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// StateZig is a token parsing state.
+type StateZig int
+
+const (
+	// StateZigUnknown represents an unknown token parsing state.
+	StateZigUnknown StateZig = iota
+	// StateZigImport means we are in import section during token parsing.
+	StateZigImport
+)
+
+// ParserZig is a dependency parser for the Zig programming language.
+// It is not thread safe.
+type ParserZig struct {
+	State  StateZig
+	Output []string
+}
+
+// Parse parses dependencies from Zig file content using the chroma Zig lexer.
+func (p *ParserZig) Parse(filepath string) ([]string, error) {
+	reader, err := file.OpenNoLock(filepath) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	p.init()
+	defer p.init()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from reader: %s", err)
+	}
+
+	l := lexers.Get(heartbeat.LanguageZig.String())
+	if l == nil {
+		return nil, fmt.Errorf("failed to get lexer for %s", heartbeat.LanguageZig.String())
+	}
+
+	iter, err := l.Tokenise(nil, string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to tokenize file content: %s", err)
+	}
+
+	for _, token := range iter.Tokens() {
+		p.processToken(token)
+	}
+
+	return p.Output, nil
+}
+
+func (p *ParserZig) append(dep string) {
+	// trim whitespaces
+	dep = strings.TrimSpace(dep)
+
+	// skip imports of local files
+	if strings.HasSuffix(dep, ".zig") || strings.HasSuffix(dep, ".zon") {
+		return
+	}
+
+	p.Output = append(p.Output, dep)
+}
+
+func (p *ParserZig) init() {
+	p.State = StateZigUnknown
+	p.Output = []string{}
+}
+
+func (p *ParserZig) processToken(token chroma.Token) {
+	switch token.Type {
+	case chroma.NameBuiltin:
+		p.processNameBuiltin(token.Value)
+	case chroma.LiteralString:
+		p.processLiteralString(token.Value)
+	case chroma.Punctuation:
+		p.processPunctuation(token.Value)
+	case chroma.Text, chroma.TextWhitespace:
+		// skip whitespaces
+	default:
+		p.State = StateZigUnknown
+	}
+}
+
+func (p *ParserZig) processNameBuiltin(value string) {
+	switch value {
+	case "@import":
+		p.State = StateZigImport
+	default:
+		p.State = StateZigUnknown
+	}
+}
+
+func (p *ParserZig) processLiteralString(value string) {
+	if p.State != StateZigImport {
+		return
+	}
+
+	value = strings.Trim(value, `"`)
+
+	// skip opening quote token
+	if value == "" {
+		return
+	}
+
+	p.append(value)
+
+	p.State = StateZigUnknown
+}
+
+func (p *ParserZig) processPunctuation(value string) {
+	if value != "(" {
+		p.State = StateZigUnknown
+	}
+}
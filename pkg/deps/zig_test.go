@@ -0,0 +1,23 @@
+package deps_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/deps"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserZig_Parse(t *testing.T) {
+	parser := deps.ParserZig{}
+
+	dependencies, err := parser.Parse("testdata/zig.zig")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"std",
+		"builtin",
+		"clap",
+	}, dependencies)
+}
@@ -0,0 +1,192 @@
+package durations
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+)
+
+// DefaultTimeout is the default maximum gap between two heartbeats, which is
+// still counted as time spent. Larger gaps are considered idle time.
+const DefaultTimeout = 15 * time.Minute
+
+// Field is a heartbeat attribute, which durations can be split by.
+type Field int
+
+const (
+	// FieldAIAssisted splits durations by whether the code was written with
+	// an ai assistant.
+	FieldAIAssisted Field = iota
+	// FieldBranch splits durations by branch.
+	FieldBranch
+	// FieldCategory splits durations by category.
+	FieldCategory
+	// FieldDependencies splits durations by the set of dependencies.
+	FieldDependencies
+	// FieldEntity splits durations by entity.
+	FieldEntity
+	// FieldLanguage splits durations by language.
+	FieldLanguage
+	// FieldProject splits durations by project.
+	FieldProject
+	// FieldUserAgent splits durations by user agent.
+	FieldUserAgent
+)
+
+// Config contains duration calculation configurations.
+type Config struct {
+	// SplitBy contains the fields, which start a new duration when their
+	// value changes between consecutive heartbeats.
+	SplitBy []Field
+	// Timeout is the maximum gap between two heartbeats, which is still
+	// counted as time spent. Defaults to DefaultTimeout.
+	Timeout time.Duration
+}
+
+// Duration represents a continuous block of time spent. Only attributes
+// listed in Config.SplitBy are set, as all others may vary inside a duration.
+type Duration struct {
+	AIAssisted   bool
+	Branch       string
+	Category     heartbeat.Category
+	Dependencies []string
+	Entity       string
+	Language     string
+	Project      string
+	UserAgent    string
+	// Start is the unix epoch timestamp of the first heartbeat.
+	Start float64
+	// End is the unix epoch timestamp, where the duration ends.
+	End float64
+	// Heartbeats is the number of heartbeats inside the duration.
+	Heartbeats int
+}
+
+// Seconds returns the length of the duration in seconds.
+func (d Duration) Seconds() float64 {
+	return d.End - d.Start
+}
+
+// Calculate turns heartbeats into durations. Heartbeats are ordered by time.
+// Consecutive heartbeats are joined into one duration, as long as the gap
+// between them does not exceed the timeout and the split by fields match.
+// If the fields differ, the gap is attributed to the earlier duration.
+// A duration without a following heartbeat inside the timeout ends at its
+// last heartbeat.
+func Calculate(hh []heartbeat.Heartbeat, config Config) []Duration {
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	sorted := make([]heartbeat.Heartbeat, len(hh))
+	copy(sorted, hh)
+
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time < sorted[j].Time })
+
+	var (
+		durations []Duration
+		current   *Duration
+		key       string
+	)
+
+	for _, h := range sorted {
+		k := groupKey(h, config.SplitBy)
+
+		if current != nil {
+			gap := h.Time - current.End
+
+			if gap <= timeout.Seconds() && k == key {
+				current.End = h.Time
+				current.Heartbeats++
+
+				continue
+			}
+
+			if gap <= timeout.Seconds() {
+				current.End = h.Time
+			}
+
+			durations = append(durations, *current)
+		}
+
+		d := newDuration(h, config.SplitBy)
+		current, key = &d, k
+	}
+
+	if current != nil {
+		durations = append(durations, *current)
+	}
+
+	return durations
+}
+
+// Total returns the summed up length of all durations in seconds.
+func Total(durations []Duration) float64 {
+	var total float64
+
+	for _, d := range durations {
+		total += d.Seconds()
+	}
+
+	return total
+}
+
+func newDuration(h heartbeat.Heartbeat, fields []Field) Duration {
+	d := Duration{
+		Start:      h.Time,
+		End:        h.Time,
+		Heartbeats: 1,
+	}
+
+	for _, field := range fields {
+		switch field {
+		case FieldAIAssisted:
+			d.AIAssisted = h.IsAIAssisted()
+		case FieldBranch:
+			d.Branch = valueOrEmpty(h.Branch)
+		case FieldCategory:
+			d.Category = h.Category
+		case FieldDependencies:
+			d.Dependencies = h.Dependencies
+		case FieldEntity:
+			d.Entity = h.Entity
+		case FieldLanguage:
+			d.Language = valueOrEmpty(h.Language)
+		case FieldProject:
+			d.Project = valueOrEmpty(h.Project)
+		case FieldUserAgent:
+			d.UserAgent = h.UserAgent
+		}
+	}
+
+	return d
+}
+
+// groupKey returns a string, which is equal for heartbeats sharing all
+// values of the passed in fields.
+func groupKey(h heartbeat.Heartbeat, fields []Field) string {
+	d := newDuration(h, fields)
+
+	return strings.Join([]string{
+		strconv.FormatBool(d.AIAssisted),
+		d.Branch,
+		d.Category.String(),
+		strings.Join(d.Dependencies, ","),
+		d.Entity,
+		d.Language,
+		d.Project,
+		d.UserAgent,
+	}, "\x00")
+}
+
+func valueOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
@@ -0,0 +1,216 @@
+package durations_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/durations"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculate(t *testing.T) {
+	hh := []heartbeat.Heartbeat{
+		testHeartbeat(1000, "wakatime-cli", "/tmp/main.go"),
+		testHeartbeat(1120, "wakatime-cli", "/tmp/main.go"),
+		testHeartbeat(1060, "wakatime-cli", "/tmp/main.go"),
+		testHeartbeat(2200, "wakatime-cli", "/tmp/main.go"),
+		testHeartbeat(2300, "wakatime", "/tmp/main.py"),
+		testHeartbeat(2400, "wakatime", "/tmp/main.py"),
+	}
+
+	dd := durations.Calculate(hh, durations.Config{
+		SplitBy: []durations.Field{durations.FieldProject},
+	})
+
+	assert.Equal(t, []durations.Duration{
+		{
+			Project:    "wakatime-cli",
+			Start:      1000,
+			End:        1120,
+			Heartbeats: 3,
+		},
+		{
+			Project:    "wakatime-cli",
+			Start:      2200,
+			End:        2300,
+			Heartbeats: 1,
+		},
+		{
+			Project:    "wakatime",
+			Start:      2300,
+			End:        2400,
+			Heartbeats: 2,
+		},
+	}, dd)
+
+	assert.Equal(t, float64(320), durations.Total(dd))
+}
+
+func TestCalculate_NoSplit(t *testing.T) {
+	hh := []heartbeat.Heartbeat{
+		testHeartbeat(1000, "wakatime-cli", "/tmp/main.go"),
+		testHeartbeat(1100, "wakatime", "/tmp/main.py"),
+		testHeartbeat(1200, "wakatime-cli", "/tmp/main.go"),
+	}
+
+	dd := durations.Calculate(hh, durations.Config{})
+
+	assert.Equal(t, []durations.Duration{
+		{
+			Start:      1000,
+			End:        1200,
+			Heartbeats: 3,
+		},
+	}, dd)
+}
+
+func TestCalculate_Timeout(t *testing.T) {
+	hh := []heartbeat.Heartbeat{
+		testHeartbeat(1000, "wakatime-cli", "/tmp/main.go"),
+		testHeartbeat(1060, "wakatime-cli", "/tmp/main.go"),
+		testHeartbeat(1180, "wakatime-cli", "/tmp/main.go"),
+	}
+
+	tests := map[string]struct {
+		Timeout  time.Duration
+		Expected float64
+	}{
+		"default": {
+			Expected: 180,
+		},
+		"gap equals timeout": {
+			Timeout:  2 * time.Minute,
+			Expected: 180,
+		},
+		"gap exceeds timeout": {
+			Timeout:  time.Minute,
+			Expected: 60,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dd := durations.Calculate(hh, durations.Config{
+				Timeout: test.Timeout,
+			})
+
+			assert.Equal(t, test.Expected, durations.Total(dd))
+		})
+	}
+}
+
+func TestCalculate_SplitBy(t *testing.T) {
+	hh := []heartbeat.Heartbeat{
+		{
+			Branch:       heartbeat.PointerTo("master"),
+			Category:     heartbeat.CodingCategory,
+			Dependencies: []string{"fmt"},
+			Entity:       "/tmp/main.go",
+			Language:     heartbeat.PointerTo("Go"),
+			Project:      heartbeat.PointerTo("wakatime-cli"),
+			Time:         1000,
+			UserAgent:    "wakatime/13.0.6 (linux) go1.20.4 vim/9.0",
+		},
+		{
+			AILineChanges: heartbeat.PointerTo(12),
+			Branch:        heartbeat.PointerTo("feature"),
+			Category:      heartbeat.DebuggingCategory,
+			Dependencies:  []string{"os"},
+			Entity:        "/tmp/file.go",
+			Language:      heartbeat.PointerTo("Golang"),
+			Project:       heartbeat.PointerTo("wakatime"),
+			Time:          1060,
+			UserAgent:     "wakatime/13.0.6 (linux) go1.20.4 vscode/1.78.2",
+		},
+	}
+
+	tests := map[string]struct {
+		Field    durations.Field
+		Expected []durations.Duration
+	}{
+		"ai assisted": {
+			Field: durations.FieldAIAssisted,
+			Expected: []durations.Duration{
+				{AIAssisted: false, Start: 1000, End: 1060, Heartbeats: 1},
+				{AIAssisted: true, Start: 1060, End: 1060, Heartbeats: 1},
+			},
+		},
+		"branch": {
+			Field: durations.FieldBranch,
+			Expected: []durations.Duration{
+				{Branch: "master", Start: 1000, End: 1060, Heartbeats: 1},
+				{Branch: "feature", Start: 1060, End: 1060, Heartbeats: 1},
+			},
+		},
+		"category": {
+			Field: durations.FieldCategory,
+			Expected: []durations.Duration{
+				{Category: heartbeat.CodingCategory, Start: 1000, End: 1060, Heartbeats: 1},
+				{Category: heartbeat.DebuggingCategory, Start: 1060, End: 1060, Heartbeats: 1},
+			},
+		},
+		"dependencies": {
+			Field: durations.FieldDependencies,
+			Expected: []durations.Duration{
+				{Dependencies: []string{"fmt"}, Start: 1000, End: 1060, Heartbeats: 1},
+				{Dependencies: []string{"os"}, Start: 1060, End: 1060, Heartbeats: 1},
+			},
+		},
+		"entity": {
+			Field: durations.FieldEntity,
+			Expected: []durations.Duration{
+				{Entity: "/tmp/main.go", Start: 1000, End: 1060, Heartbeats: 1},
+				{Entity: "/tmp/file.go", Start: 1060, End: 1060, Heartbeats: 1},
+			},
+		},
+		"language": {
+			Field: durations.FieldLanguage,
+			Expected: []durations.Duration{
+				{Language: "Go", Start: 1000, End: 1060, Heartbeats: 1},
+				{Language: "Golang", Start: 1060, End: 1060, Heartbeats: 1},
+			},
+		},
+		"project": {
+			Field: durations.FieldProject,
+			Expected: []durations.Duration{
+				{Project: "wakatime-cli", Start: 1000, End: 1060, Heartbeats: 1},
+				{Project: "wakatime", Start: 1060, End: 1060, Heartbeats: 1},
+			},
+		},
+		"user agent": {
+			Field: durations.FieldUserAgent,
+			Expected: []durations.Duration{
+				{UserAgent: "wakatime/13.0.6 (linux) go1.20.4 vim/9.0", Start: 1000, End: 1060, Heartbeats: 1},
+				{UserAgent: "wakatime/13.0.6 (linux) go1.20.4 vscode/1.78.2", Start: 1060, End: 1060, Heartbeats: 1},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dd := durations.Calculate(hh, durations.Config{
+				SplitBy: []durations.Field{test.Field},
+			})
+
+			assert.Equal(t, test.Expected, dd)
+		})
+	}
+}
+
+func TestCalculate_Empty(t *testing.T) {
+	dd := durations.Calculate(nil, durations.Config{})
+
+	assert.Empty(t, dd)
+	assert.Zero(t, durations.Total(dd))
+}
+
+func testHeartbeat(time float64, project, entity string) heartbeat.Heartbeat {
+	return heartbeat.Heartbeat{
+		Category: heartbeat.CodingCategory,
+		Entity:   entity,
+		Project:  heartbeat.PointerTo(project),
+		Time:     time,
+	}
+}
@@ -0,0 +1,134 @@
+package filestats
+
+import (
+	"bytes"
+)
+
+// maxDiffEditDistance limits the edit distance computed by the line diff. Larger
+// changes fall back to counting lines not contained in the other file.
+const maxDiffEditDistance = 2000
+
+// diffLines returns the number of lines added and deleted when changing
+// before into after.
+func diffLines(before, after []byte) (int, int) {
+	a, b := lineIDs(splitLines(before), splitLines(after))
+
+	// lines at the start and end, which did not change, don't affect the diff
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		a, b = a[1:], b[1:]
+	}
+
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	d, ok := editDistance(a, b, maxDiffEditDistance)
+	if !ok {
+		common := commonLines(a, b)
+		return len(b) - common, len(a) - common
+	}
+
+	// every edit is either an addition or a deletion
+	return (d + len(b) - len(a)) / 2, (d - len(b) + len(a)) / 2
+}
+
+// splitLines splits content into lines. Windows line endings are normalized.
+func splitLines(content []byte) [][]byte {
+	if len(content) == 0 {
+		return nil
+	}
+
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+	content = bytes.TrimSuffix(content, []byte("\n"))
+
+	return bytes.Split(content, []byte("\n"))
+}
+
+// lineIDs maps lines to ids, so that equal lines have the same id.
+func lineIDs(a, b [][]byte) ([]int, []int) {
+	ids := make(map[string]int)
+
+	toIDs := func(lines [][]byte) []int {
+		result := make([]int, len(lines))
+
+		for i, line := range lines {
+			id, ok := ids[string(line)]
+			if !ok {
+				id = len(ids)
+				ids[string(line)] = id
+			}
+
+			result[i] = id
+		}
+
+		return result
+	}
+
+	return toIDs(a), toIDs(b)
+}
+
+// editDistance returns the minimum number of line additions and deletions
+// needed to change a into b, by using the greedy algorithm of Eugene W. Myers.
+// False is returned, if the edit distance exceeds limit.
+func editDistance(a, b []int, limit int) (int, bool) {
+	n, m := len(a), len(b)
+
+	if n == 0 || m == 0 {
+		return n + m, n+m <= limit
+	}
+
+	if n+m < limit {
+		limit = n + m
+	}
+
+	// v holds the furthest reaching x for each diagonal k, offset by limit
+	v := make([]int, 2*limit+2)
+
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+
+			if k == -d || (k != d && v[limit+k-1] < v[limit+k+1]) {
+				x = v[limit+k+1]
+			} else {
+				x = v[limit+k-1] + 1
+			}
+
+			y := x - k
+
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[limit+k] = x
+
+			if x >= n && y >= m {
+				return d, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// commonLines returns the number of lines contained in both a and b,
+// regardless of their order.
+func commonLines(a, b []int) int {
+	counts := make(map[int]int)
+
+	for _, id := range a {
+		counts[id]++
+	}
+
+	var common int
+
+	for _, id := range b {
+		if counts[id] > 0 {
+			counts[id]--
+			common++
+		}
+	}
+
+	return common
+}
@@ -0,0 +1,78 @@
+package filestats
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := map[string]struct {
+		Before            string
+		After             string
+		ExpectedAdditions int
+		ExpectedDeletions int
+	}{
+		"unchanged": {
+			Before: "a\nb\nc\n",
+			After:  "a\nb\nc\n",
+		},
+		"empty before": {
+			After:             "a\nb\n",
+			ExpectedAdditions: 2,
+		},
+		"empty after": {
+			Before:            "a\nb\n",
+			ExpectedDeletions: 2,
+		},
+		"line added": {
+			Before:            "a\nb\nc\n",
+			After:             "a\nb\nx\nc\n",
+			ExpectedAdditions: 1,
+		},
+		"line changed": {
+			Before:            "a\nb\nc\n",
+			After:             "a\nx\nc\n",
+			ExpectedAdditions: 1,
+			ExpectedDeletions: 1,
+		},
+		"lines moved": {
+			Before:            "a\nb\nc\nd\n",
+			After:             "c\nd\na\nb\n",
+			ExpectedAdditions: 2,
+			ExpectedDeletions: 2,
+		},
+		"windows line endings": {
+			Before: "a\r\nb\r\n",
+			After:  "a\nb\n",
+		},
+		"missing trailing newline": {
+			Before: "a\nb\n",
+			After:  "a\nb",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			additions, deletions := diffLines([]byte(test.Before), []byte(test.After))
+
+			assert.Equal(t, test.ExpectedAdditions, additions)
+			assert.Equal(t, test.ExpectedDeletions, deletions)
+		})
+	}
+}
+
+func TestDiffLines_MaxEditDistanceExceeded(t *testing.T) {
+	var before, after strings.Builder
+
+	for i := 0; i < maxDiffEditDistance; i++ {
+		before.WriteString("a\n")
+		after.WriteString("b\n")
+	}
+
+	additions, deletions := diffLines([]byte(before.String()), []byte(after.String()))
+
+	assert.Equal(t, maxDiffEditDistance, additions)
+	assert.Equal(t, maxDiffEditDistance, deletions)
+}
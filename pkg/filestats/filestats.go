@@ -6,19 +6,43 @@ import (
 	"io"
 	"os"
 
+	"github.com/wakatime/wakatime-cli/pkg/cache"
 	"github.com/wakatime/wakatime-cli/pkg/file"
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/log"
 )
 
-// Max file size supporting line number count stats. Files larger than this in
-// bytes will not have a line count stat for performance. Default is 2MB (2*1024*1024).
-const maxFileSizeSupported = 2097152
+const (
+	// Max file size supporting line number count stats. Files larger than this in
+	// bytes will not have a line count stat for performance. Default is 2MB (2*1024*1024).
+	maxFileSizeSupported = 2097152
+	// cacheBucket is the detection cache bucket of git diff snapshots.
+	cacheBucket = "filestats"
+)
+
+// Config defines filestats detection options.
+type Config struct {
+	// Cache stores the git diff of every file as snapshot, against which line
+	// changes since the previous heartbeat are computed. Line changes are not
+	// detected without cache. Optional.
+	Cache *cache.Cache
+}
+
+// snapshot contains the number of lines added and deleted in a file compared
+// to its content in git at the time of a heartbeat.
+type snapshot struct {
+	Additions int
+	Deletions int
+}
 
 // WithDetection initializes and returns a heartbeat handle option, which
-// can be used in a heartbeat processing pipeline to detect filestats. At the
-// moment only the total number of lines in a file is detected.
-func WithDetection() heartbeat.HandleOption {
+// can be used in a heartbeat processing pipeline to detect filestats. The total
+// number of lines in a file is detected. If not set already, line additions and
+// deletions since the previous heartbeat are detected by diffing the file against
+// its content in the git index or HEAD commit and comparing the result with the
+// snapshot of the previous heartbeat. The first heartbeat of a file only records
+// a snapshot.
+func WithDetection(config Config) heartbeat.HandleOption {
 	return func(next heartbeat.Handle) heartbeat.Handle {
 		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 			log.Debugln("execute filestats detection")
@@ -32,7 +56,9 @@ func WithDetection() heartbeat.HandleOption {
 					continue
 				}
 
-				if h.Lines != nil {
+				detectLineChanges := config.Cache != nil && h.LineAdditions == nil && h.LineDeletions == nil
+
+				if h.Lines != nil && !detectLineChanges {
 					continue
 				}
 
@@ -61,13 +87,51 @@ func WithDetection() heartbeat.HandleOption {
 					continue
 				}
 
-				lines, err := countLineNumbers(filepath)
-				if err != nil {
-					log.Warnf("failed to detect the total number of lines in file %q: %s", filepath, err)
+				if h.Lines == nil {
+					lines, err := countLineNumbers(filepath)
+					if err != nil {
+						log.Warnf("failed to detect the total number of lines in file %q: %s", filepath, err)
+					} else {
+						hh[n].Lines = heartbeat.PointerTo(lines)
+					}
+				}
+
+				if !detectLineChanges {
 					continue
 				}
 
-				hh[n].Lines = heartbeat.PointerTo(lines)
+				additions, deletions, ok := detectGitLineChanges(h.Entity, filepath)
+				if !ok {
+					continue
+				}
+
+				var previous snapshot
+
+				found := config.Cache.Get(cacheBucket, h.Entity, &previous)
+
+				config.Cache.Set(cacheBucket, h.Entity, snapshot{
+					Additions: additions,
+					Deletions: deletions,
+				})
+
+				if !found {
+					h.Explanation.Add("filestats", "git diff snapshot recorded, line changes detected from next heartbeat on")
+					continue
+				}
+
+				// diff counts shrink, when changes are reverted or committed
+				additions = max(additions-previous.Additions, 0)
+				deletions = max(deletions-previous.Deletions, 0)
+
+				hh[n].LineAdditions = heartbeat.PointerTo(additions)
+				hh[n].LineDeletions = heartbeat.PointerTo(deletions)
+
+				h.Explanation.Add(
+					"filestats",
+					"%d line addition(s) and %d line deletion(s) since previous heartbeat detected by git diff",
+					additions,
+					deletions,
+				)
 			}
 
 			return next(hh)
@@ -75,6 +139,33 @@ func WithDetection() heartbeat.HandleOption {
 	}
 }
 
+// detectGitLineChanges returns the number of lines added and deleted in a file
+// compared to its content in the git index or HEAD commit. Entity is used to
+// find the git repository, while the content is read from filepath. False is
+// returned for files not tracked by git.
+func detectGitLineChanges(entity, filepath string) (int, int, bool) {
+	repo, ok := findGitRepository(entity)
+	if !ok {
+		return 0, 0, false
+	}
+
+	committed, ok := repo.readBlob(entity)
+	if !ok {
+		log.Debugf("file %q not tracked by git. Line changes won't be detected", entity)
+		return 0, 0, false
+	}
+
+	content, err := readFile(filepath)
+	if err != nil {
+		log.Warnf("failed to read file %q: %s", filepath, err)
+		return 0, 0, false
+	}
+
+	additions, deletions := diffLines(committed, content)
+
+	return additions, deletions, true
+}
+
 func countLineNumbers(filepath string) (int, error) {
 	f, err := file.OpenNoLock(filepath) // nolint:gosec
 	if err != nil {
@@ -104,3 +195,18 @@ func countLineNumbers(filepath string) (int, error) {
 		}
 	}
 }
+
+func readFile(filepath string) ([]byte, error) {
+	f, err := file.OpenNoLock(filepath) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %s", err)
+	}
+
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	return io.ReadAll(f)
+}
@@ -2,9 +2,13 @@ package filestats_test
 
 import (
 	"bytes"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/wakatime/wakatime-cli/pkg/cache"
 	"github.com/wakatime/wakatime-cli/pkg/filestats"
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 
@@ -13,7 +17,7 @@ import (
 )
 
 func TestWithDetection(t *testing.T) {
-	opt := filestats.WithDetection()
+	opt := filestats.WithDetection(filestats.Config{})
 	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		assert.Len(t, hh, 2)
 		assert.Contains(t, hh, heartbeat.Heartbeat{
@@ -54,7 +58,7 @@ func TestWithDetection(t *testing.T) {
 }
 
 func TestWithDetection_RemoteFile(t *testing.T) {
-	opt := filestats.WithDetection()
+	opt := filestats.WithDetection(filestats.Config{})
 	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		assert.Len(t, hh, 1)
 		assert.Contains(t, hh, heartbeat.Heartbeat{
@@ -94,7 +98,7 @@ func TestWithDetection_MaxFileSizeExceeded(t *testing.T) {
 	_, err = f.Write(b.Bytes())
 	require.NoError(t, err)
 
-	opt := filestats.WithDetection()
+	opt := filestats.WithDetection(filestats.Config{})
 	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		assert.Equal(t, hh, []heartbeat.Heartbeat{
 			{
@@ -115,3 +119,274 @@ func TestWithDetection_MaxFileSizeExceeded(t *testing.T) {
 	})
 	require.NoError(t, err)
 }
+
+func TestWithDetection_GitIndex(t *testing.T) {
+	tmpDir := setupTestGitRepository(t, "testdata/git_index")
+	entity := filepath.Join(tmpDir, "main.go")
+
+	err := os.WriteFile(entity, []byte("package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n"), 0600)
+	require.NoError(t, err)
+
+	c := setupTestCache(t, entity)
+
+	opt := filestats.WithDetection(filestats.Config{Cache: c})
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Equal(t, []heartbeat.Heartbeat{
+			{
+				EntityType:    heartbeat.FileType,
+				Entity:        entity,
+				LineAdditions: heartbeat.PointerTo(1),
+				LineDeletions: heartbeat.PointerTo(2),
+				Lines:         heartbeat.PointerTo(7),
+			},
+		}, hh)
+
+		return []heartbeat.Result{}, nil
+	})
+
+	_, err = handle([]heartbeat.Heartbeat{
+		{
+			EntityType: heartbeat.FileType,
+			Entity:     entity,
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestWithDetection_GitHeadPacked(t *testing.T) {
+	tmpDir := setupTestGitRepository(t, "testdata/git_packed")
+	entity := filepath.Join(tmpDir, "main.go")
+
+	var content bytes.Buffer
+
+	content.WriteString("package main\n\nimport \"fmt\"\n\nfunc main() {\n")
+
+	for i := 0; i < 52; i++ {
+		content.WriteString(fmt.Sprintf("\tfmt.Println(%d)\n", i))
+	}
+
+	content.WriteString("}\n")
+
+	err := os.WriteFile(entity, content.Bytes(), 0600)
+	require.NoError(t, err)
+
+	c := setupTestCache(t, entity)
+
+	opt := filestats.WithDetection(filestats.Config{Cache: c})
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Equal(t, []heartbeat.Heartbeat{
+			{
+				EntityType:    heartbeat.FileType,
+				Entity:        entity,
+				LineAdditions: heartbeat.PointerTo(2),
+				LineDeletions: heartbeat.PointerTo(0),
+				Lines:         heartbeat.PointerTo(58),
+			},
+		}, hh)
+
+		return []heartbeat.Result{}, nil
+	})
+
+	_, err = handle([]heartbeat.Heartbeat{
+		{
+			EntityType: heartbeat.FileType,
+			Entity:     entity,
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestWithDetection_GitUntracked(t *testing.T) {
+	tmpDir := setupTestGitRepository(t, "testdata/git_index")
+	entity := filepath.Join(tmpDir, "other.go")
+
+	err := os.WriteFile(entity, []byte("package main\n"), 0600)
+	require.NoError(t, err)
+
+	c := setupTestCache(t, entity)
+
+	opt := filestats.WithDetection(filestats.Config{Cache: c})
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Equal(t, []heartbeat.Heartbeat{
+			{
+				EntityType: heartbeat.FileType,
+				Entity:     entity,
+				Lines:      heartbeat.PointerTo(1),
+			},
+		}, hh)
+
+		return []heartbeat.Result{}, nil
+	})
+
+	_, err = handle([]heartbeat.Heartbeat{
+		{
+			EntityType: heartbeat.FileType,
+			Entity:     entity,
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestWithDetection_LineChangesSet(t *testing.T) {
+	tmpDir := setupTestGitRepository(t, "testdata/git_index")
+	entity := filepath.Join(tmpDir, "main.go")
+
+	err := os.WriteFile(entity, []byte("package main\n"), 0600)
+	require.NoError(t, err)
+
+	c := setupTestCache(t, entity)
+
+	opt := filestats.WithDetection(filestats.Config{Cache: c})
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Equal(t, []heartbeat.Heartbeat{
+			{
+				EntityType:    heartbeat.FileType,
+				Entity:        entity,
+				LineAdditions: heartbeat.PointerTo(3),
+				Lines:         heartbeat.PointerTo(1),
+			},
+		}, hh)
+
+		return []heartbeat.Result{}, nil
+	})
+
+	_, err = handle([]heartbeat.Heartbeat{
+		{
+			EntityType:    heartbeat.FileType,
+			Entity:        entity,
+			LineAdditions: heartbeat.PointerTo(3),
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestWithDetection_GitSnapshot(t *testing.T) {
+	tmpDir := setupTestGitRepository(t, "testdata/git_index")
+	entity := filepath.Join(tmpDir, "main.go")
+
+	c := cache.New(filepath.Join(t.TempDir(), "detection.bdb"))
+
+	var results []heartbeat.Heartbeat
+
+	opt := filestats.WithDetection(filestats.Config{Cache: c})
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		results = append(results, hh...)
+
+		return []heartbeat.Result{}, nil
+	})
+
+	for _, content := range []string{
+		"package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n",
+		"package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hi\")\n\tfmt.Println(\"bye\")\n}\n",
+		"package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hi\")\n\tfmt.Println(\"bye\")\n}\n",
+		"package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n",
+	} {
+		err := os.WriteFile(entity, []byte(content), 0600)
+		require.NoError(t, err)
+
+		_, err = handle([]heartbeat.Heartbeat{
+			{
+				EntityType: heartbeat.FileType,
+				Entity:     entity,
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	require.Len(t, results, 4)
+
+	// the first heartbeat only records a snapshot
+	assert.Nil(t, results[0].LineAdditions)
+	assert.Nil(t, results[0].LineDeletions)
+
+	assert.Equal(t, heartbeat.PointerTo(1), results[1].LineAdditions)
+	assert.Equal(t, heartbeat.PointerTo(0), results[1].LineDeletions)
+
+	assert.Equal(t, heartbeat.PointerTo(0), results[2].LineAdditions)
+	assert.Equal(t, heartbeat.PointerTo(0), results[2].LineDeletions)
+
+	// reverted changes are not counted as deletions
+	assert.Equal(t, heartbeat.PointerTo(0), results[3].LineAdditions)
+	assert.Equal(t, heartbeat.PointerTo(0), results[3].LineDeletions)
+}
+
+func TestWithDetection_GitWithoutCache(t *testing.T) {
+	tmpDir := setupTestGitRepository(t, "testdata/git_index")
+	entity := filepath.Join(tmpDir, "main.go")
+
+	err := os.WriteFile(entity, []byte("package main\n"), 0600)
+	require.NoError(t, err)
+
+	opt := filestats.WithDetection(filestats.Config{})
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Equal(t, []heartbeat.Heartbeat{
+			{
+				EntityType: heartbeat.FileType,
+				Entity:     entity,
+				Lines:      heartbeat.PointerTo(1),
+			},
+		}, hh)
+
+		return []heartbeat.Result{}, nil
+	})
+
+	_, err = handle([]heartbeat.Heartbeat{
+		{
+			EntityType: heartbeat.FileType,
+			Entity:     entity,
+		},
+	})
+	require.NoError(t, err)
+}
+
+// setupTestCache creates a detection cache with an empty git diff snapshot of
+// entity, so that line changes are detected by the first heartbeat.
+func setupTestCache(t *testing.T, entity string) *cache.Cache {
+	c := cache.New(filepath.Join(t.TempDir(), "detection.bdb"))
+	c.Set("filestats", entity, struct{}{})
+
+	return c
+}
+
+func setupTestGitRepository(t *testing.T, src string) string {
+	tmpDir := t.TempDir()
+
+	copyDir(t, src, filepath.Join(tmpDir, ".git"))
+
+	return tmpDir
+}
+
+func copyDir(t *testing.T, src string, dst string) {
+	err := os.MkdirAll(dst, 0700)
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(src)
+	require.NoError(t, err)
+
+	for _, entry := range entries {
+		srcPath := filepath.Join(src, entry.Name())
+		dstPath := filepath.Join(dst, entry.Name())
+
+		if entry.IsDir() {
+			copyDir(t, srcPath, dstPath)
+			continue
+		}
+
+		copyFile(t, srcPath, dstPath)
+	}
+}
+
+func copyFile(t *testing.T, source, destination string) {
+	input, err := os.Open(source)
+	require.NoError(t, err)
+
+	defer input.Close()
+
+	output, err := os.Create(destination)
+	require.NoError(t, err)
+
+	defer output.Close()
+
+	_, err = io.Copy(output, input)
+	require.NoError(t, err)
+}
@@ -0,0 +1,397 @@
+package filestats
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+const (
+	// gitHashSize is the size of sha1 object ids in bytes.
+	gitHashSize = 20
+	// maxGitIndexSize is the maximum size of git index files read in bytes.
+	// Default is 64MB (64*1024*1024).
+	maxGitIndexSize = 67108864
+	// maxGitSymrefDepth limits the number of symbolic refs followed.
+	maxGitSymrefDepth = 5
+	// maxRecursiveIteration limits the number of parent folders searched for a git repository.
+	maxRecursiveIteration = 500
+)
+
+// gitRepository contains the folders of a git repository.
+type gitRepository struct {
+	// Worktree is the root folder of the work tree.
+	Worktree string
+	// Gitdir is the git directory of the work tree.
+	Gitdir string
+	// Commondir is the git directory shared by all work trees. It contains
+	// objects and refs.
+	Commondir string
+}
+
+// findGitRepository finds the git repository a file belongs to. Repositories
+// using sha256 object ids are not supported.
+func findGitRepository(fp string) (gitRepository, bool) {
+	dir := filepath.Dir(fp)
+
+	for i := 0; i < maxRecursiveIteration; i++ {
+		dotGit := filepath.Join(dir, ".git")
+
+		if info, err := os.Stat(dotGit); err == nil {
+			gitdir := dotGit
+
+			// work trees and submodules contain a .git file pointing to the git directory
+			if !info.IsDir() {
+				resolved, ok := readGitdirFile(dotGit)
+				if !ok {
+					return gitRepository{}, false
+				}
+
+				gitdir = resolved
+			}
+
+			repo := gitRepository{
+				Worktree:  dir,
+				Gitdir:    gitdir,
+				Commondir: readCommondir(gitdir),
+			}
+
+			if repo.usesSha256() {
+				log.Debugf("git repository %q uses sha256 object ids, which are not supported", dir)
+				return gitRepository{}, false
+			}
+
+			return repo, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+
+		dir = parent
+	}
+
+	return gitRepository{}, false
+}
+
+// readGitdirFile reads the git directory from a .git file.
+func readGitdirFile(fp string) (string, bool) {
+	data, err := os.ReadFile(fp) // nolint:gosec
+	if err != nil {
+		log.Debugf("failed to read file %q: %s", fp, err)
+		return "", false
+	}
+
+	line, _, _ := strings.Cut(string(data), "\n")
+
+	gitdir, ok := strings.CutPrefix(strings.TrimSpace(line), "gitdir: ")
+	if !ok {
+		return "", false
+	}
+
+	if !filepath.IsAbs(gitdir) {
+		gitdir = filepath.Join(filepath.Dir(fp), gitdir)
+	}
+
+	return gitdir, true
+}
+
+// readCommondir returns the common git directory of linked work trees. The
+// git directory itself is returned otherwise.
+func readCommondir(gitdir string) string {
+	data, err := os.ReadFile(filepath.Join(gitdir, "commondir")) // nolint:gosec
+	if err != nil {
+		return gitdir
+	}
+
+	commondir := strings.TrimSpace(string(data))
+	if commondir == "" {
+		return gitdir
+	}
+
+	if !filepath.IsAbs(commondir) {
+		commondir = filepath.Join(gitdir, commondir)
+	}
+
+	return commondir
+}
+
+// usesSha256 returns true, if the repository uses sha256 object ids.
+func (r gitRepository) usesSha256() bool {
+	data, err := os.ReadFile(filepath.Join(r.Commondir, "config")) // nolint:gosec
+	if err != nil {
+		return false
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if ok && strings.EqualFold(strings.TrimSpace(key), "objectformat") {
+			return strings.EqualFold(strings.TrimSpace(value), "sha256")
+		}
+	}
+
+	return false
+}
+
+// readBlob returns the content of a file as staged in the git index. If the
+// file is not staged, its content as committed in HEAD is returned.
+func (r gitRepository) readBlob(fp string) ([]byte, bool) {
+	rel, err := filepath.Rel(r.Worktree, fp)
+	if err != nil {
+		return nil, false
+	}
+
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return nil, false
+	}
+
+	hash, ok := r.findIndexEntry(rel)
+	if !ok {
+		hash, ok = r.findHeadEntry(rel)
+	}
+
+	if !ok {
+		return nil, false
+	}
+
+	objType, data, err := r.readObject(hash)
+	if err != nil {
+		log.Debugf("failed to read git object %s: %s", hash, err)
+		return nil, false
+	}
+
+	if objType != gitObjectBlob {
+		return nil, false
+	}
+
+	return data, true
+}
+
+// findIndexEntry returns the object id of a file in the git index. Only
+// index versions 2, 3 and 4 are supported.
+func (r gitRepository) findIndexEntry(path string) (string, bool) {
+	fp := filepath.Join(r.Gitdir, "index")
+
+	info, err := os.Stat(fp)
+	if err != nil || info.Size() > maxGitIndexSize {
+		return "", false
+	}
+
+	data, err := os.ReadFile(fp) // nolint:gosec
+	if err != nil {
+		log.Debugf("failed to read git index %q: %s", fp, err)
+		return "", false
+	}
+
+	if len(data) < 12 || !bytes.Equal(data[:4], []byte("DIRC")) {
+		return "", false
+	}
+
+	version := binary.BigEndian.Uint32(data[4:8])
+	if version < 2 || version > 4 {
+		log.Debugf("unsupported git index version %d", version)
+		return "", false
+	}
+
+	count := binary.BigEndian.Uint32(data[8:12])
+	offset := 12
+
+	var previous []byte
+
+	for i := uint32(0); i < count; i++ {
+		// stat data (40 bytes), object id and flags
+		if offset+40+gitHashSize+2 > len(data) {
+			return "", false
+		}
+
+		start := offset
+		hash := data[offset+40 : offset+40+gitHashSize]
+		flags := binary.BigEndian.Uint16(data[offset+40+gitHashSize:])
+		offset += 40 + gitHashSize + 2
+
+		// extended flags
+		if version >= 3 && flags&0x4000 != 0 {
+			offset += 2
+		}
+
+		var name []byte
+
+		if version == 4 {
+			// path is prefix compressed relative to the previous entry
+			strip, n := readGitIndexVarint(data[offset:])
+			if n == 0 || strip > len(previous) {
+				return "", false
+			}
+
+			offset += n
+
+			end := bytes.IndexByte(data[offset:], 0)
+			if end < 0 {
+				return "", false
+			}
+
+			name = append(append([]byte{}, previous[:len(previous)-strip]...), data[offset:offset+end]...)
+			offset += end + 1
+		} else {
+			end := bytes.IndexByte(data[offset:], 0)
+			if end < 0 {
+				return "", false
+			}
+
+			name = data[offset : offset+end]
+
+			// entries are padded with 1-8 nul bytes to a multiple of 8 bytes
+			offset = start + (offset+end-start+8)/8*8
+		}
+
+		previous = name
+
+		// only use entries without merge conflicts
+		if string(name) == path && flags&0x3000 == 0 {
+			return hex.EncodeToString(hash), true
+		}
+	}
+
+	return "", false
+}
+
+// readGitIndexVarint reads an offset encoded variable length integer and
+// returns it and the number of bytes read.
+func readGitIndexVarint(data []byte) (int, int) {
+	if len(data) == 0 {
+		return 0, 0
+	}
+
+	value := int(data[0] & 0x7f)
+	n := 1
+
+	for data[n-1]&0x80 != 0 {
+		if n >= len(data) {
+			return 0, 0
+		}
+
+		value = ((value + 1) << 7) | int(data[n]&0x7f)
+		n++
+	}
+
+	return value, n
+}
+
+// findHeadEntry returns the object id of a file in the tree of the HEAD commit.
+func (r gitRepository) findHeadEntry(path string) (string, bool) {
+	hash, ok := r.resolveHead()
+	if !ok {
+		return "", false
+	}
+
+	objType, data, err := r.readObject(hash)
+	if err != nil || objType != gitObjectCommit {
+		return "", false
+	}
+
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+
+	tree, ok := bytes.CutPrefix(line, []byte("tree "))
+	if !ok {
+		return "", false
+	}
+
+	hash = string(tree)
+
+	for _, name := range strings.Split(path, "/") {
+		objType, data, err := r.readObject(hash)
+		if err != nil || objType != gitObjectTree {
+			return "", false
+		}
+
+		hash, ok = findTreeEntry(data, name)
+		if !ok {
+			return "", false
+		}
+	}
+
+	return hash, true
+}
+
+// findTreeEntry returns the object id of an entry in a tree object.
+func findTreeEntry(data []byte, name string) (string, bool) {
+	for len(data) > 0 {
+		// entries are "<mode> <name>\x00<object id>"
+		header, rest, ok := bytes.Cut(data, []byte{0})
+		if !ok || len(rest) < gitHashSize {
+			return "", false
+		}
+
+		_, entryName, _ := bytes.Cut(header, []byte(" "))
+		if string(entryName) == name {
+			return hex.EncodeToString(rest[:gitHashSize]), true
+		}
+
+		data = rest[gitHashSize:]
+	}
+
+	return "", false
+}
+
+// resolveHead returns the object id of the HEAD commit.
+func (r gitRepository) resolveHead() (string, bool) {
+	data, err := os.ReadFile(filepath.Join(r.Gitdir, "HEAD")) // nolint:gosec
+	if err != nil {
+		return "", false
+	}
+
+	head := strings.TrimSpace(string(data))
+
+	for i := 0; i < maxGitSymrefDepth; i++ {
+		ref, ok := strings.CutPrefix(head, "ref: ")
+		if !ok {
+			return head, isGitHash(head)
+		}
+
+		head, ok = r.readRef(ref)
+		if !ok {
+			return "", false
+		}
+	}
+
+	return "", false
+}
+
+// readRef reads a loose or packed ref.
+func (r gitRepository) readRef(ref string) (string, bool) {
+	if data, err := os.ReadFile(filepath.Join(r.Commondir, filepath.FromSlash(ref))); err == nil { // nolint:gosec
+		return strings.TrimSpace(string(data)), true
+	}
+
+	data, err := os.ReadFile(filepath.Join(r.Commondir, "packed-refs")) // nolint:gosec
+	if err != nil {
+		return "", false
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		hash, name, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if ok && name == ref {
+			return hash, true
+		}
+	}
+
+	return "", false
+}
+
+// isGitHash returns true, if the value is a hex encoded sha1 object id.
+func isGitHash(value string) bool {
+	if len(value) != 2*gitHashSize {
+		return false
+	}
+
+	_, err := hex.DecodeString(value)
+
+	return err == nil
+}
@@ -0,0 +1,441 @@
+package filestats
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// gitObjectType is the type of a git object.
+type gitObjectType int
+
+const (
+	gitObjectCommit   gitObjectType = 1
+	gitObjectTree     gitObjectType = 2
+	gitObjectBlob     gitObjectType = 3
+	gitObjectTag      gitObjectType = 4
+	gitObjectOfsDelta gitObjectType = 6
+	gitObjectRefDelta gitObjectType = 7
+)
+
+const (
+	// maxGitObjectSize is the maximum size of git objects read in bytes.
+	// Default is 16MB (16*1024*1024).
+	maxGitObjectSize = 16777216
+	// maxGitDeltaDepth limits the length of delta chains in pack files.
+	maxGitDeltaDepth = 100
+)
+
+// errGitObjectNotFound is returned when an object is neither loose nor packed.
+var errGitObjectNotFound = errors.New("object not found")
+
+// parseGitObjectType parses the type name of a loose object.
+func parseGitObjectType(name string) (gitObjectType, bool) {
+	switch name {
+	case "commit":
+		return gitObjectCommit, true
+	case "tree":
+		return gitObjectTree, true
+	case "blob":
+		return gitObjectBlob, true
+	case "tag":
+		return gitObjectTag, true
+	default:
+		return 0, false
+	}
+}
+
+// readObject reads a loose or packed object.
+func (r gitRepository) readObject(hash string) (gitObjectType, []byte, error) {
+	if !isGitHash(hash) {
+		return 0, nil, fmt.Errorf("invalid object id %q", hash)
+	}
+
+	return r.readObjectWithDepth(hash, 0)
+}
+
+// readObjectWithDepth reads a loose or packed object. Depth is the number of
+// deltas resolved so far.
+func (r gitRepository) readObjectWithDepth(hash string, depth int) (gitObjectType, []byte, error) {
+	objType, data, err := r.readLooseObject(hash)
+	if err == nil {
+		return objType, data, nil
+	}
+
+	if !errors.Is(err, errGitObjectNotFound) {
+		return 0, nil, err
+	}
+
+	return r.readPackedObject(hash, depth)
+}
+
+// readLooseObject reads an object from the objects folder.
+func (r gitRepository) readLooseObject(hash string) (gitObjectType, []byte, error) {
+	fp := filepath.Join(r.Commondir, "objects", hash[:2], hash[2:])
+
+	f, err := os.Open(fp) // nolint:gosec
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil, errGitObjectNotFound
+		}
+
+		return 0, nil, fmt.Errorf("failed to open file %q: %s", fp, err)
+	}
+
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	zr, err := zlib.NewReader(f)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to decompress object %q: %s", fp, err)
+	}
+
+	br := bufio.NewReader(zr)
+
+	header, err := br.ReadString(0)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read header of object %q: %s", fp, err)
+	}
+
+	// header is "<type> <size>\x00"
+	name, sizeStr, ok := bytes.Cut([]byte(header[:len(header)-1]), []byte(" "))
+	if !ok {
+		return 0, nil, fmt.Errorf("invalid header of object %q", fp)
+	}
+
+	objType, ok := parseGitObjectType(string(name))
+	if !ok {
+		return 0, nil, fmt.Errorf("invalid type %q of object %q", name, fp)
+	}
+
+	size, err := strconv.Atoi(string(sizeStr))
+	if err != nil || size > maxGitObjectSize {
+		return 0, nil, fmt.Errorf("invalid or too large size %q of object %q", sizeStr, fp)
+	}
+
+	data := make([]byte, size)
+
+	if _, err := io.ReadFull(br, data); err != nil {
+		return 0, nil, fmt.Errorf("failed to read object %q: %s", fp, err)
+	}
+
+	return objType, data, nil
+}
+
+// readPackedObject reads an object from the pack files.
+func (r gitRepository) readPackedObject(hash string, depth int) (gitObjectType, []byte, error) {
+	id, err := hex.DecodeString(hash)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid object id %q", hash)
+	}
+
+	indexes, err := filepath.Glob(filepath.Join(r.Commondir, "objects", "pack", "pack-*.idx"))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to list pack files: %s", err)
+	}
+
+	for _, idx := range indexes {
+		offset, ok, err := findPackOffset(idx, id)
+		if err != nil {
+			log.Debugf("failed to read pack index %q: %s", idx, err)
+			continue
+		}
+
+		if !ok {
+			continue
+		}
+
+		pack := idx[:len(idx)-len(".idx")] + ".pack"
+
+		return r.readPackObjectAt(pack, offset, depth)
+	}
+
+	return 0, nil, errGitObjectNotFound
+}
+
+// findPackOffset looks up the offset of an object in a version 2 pack index file.
+func findPackOffset(fp string, id []byte) (int64, bool, error) {
+	f, err := os.Open(fp) // nolint:gosec
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to open file: %s", err)
+	}
+
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	// header and fan-out table
+	header := make([]byte, 8+256*4)
+	if _, err := f.ReadAt(header, 0); err != nil {
+		return 0, false, fmt.Errorf("failed to read header: %s", err)
+	}
+
+	if !bytes.Equal(header[:8], []byte{0xff, 't', 'O', 'c', 0, 0, 0, 2}) {
+		return 0, false, fmt.Errorf("unsupported pack index version")
+	}
+
+	fanout := func(n int) uint32 {
+		if n < 0 {
+			return 0
+		}
+
+		return binary.BigEndian.Uint32(header[8+n*4:])
+	}
+
+	total := int64(fanout(255))
+	lo, hi := int64(fanout(int(id[0])-1)), int64(fanout(int(id[0])))
+
+	name := make([]byte, gitHashSize)
+
+	for lo < hi {
+		mid := (lo + hi) / 2
+
+		if _, err := f.ReadAt(name, int64(len(header))+mid*gitHashSize); err != nil {
+			return 0, false, fmt.Errorf("failed to read object id: %s", err)
+		}
+
+		switch cmp := bytes.Compare(name, id); {
+		case cmp < 0:
+			lo = mid + 1
+		case cmp > 0:
+			hi = mid
+		default:
+			return readPackIndexOffset(f, int64(len(header)), total, mid)
+		}
+	}
+
+	return 0, false, nil
+}
+
+// readPackIndexOffset reads the pack file offset of the nth object of a pack index.
+func readPackIndexOffset(f *os.File, start, total, n int64) (int64, bool, error) {
+	// object ids are followed by crc32 checksums and 4 byte offsets
+	offsets := start + total*gitHashSize + total*4
+
+	buf := make([]byte, 8)
+	if _, err := f.ReadAt(buf[:4], offsets+n*4); err != nil {
+		return 0, false, fmt.Errorf("failed to read offset: %s", err)
+	}
+
+	offset := binary.BigEndian.Uint32(buf[:4])
+	if offset&0x80000000 == 0 {
+		return int64(offset), true, nil
+	}
+
+	// the most significant bit marks an index into the table of 8 byte offsets
+	large := offsets + total*4 + int64(offset&0x7fffffff)*8
+	if _, err := f.ReadAt(buf, large); err != nil {
+		return 0, false, fmt.Errorf("failed to read large offset: %s", err)
+	}
+
+	return int64(binary.BigEndian.Uint64(buf)), true, nil
+}
+
+// readPackObjectAt reads the object at an offset of a pack file and resolves deltas.
+func (r gitRepository) readPackObjectAt(fp string, offset int64, depth int) (gitObjectType, []byte, error) {
+	if depth > maxGitDeltaDepth {
+		return 0, nil, fmt.Errorf("delta chain exceeds max depth of %d", maxGitDeltaDepth)
+	}
+
+	f, err := os.Open(fp) // nolint:gosec
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to open file %q: %s", fp, err)
+	}
+
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	br := bufio.NewReader(io.NewSectionReader(f, offset, 1<<62))
+
+	// type and size are encoded in a variable length header
+	c, err := br.ReadByte()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read object header: %s", err)
+	}
+
+	objType := gitObjectType((c >> 4) & 0x07)
+	size := int64(c & 0x0f)
+
+	for shift := 4; c&0x80 != 0; shift += 7 {
+		if c, err = br.ReadByte(); err != nil {
+			return 0, nil, fmt.Errorf("failed to read object header: %s", err)
+		}
+
+		size |= int64(c&0x7f) << shift
+	}
+
+	if size > maxGitObjectSize {
+		return 0, nil, fmt.Errorf("object size of %d bytes exceeds max size", size)
+	}
+
+	var (
+		baseType gitObjectType
+		base     []byte
+	)
+
+	switch objType {
+	case gitObjectCommit, gitObjectTree, gitObjectBlob, gitObjectTag:
+	case gitObjectOfsDelta:
+		c, err := br.ReadByte()
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to read delta base offset: %s", err)
+		}
+
+		rel := int64(c & 0x7f)
+
+		for c&0x80 != 0 {
+			if c, err = br.ReadByte(); err != nil {
+				return 0, nil, fmt.Errorf("failed to read delta base offset: %s", err)
+			}
+
+			rel = ((rel + 1) << 7) | int64(c&0x7f)
+		}
+
+		baseType, base, err = r.readPackObjectAt(fp, offset-rel, depth+1)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to read delta base: %s", err)
+		}
+	case gitObjectRefDelta:
+		id := make([]byte, gitHashSize)
+		if _, err := io.ReadFull(br, id); err != nil {
+			return 0, nil, fmt.Errorf("failed to read delta base object id: %s", err)
+		}
+
+		baseType, base, err = r.readObjectWithDepth(hex.EncodeToString(id), depth+1)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to read delta base: %s", err)
+		}
+	default:
+		return 0, nil, fmt.Errorf("invalid object type %d", objType)
+	}
+
+	zr, err := zlib.NewReader(br)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to decompress object: %s", err)
+	}
+
+	data := make([]byte, size)
+
+	if _, err := io.ReadFull(zr, data); err != nil {
+		return 0, nil, fmt.Errorf("failed to read object: %s", err)
+	}
+
+	if base == nil {
+		return objType, data, nil
+	}
+
+	patched, err := applyGitDelta(base, data)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to apply delta: %s", err)
+	}
+
+	return baseType, patched, nil
+}
+
+// applyGitDelta applies a delta of a pack file to its base object.
+func applyGitDelta(base, delta []byte) ([]byte, error) {
+	readSize := func() (int, bool) {
+		var (
+			size  int
+			shift uint
+		)
+
+		for len(delta) > 0 {
+			c := delta[0]
+			delta = delta[1:]
+			size |= int(c&0x7f) << shift
+			shift += 7
+
+			if c&0x80 == 0 {
+				return size, true
+			}
+		}
+
+		return 0, false
+	}
+
+	baseSize, ok := readSize()
+	if !ok || baseSize != len(base) {
+		return nil, fmt.Errorf("invalid base size")
+	}
+
+	resultSize, ok := readSize()
+	if !ok || resultSize > maxGitObjectSize {
+		return nil, fmt.Errorf("invalid result size")
+	}
+
+	result := make([]byte, 0, resultSize)
+
+	for len(delta) > 0 {
+		cmd := delta[0]
+		delta = delta[1:]
+
+		switch {
+		case cmd&0x80 != 0:
+			// copy from base, offset and size bytes are present per flag bit
+			var offset, size int
+
+			for i := uint(0); i < 7; i++ {
+				if cmd&(1<<i) == 0 {
+					continue
+				}
+
+				if len(delta) == 0 {
+					return nil, fmt.Errorf("truncated copy instruction")
+				}
+
+				if i < 4 {
+					offset |= int(delta[0]) << (8 * i)
+				} else {
+					size |= int(delta[0]) << (8 * (i - 4))
+				}
+
+				delta = delta[1:]
+			}
+
+			if size == 0 {
+				size = 0x10000
+			}
+
+			if offset+size > len(base) {
+				return nil, fmt.Errorf("copy instruction out of bounds")
+			}
+
+			result = append(result, base[offset:offset+size]...)
+		case cmd != 0:
+			// insert the next cmd bytes
+			if int(cmd) > len(delta) {
+				return nil, fmt.Errorf("truncated insert instruction")
+			}
+
+			result = append(result, delta[:cmd]...)
+			delta = delta[cmd:]
+		default:
+			return nil, fmt.Errorf("invalid delta instruction")
+		}
+	}
+
+	if len(result) != resultSize {
+		return nil, fmt.Errorf("invalid result size")
+	}
+
+	return result, nil
+}
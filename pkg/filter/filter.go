@@ -31,10 +31,13 @@ func WithFiltering(config Config) heartbeat.HandleOption {
 				err := Filter(h, config)
 				if err != nil {
 					log.Debugf(err.Error())
+					h.Explanation.Add("filter", "heartbeat skipped: %s", err)
 
 					continue
 				}
 
+				h.Explanation.Add("filter", "heartbeat passed include, exclude and .wakatime-project file filters")
+
 				filtered = append(filtered, h)
 			}
 
@@ -101,7 +104,9 @@ func filterByPattern(entity string, include, exclude []regex.Regex) error {
 
 // filterFileEntity determines if a heartbeat of type file should be skipped, by verifying
 // the existence of the passed in filepath, and optionally by checking if a
-// wakatime project file can be detected in the filepath directory tree.
+// wakatime project file can be detected in the filepath directory tree. Include
+// and exclude patterns of a wakatime project file are matched against the path
+// relative to its folder.
 // Returns an error to signal to the caller to skip the heartbeat.
 func filterFileEntity(h heartbeat.Heartbeat, config Config) error {
 	if h.EntityType != heartbeat.FileType {
@@ -126,11 +131,20 @@ func filterFileEntity(h heartbeat.Heartbeat, config Config) error {
 	//	return fmt.Errorf(fmt.Sprintf("skipping because of non-existing file %q", entity))
 	//}
 
+	projectFile, ok, err := project.ReadFileConfig(entity)
+	if err != nil {
+		log.Warnf("failed to read .wakatime-project file: %s", err)
+	}
+
 	// when including only with project file, skip files when the project doesn't have a .wakatime-project file
-	if config.IncludeOnlyWithProjectFile {
-		_, ok := project.FindFileOrDirectory(entity, project.WakaTimeProjectFile)
-		if !ok {
-			return fmt.Errorf("skipping because missing .wakatime-project file in parent path")
+	if config.IncludeOnlyWithProjectFile && !ok {
+		return fmt.Errorf("skipping because missing .wakatime-project file in parent path")
+	}
+
+	// filter by include and exclude patterns of the .wakatime-project file
+	if ok {
+		if err := filterByPattern(projectFile.RelativePath(entity), projectFile.Include, projectFile.Exclude); err != nil {
+			return fmt.Errorf("filter by .wakatime-project file: %s", err)
 		}
 	}
 
@@ -168,6 +168,53 @@ func TestFilter_ExistingProjectFile(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestFilter_ProjectFilePatterns(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	err := os.MkdirAll(filepath.Join(tmpDir, "docs", "public"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	err = os.WriteFile(
+		filepath.Join(tmpDir, ".wakatime-project"),
+		[]byte("project = wakatime-cli\ninclude =\n    ^docs/public/\nexclude =\n    ^docs/\n"),
+		0600,
+	)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		Entity   string
+		Expected string
+	}{
+		"excluded": {
+			Entity: filepath.Join(tmpDir, "docs", "index.md"),
+			Expected: "filter file: filter by .wakatime-project file:" +
+				" skipping because matches exclude pattern \"(?i)^docs/\"",
+		},
+		"included": {
+			Entity: filepath.Join(tmpDir, "docs", "public", "index.md"),
+		},
+		"not matching": {
+			Entity: filepath.Join(tmpDir, "main.go"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := testHeartbeat()
+			h.Entity = test.Entity
+
+			err := filter.Filter(h, filter.Config{})
+
+			if test.Expected == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			assert.EqualError(t, err, test.Expected)
+		})
+	}
+}
+
 func TestFilter_RemoteFileSkipsFiltering(t *testing.T) {
 	h := testHeartbeat()
 	h.LocalFile = h.Entity
@@ -43,6 +43,8 @@ const (
 	WritingDocsCategory
 	// WritingTestsCategory means user is currently writing tests.
 	WritingTestsCategory
+	// AICodingCategory means user is currently coding with an AI assistant.
+	AICodingCategory
 )
 
 const (
@@ -63,6 +65,7 @@ const (
 	translatingCategoryString   = "translating"
 	writingDocsCategoryString   = "writing docs"
 	writingTestsCategoryString  = "writing tests"
+	aiCodingCategoryString      = "ai coding"
 )
 
 // ParseCategory parses a category from a string.
@@ -102,6 +105,8 @@ func ParseCategory(s string) (Category, error) {
 		return WritingDocsCategory, nil
 	case writingTestsCategoryString:
 		return WritingTestsCategory, nil
+	case aiCodingCategoryString:
+		return AICodingCategory, nil
 	default:
 		return 0, fmt.Errorf("invalid category %q", s)
 	}
@@ -168,6 +173,8 @@ func (c Category) String() string {
 		return writingDocsCategoryString
 	case WritingTestsCategory:
 		return writingTestsCategoryString
+	case AICodingCategory:
+		return aiCodingCategoryString
 	default:
 		return ""
 	}
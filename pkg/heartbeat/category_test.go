@@ -29,6 +29,7 @@ func categoryTests() map[string]heartbeat.Category {
 		"translating":    heartbeat.TranslatingCategory,
 		"writing docs":   heartbeat.WritingDocsCategory,
 		"writing tests":  heartbeat.WritingTestsCategory,
+		"ai coding":      heartbeat.AICodingCategory,
 	}
 }
 
@@ -43,6 +44,13 @@ func TestParseCategory(t *testing.T) {
 	}
 }
 
+func TestCategory_Values(t *testing.T) {
+	assert.Equal(t, heartbeat.Category(0), heartbeat.CodingCategory)
+	assert.Equal(t, heartbeat.Category(1), heartbeat.BrowsingCategory)
+	assert.Equal(t, heartbeat.Category(16), heartbeat.WritingTestsCategory)
+	assert.Equal(t, heartbeat.Category(17), heartbeat.AICodingCategory)
+}
+
 func TestParseCategory_Invalid(t *testing.T) {
 	_, err := heartbeat.ParseCategory("invalid")
 	require.Error(t, err)
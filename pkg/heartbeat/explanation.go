@@ -0,0 +1,27 @@
+package heartbeat
+
+import "fmt"
+
+// Explanation records the decisions taken by the handle pipeline for a
+// heartbeat. A nil Explanation is valid and records nothing.
+type Explanation struct {
+	Steps []ExplanationStep `json:"steps"`
+}
+
+// ExplanationStep is a decision taken by a stage of the handle pipeline.
+type ExplanationStep struct {
+	Stage   string `json:"stage"`
+	Message string `json:"message"`
+}
+
+// Add records a decision of a pipeline stage.
+func (e *Explanation) Add(stage, format string, args ...any) {
+	if e == nil {
+		return
+	}
+
+	e.Steps = append(e.Steps, ExplanationStep{
+		Stage:   stage,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
@@ -19,43 +19,50 @@ var remoteAddressRegex = regexp.MustCompile(`(?i)^((ssh|sftp)://)+(?P<credential
 
 // Heartbeat is a structure representing activity for a user on a some entity.
 type Heartbeat struct {
-	APIKey                string     `json:"-"`
-	Branch                *string    `json:"branch,omitempty"`
-	BranchAlternate       string     `json:"-"`
-	Category              Category   `json:"category"`
-	CursorPosition        *int       `json:"cursorpos,omitempty"`
-	Dependencies          []string   `json:"dependencies,omitempty"`
-	Entity                string     `json:"entity"`
-	EntityType            EntityType `json:"type"`
-	IsUnsavedEntity       bool       `json:"-"`
-	IsWrite               *bool      `json:"is_write,omitempty"`
-	Language              *string    `json:"language,omitempty"`
-	LanguageAlternate     string     `json:"-"`
-	LineAdditions         *int       `json:"line_additions,omitempty"`
-	LineDeletions         *int       `json:"line_deletions,omitempty"`
-	LineNumber            *int       `json:"lineno,omitempty"`
-	Lines                 *int       `json:"lines,omitempty"`
-	LocalFile             string     `json:"-"`
-	LocalFileNeedsCleanup bool       `json:"-"`
-	Project               *string    `json:"project,omitempty"`
-	ProjectAlternate      string     `json:"-"`
-	ProjectFromGitRemote  bool       `json:"-"`
-	ProjectOverride       string     `json:"-"`
-	ProjectPath           string     `json:"-"`
-	ProjectPathOverride   string     `json:"-"`
-	ProjectRootCount      *int       `json:"project_root_count,omitempty"`
-	Time                  float64    `json:"time"`
-	UserAgent             string     `json:"user_agent"`
+	AILineChanges         *int         `json:"ai_line_changes,omitempty"`
+	APIKey                string       `json:"-"`
+	Branch                *string      `json:"branch,omitempty"`
+	BranchAlternate       string       `json:"-"`
+	Category              Category     `json:"category"`
+	CursorPosition        *int         `json:"cursorpos,omitempty"`
+	Dependencies          []string     `json:"dependencies,omitempty"`
+	Entity                string       `json:"entity"`
+	EntityType            EntityType   `json:"type"`
+	Explanation           *Explanation `json:"-"`
+	HideFileNames         bool         `json:"-"`
+	Hostname              string       `json:"-"`
+	HumanLineChanges      *int         `json:"human_line_changes,omitempty"`
+	IsUnsavedEntity       bool         `json:"-"`
+	IsWrite               *bool        `json:"is_write,omitempty"`
+	Language              *string      `json:"language,omitempty"`
+	LanguageAlternate     string       `json:"-"`
+	LineAdditions         *int         `json:"line_additions,omitempty"`
+	LineDeletions         *int         `json:"line_deletions,omitempty"`
+	LineNumber            *int         `json:"lineno,omitempty"`
+	Lines                 *int         `json:"lines,omitempty"`
+	LocalFile             string       `json:"-"`
+	LocalFileNeedsCleanup bool         `json:"-"`
+	Project               *string      `json:"project,omitempty"`
+	ProjectAlternate      string       `json:"-"`
+	ProjectFromGitRemote  bool         `json:"-"`
+	ProjectOverride       string       `json:"-"`
+	ProjectPath           string       `json:"-"`
+	ProjectPathOverride   string       `json:"-"`
+	ProjectRootCount      *int         `json:"project_root_count,omitempty"`
+	Time                  float64      `json:"time"`
+	UserAgent             string       `json:"user_agent"`
 }
 
 // New creates a new instance of Heartbeat with formatted entity
 // and local file paths for file type heartbeats.
 func New(
+	aiLineChanges *int,
 	branchAlternate string,
 	category Category,
 	cursorPosition *int,
 	entity string,
 	entityType EntityType,
+	humanLineChanges *int,
 	isUnsavedEntity bool,
 	isWrite *bool,
 	language *string,
@@ -73,11 +80,13 @@ func New(
 	userAgent string,
 ) Heartbeat {
 	return Heartbeat{
+		AILineChanges:        aiLineChanges,
 		BranchAlternate:      branchAlternate,
 		Category:             category,
 		CursorPosition:       cursorPosition,
 		Entity:               entity,
 		EntityType:           entityType,
+		HumanLineChanges:     humanLineChanges,
 		IsUnsavedEntity:      isUnsavedEntity,
 		IsWrite:              isWrite,
 		Language:             language,
@@ -124,6 +133,26 @@ func (h Heartbeat) ID() string {
 	)
 }
 
+// IsAIAssisted returns true when the heartbeat is categorized as ai coding or
+// more lines were changed by an ai assistant than by the user.
+func (h Heartbeat) IsAIAssisted() bool {
+	if h.Category == AICodingCategory {
+		return true
+	}
+
+	var aiLineChanges, humanLineChanges int
+
+	if h.AILineChanges != nil {
+		aiLineChanges = *h.AILineChanges
+	}
+
+	if h.HumanLineChanges != nil {
+		humanLineChanges = *h.HumanLineChanges
+	}
+
+	return aiLineChanges > humanLineChanges
+}
+
 // IsRemote returns true when entity is a remote file.
 func (h Heartbeat) IsRemote() bool {
 	if h.EntityType != FileType {
@@ -19,11 +19,13 @@ import (
 
 func TestNew(t *testing.T) {
 	h := heartbeat.New(
+		heartbeat.PointerTo(5),
 		"feature/branch",
 		heartbeat.CodingCategory,
 		heartbeat.PointerTo(12),
 		"testdata/main.go",
 		heartbeat.FileType,
+		heartbeat.PointerTo(7),
 		true,
 		heartbeat.PointerTo(true),
 		heartbeat.PointerTo("Go"),
@@ -44,10 +46,12 @@ func TestNew(t *testing.T) {
 	assert.True(t, strings.HasSuffix(h.Entity, "testdata/main.go"))
 
 	assert.Equal(t, heartbeat.Heartbeat{
+		AILineChanges:       heartbeat.PointerTo(5),
 		BranchAlternate:     "feature/branch",
 		Category:            heartbeat.CodingCategory,
 		CursorPosition:      heartbeat.PointerTo(12),
 		EntityType:          heartbeat.FileType,
+		HumanLineChanges:    heartbeat.PointerTo(7),
 		IsUnsavedEntity:     true,
 		IsWrite:             heartbeat.PointerTo(true),
 		Language:            heartbeat.PointerTo("Go"),
@@ -90,21 +94,24 @@ func TestHeartbeat_ID_NilFields(t *testing.T) {
 
 func TestHeartbeat_JSON(t *testing.T) {
 	h := heartbeat.Heartbeat{
-		Branch:         heartbeat.PointerTo("heartbeat"),
-		Category:       heartbeat.CodingCategory,
-		CursorPosition: heartbeat.PointerTo(12),
-		Dependencies:   []string{"dep1", "dep2"},
-		Entity:         "/tmp/main.go",
-		EntityType:     heartbeat.FileType,
-		IsWrite:        heartbeat.PointerTo(true),
-		Language:       heartbeat.PointerTo("Go"),
-		LineAdditions:  heartbeat.PointerTo(123),
-		LineDeletions:  heartbeat.PointerTo(456),
-		LineNumber:     heartbeat.PointerTo(42),
-		Lines:          heartbeat.PointerTo(100),
-		Project:        heartbeat.PointerTo("wakatime"),
-		Time:           1585598060.1,
-		UserAgent:      "wakatime/13.0.7",
+		AILineChanges:    heartbeat.PointerTo(78),
+		Branch:           heartbeat.PointerTo("heartbeat"),
+		Category:         heartbeat.CodingCategory,
+		CursorPosition:   heartbeat.PointerTo(12),
+		Dependencies:     []string{"dep1", "dep2"},
+		Entity:           "/tmp/main.go",
+		EntityType:       heartbeat.FileType,
+		Hostname:         "my-computer",
+		HumanLineChanges: heartbeat.PointerTo(90),
+		IsWrite:          heartbeat.PointerTo(true),
+		Language:         heartbeat.PointerTo("Go"),
+		LineAdditions:    heartbeat.PointerTo(123),
+		LineDeletions:    heartbeat.PointerTo(456),
+		LineNumber:       heartbeat.PointerTo(42),
+		Lines:            heartbeat.PointerTo(100),
+		Project:          heartbeat.PointerTo("wakatime"),
+		Time:             1585598060.1,
+		UserAgent:        "wakatime/13.0.7",
 	}
 
 	jsonEncoded, err := json.Marshal(h)
@@ -223,6 +230,46 @@ func TestUserAgent(t *testing.T) {
 	assert.Equal(t, expected, heartbeat.UserAgent("testplugin"))
 }
 
+func TestHeartbeat_IsAIAssisted(t *testing.T) {
+	tests := map[string]struct {
+		Heartbeat heartbeat.Heartbeat
+		Expected  bool
+	}{
+		"ai coding category": {
+			Heartbeat: heartbeat.Heartbeat{Category: heartbeat.AICodingCategory},
+			Expected:  true,
+		},
+		"more ai line changes": {
+			Heartbeat: heartbeat.Heartbeat{
+				AILineChanges:    heartbeat.PointerTo(10),
+				HumanLineChanges: heartbeat.PointerTo(2),
+			},
+			Expected: true,
+		},
+		"ai line changes only": {
+			Heartbeat: heartbeat.Heartbeat{AILineChanges: heartbeat.PointerTo(1)},
+			Expected:  true,
+		},
+		"more human line changes": {
+			Heartbeat: heartbeat.Heartbeat{
+				AILineChanges:    heartbeat.PointerTo(2),
+				HumanLineChanges: heartbeat.PointerTo(10),
+			},
+			Expected: false,
+		},
+		"no attribution": {
+			Heartbeat: heartbeat.Heartbeat{Category: heartbeat.CodingCategory},
+			Expected:  false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, test.Heartbeat.IsAIAssisted())
+		})
+	}
+}
+
 func TestRemoteAddressRegex(t *testing.T) {
 	tests := map[string]struct {
 		Heartbeat heartbeat.Heartbeat
@@ -0,0 +1,112 @@
+package heartbeat
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/regex"
+)
+
+// userAgentDetailsRegex matches the operating system and go runtime details of a user agent.
+var userAgentDetailsRegex = regexp.MustCompile(`^(\S+) \([^)]*\) go\S* `) // nolint:gochecknoglobals
+
+// PrivacyProfile declares which heartbeat fields to strip or coarsen for
+// entities matching its patterns.
+type PrivacyProfile struct {
+	// Name is the name of the profile.
+	Name string
+	// Patterns will be matched against the entity and if matching will apply the profile.
+	Patterns []regex.Regex
+	// BasenameOnly reduces file entities to their file name.
+	BasenameOnly bool
+	// MaxDepth limits file entities to this number of parent folders. Zero means no limit.
+	MaxDepth int
+	// DropDependencies removes dependencies.
+	DropDependencies bool
+	// DropLineNumbers removes cursor position and line number.
+	DropLineNumbers bool
+	// RoundTime rounds the time down to the minute.
+	RoundTime bool
+	// DropUserAgentDetails removes operating system and go runtime details from the user agent.
+	DropUserAgentDetails bool
+	// HashHostname replaces the hostname sent along with the heartbeat by its hash.
+	HashHostname bool
+}
+
+// String implements fmt.Stringer interface.
+func (p PrivacyProfile) String() string {
+	return p.Name
+}
+
+// applyPrivacyProfiles applies all privacy profiles matching entity to a heartbeat.
+// Entity is passed in separately, as it is matched before sanitization.
+func applyPrivacyProfiles(h Heartbeat, entity string, config SanitizeConfig) Heartbeat {
+	for _, profile := range config.PrivacyProfiles {
+		if !ShouldSanitize(entity, profile.Patterns) {
+			continue
+		}
+
+		h = applyPrivacyProfile(h, profile, config.Hostname)
+	}
+
+	return h
+}
+
+// applyPrivacyProfile strips and coarsens heartbeat fields following a privacy profile.
+func applyPrivacyProfile(h Heartbeat, profile PrivacyProfile, hostname string) Heartbeat {
+	if h.EntityType == FileType && !h.IsRemote() {
+		if profile.BasenameOnly {
+			h.Entity = limitDepth(h.Entity, 0)
+			h.ProjectRootCount = nil
+		} else if profile.MaxDepth > 0 {
+			h.Entity = limitDepth(h.Entity, profile.MaxDepth)
+			h.ProjectRootCount = nil
+		}
+	}
+
+	if profile.DropDependencies {
+		h.Dependencies = nil
+	}
+
+	if profile.DropLineNumbers {
+		h.CursorPosition = nil
+		h.LineNumber = nil
+	}
+
+	if profile.RoundTime {
+		h.Time = math.Floor(h.Time/60) * 60
+	}
+
+	if profile.DropUserAgentDetails {
+		h.UserAgent = userAgentDetailsRegex.ReplaceAllString(h.UserAgent, "$1 ")
+	}
+
+	if profile.HashHostname && hostname != "" {
+		h.Hostname = hashHostname(hostname)
+	}
+
+	return h
+}
+
+// limitDepth keeps the file name and at most depth parent folders of a file path.
+func limitDepth(fp string, depth int) string {
+	parts := strings.FieldsFunc(fp, func(r rune) bool {
+		return r == '/' || r == '\\'
+	})
+
+	if len(parts) <= depth+1 {
+		return fp
+	}
+
+	return strings.Join(parts[len(parts)-depth-1:], "/")
+}
+
+// hashHostname returns the first 16 hex characters of the sha256 hash of a hostname.
+func hashHostname(hostname string) string {
+	sum := sha256.Sum256([]byte(hostname))
+
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,206 @@
+package heartbeat_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/regex"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitize_PrivacyProfile_BasenameOnly(t *testing.T) {
+	h := testPrivacyHeartbeat()
+
+	r := heartbeat.Sanitize(h, privacyConfig(heartbeat.PrivacyProfile{BasenameOnly: true}))
+
+	h.Entity = "main.go"
+	h.ProjectRootCount = nil
+
+	assert.Equal(t, h, r)
+}
+
+func TestSanitize_PrivacyProfile_BasenameOnly_Windows(t *testing.T) {
+	h := testPrivacyHeartbeat()
+	h.Entity = `C:\clients\acme\pkg\main.go`
+
+	r := heartbeat.Sanitize(h, heartbeat.SanitizeConfig{
+		PrivacyProfiles: []heartbeat.PrivacyProfile{
+			{
+				Name:         "client",
+				Patterns:     []regex.Regex{regexp.MustCompile(`^C:\\clients\\`)},
+				BasenameOnly: true,
+			},
+		},
+	})
+
+	assert.Equal(t, "main.go", r.Entity)
+}
+
+func TestSanitize_PrivacyProfile_BasenameOnly_App(t *testing.T) {
+	h := testPrivacyHeartbeat()
+	h.Entity = "/clients/Slack"
+	h.EntityType = heartbeat.AppType
+
+	r := heartbeat.Sanitize(h, privacyConfig(heartbeat.PrivacyProfile{BasenameOnly: true}))
+
+	assert.Equal(t, "/clients/Slack", r.Entity)
+}
+
+func TestSanitize_PrivacyProfile_MaxDepth(t *testing.T) {
+	tests := map[string]struct {
+		MaxDepth int
+		Expected string
+	}{
+		"one folder": {
+			MaxDepth: 1,
+			Expected: "pkg/main.go",
+		},
+		"two folders": {
+			MaxDepth: 2,
+			Expected: "acme/pkg/main.go",
+		},
+		"more folders than available": {
+			MaxDepth: 10,
+			Expected: "/clients/acme/pkg/main.go",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := heartbeat.Sanitize(testPrivacyHeartbeat(), privacyConfig(heartbeat.PrivacyProfile{
+				MaxDepth: test.MaxDepth,
+			}))
+
+			assert.Equal(t, test.Expected, r.Entity)
+		})
+	}
+}
+
+func TestSanitize_PrivacyProfile_DropDependencies(t *testing.T) {
+	h := testPrivacyHeartbeat()
+
+	r := heartbeat.Sanitize(h, privacyConfig(heartbeat.PrivacyProfile{DropDependencies: true}))
+
+	h.Dependencies = nil
+
+	assert.Equal(t, h, r)
+}
+
+func TestSanitize_PrivacyProfile_DropLineNumbers(t *testing.T) {
+	h := testPrivacyHeartbeat()
+
+	r := heartbeat.Sanitize(h, privacyConfig(heartbeat.PrivacyProfile{DropLineNumbers: true}))
+
+	h.CursorPosition = nil
+	h.LineNumber = nil
+
+	assert.Equal(t, h, r)
+}
+
+func TestSanitize_PrivacyProfile_RoundTime(t *testing.T) {
+	h := testPrivacyHeartbeat()
+
+	r := heartbeat.Sanitize(h, privacyConfig(heartbeat.PrivacyProfile{RoundTime: true}))
+
+	h.Time = 1585598040
+
+	assert.Equal(t, h, r)
+}
+
+func TestSanitize_PrivacyProfile_DropUserAgentDetails(t *testing.T) {
+	h := testPrivacyHeartbeat()
+
+	r := heartbeat.Sanitize(h, privacyConfig(heartbeat.PrivacyProfile{DropUserAgentDetails: true}))
+
+	h.UserAgent = "wakatime/13.0.7 vscode/1.80.0 vscode-wakatime/24.2.0"
+
+	assert.Equal(t, h, r)
+}
+
+func TestSanitize_PrivacyProfile_HashHostname(t *testing.T) {
+	h := testPrivacyHeartbeat()
+
+	config := privacyConfig(heartbeat.PrivacyProfile{HashHostname: true})
+	config.Hostname = "my-computer"
+
+	r := heartbeat.Sanitize(h, config)
+
+	// sha256 of my-computer
+	h.Hostname = "0140090ff45909b5"
+
+	assert.Equal(t, h, r)
+}
+
+func TestSanitize_PrivacyProfile_NotMatching(t *testing.T) {
+	h := testPrivacyHeartbeat()
+	h.Entity = "/home/user/projects/main.go"
+
+	r := heartbeat.Sanitize(h, privacyConfig(heartbeat.PrivacyProfile{
+		BasenameOnly:         true,
+		DropDependencies:     true,
+		DropLineNumbers:      true,
+		DropUserAgentDetails: true,
+		HashHostname:         true,
+		RoundTime:            true,
+	}))
+
+	assert.Equal(t, h, r)
+}
+
+func TestSanitize_PrivacyProfile_MatchesBeforeHidingProjectFolder(t *testing.T) {
+	h := testPrivacyHeartbeat()
+	h.ProjectPath = "/clients/acme"
+
+	config := privacyConfig(heartbeat.PrivacyProfile{DropDependencies: true})
+	config.HideProjectFolder = true
+
+	r := heartbeat.Sanitize(h, config)
+
+	assert.Equal(t, "pkg/main.go", r.Entity)
+	assert.Nil(t, r.Dependencies)
+}
+
+func TestSanitize_PrivacyProfile_Explanation(t *testing.T) {
+	h := testPrivacyHeartbeat()
+	h.Explanation = &heartbeat.Explanation{}
+
+	_ = heartbeat.Sanitize(h, privacyConfig(heartbeat.PrivacyProfile{RoundTime: true}))
+
+	assert.Equal(t, []heartbeat.ExplanationStep{
+		{
+			Stage:   "sanitize",
+			Message: `privacy profile "client" applied by pattern "^/clients/"`,
+		},
+	}, h.Explanation.Steps)
+}
+
+func privacyConfig(profile heartbeat.PrivacyProfile) heartbeat.SanitizeConfig {
+	profile.Name = "client"
+	profile.Patterns = []regex.Regex{regexp.MustCompile("^/clients/")}
+
+	return heartbeat.SanitizeConfig{
+		PrivacyProfiles: []heartbeat.PrivacyProfile{profile},
+	}
+}
+
+func testPrivacyHeartbeat() heartbeat.Heartbeat {
+	return heartbeat.Heartbeat{
+		Branch:           heartbeat.PointerTo("main"),
+		Category:         heartbeat.CodingCategory,
+		CursorPosition:   heartbeat.PointerTo(12),
+		Dependencies:     []string{"dep1", "dep2"},
+		Entity:           "/clients/acme/pkg/main.go",
+		EntityType:       heartbeat.FileType,
+		IsWrite:          heartbeat.PointerTo(true),
+		Language:         heartbeat.PointerTo("Go"),
+		LineNumber:       heartbeat.PointerTo(42),
+		Lines:            heartbeat.PointerTo(100),
+		Project:          heartbeat.PointerTo("acme"),
+		ProjectRootCount: heartbeat.PointerTo(2),
+		Time:             1585598060.5,
+		UserAgent: "wakatime/13.0.7 (linux-6.1.0-x86_64) go1.22.1 vscode/1.80.0" +
+			" vscode-wakatime/24.2.0",
+	}
+}
@@ -20,6 +20,19 @@ type SanitizeConfig struct {
 	// ProjectPatterns will be matched against the project name and if matching will obfuscate
 	// common heartbeat meta data (cursor position, dependencies, line number and lines).
 	ProjectPatterns []regex.Regex
+	// Pseudonymizer, if set, replaces hidden file and branch names with stable tokens
+	// instead of dropping them.
+	Pseudonymizer Pseudonymizer
+	// PrivacyProfiles will be matched against the entity and if matching will strip
+	// or coarsen the heartbeat fields declared by the profile.
+	PrivacyProfiles []PrivacyProfile
+	// Hostname is the hostname hashed by privacy profiles.
+	Hostname string
+}
+
+// Pseudonymizer replaces names with stable tokens.
+type Pseudonymizer interface {
+	Pseudonymize(name string) string
 }
 
 // WithSanitization initializes and returns a heartbeat handle option, which
@@ -45,35 +58,113 @@ func Sanitize(h Heartbeat, config SanitizeConfig) Heartbeat {
 		h.Dependencies = nil
 	}
 
+	explainSanitization(h, config)
+
+	entity := h.Entity
+
 	switch {
-	case ShouldSanitize(h.Entity, config.FilePatterns):
-		if h.EntityType == FileType {
-			h.Entity = "HIDDEN" + filepath.Ext(h.Entity)
-		} else {
-			h.Entity = "HIDDEN"
-		}
+	case h.HideFileNames || ShouldSanitize(h.Entity, config.FilePatterns):
+		h.Entity = hideEntity(h, config.Pseudonymizer)
 
 		h = sanitizeMetaData(h)
 
 		if h.Branch != nil && (len(config.BranchPatterns) == 0 || ShouldSanitize(*h.Branch, config.BranchPatterns)) {
-			h.Branch = nil
+			h.Branch = hideBranch(*h.Branch, config.Pseudonymizer)
 		}
 	case h.Project != nil && ShouldSanitize(*h.Project, config.ProjectPatterns):
 		h = sanitizeMetaData(h)
 		if h.Branch != nil && (len(config.BranchPatterns) == 0 || ShouldSanitize(*h.Branch, config.BranchPatterns)) {
-			h.Branch = nil
+			h.Branch = hideBranch(*h.Branch, config.Pseudonymizer)
 		}
 	case h.Branch != nil && ShouldSanitize(*h.Branch, config.BranchPatterns):
-		h.Branch = nil
+		h.Branch = hideBranch(*h.Branch, config.Pseudonymizer)
 	}
 
 	h = hideProjectFolder(h, config.HideProjectFolder)
 
 	h = hideCredentials(h)
 
+	h = applyPrivacyProfiles(h, entity, config)
+
 	return h
 }
 
+// hideEntity returns the hidden entity. Without pseudonymizer all entities
+// collapse into HIDDEN, keeping only the file extension. Files are
+// pseudonymized without their extension, so that revealing restores the path.
+func hideEntity(h Heartbeat, pseudonymizer Pseudonymizer) string {
+	var ext string
+	if h.EntityType == FileType {
+		ext = filepath.Ext(h.Entity)
+	}
+
+	if pseudonymizer == nil {
+		return "HIDDEN" + ext
+	}
+
+	return pseudonymizer.Pseudonymize(strings.TrimSuffix(h.Entity, ext)) + ext
+}
+
+// hideBranch returns the hidden branch. Without pseudonymizer the branch is dropped.
+func hideBranch(branch string, pseudonymizer Pseudonymizer) *string {
+	if pseudonymizer == nil {
+		return nil
+	}
+
+	return PointerTo(pseudonymizer.Pseudonymize(branch))
+}
+
+// explainSanitization records which data of a heartbeat is hidden and why.
+func explainSanitization(h Heartbeat, config SanitizeConfig) {
+	if h.Explanation == nil {
+		return
+	}
+
+	var (
+		hidden bool
+		steps  = len(h.Explanation.Steps)
+	)
+
+	if h.HideFileNames {
+		h.Explanation.Add("sanitize", "file name and meta data hidden by .wakatime-project file")
+		hidden = true
+	} else if pattern, ok := matchingPattern(h.Entity, config.FilePatterns); ok {
+		h.Explanation.Add("sanitize", "file name and meta data hidden by hide_file_names pattern %q", pattern)
+		hidden = true
+	} else if h.Project != nil {
+		if pattern, ok := matchingPattern(*h.Project, config.ProjectPatterns); ok {
+			h.Explanation.Add("sanitize", "meta data hidden by hide_project_names pattern %q", pattern)
+			hidden = true
+		}
+	}
+
+	if h.Branch != nil {
+		if pattern, ok := matchingPattern(*h.Branch, config.BranchPatterns); ok {
+			h.Explanation.Add("sanitize", "branch hidden by hide_branch_names pattern %q", pattern)
+		} else if hidden && len(config.BranchPatterns) == 0 {
+			h.Explanation.Add("sanitize", "branch hidden together with meta data")
+		}
+	}
+
+	if config.Pseudonymizer != nil && len(h.Explanation.Steps) > steps {
+		h.Explanation.Add("sanitize", "hidden names replaced by stable pseudonyms")
+	}
+
+	if config.HideProjectFolder && h.EntityType == FileType {
+		h.Explanation.Add("sanitize", "project folder hidden from file path")
+	}
+
+	for _, profile := range config.PrivacyProfiles {
+		if pattern, ok := matchingPattern(h.Entity, profile.Patterns); ok {
+			h.Explanation.Add("sanitize", "privacy profile %q applied by pattern %q", profile.Name, pattern)
+		}
+	}
+
+	if len(h.Explanation.Steps) == steps {
+		h.Explanation.Add("sanitize", "nothing hidden, as no sanitize pattern matched")
+	}
+}
+
 // hideProjectFolder makes entity relative to project folder if we're hiding the project folder.
 func hideProjectFolder(h Heartbeat, hideProjectFolder bool) Heartbeat {
 	if h.EntityType != FileType || !hideProjectFolder {
@@ -143,11 +234,18 @@ func sanitizeMetaData(h Heartbeat) Heartbeat {
 // checks it against the passed in regex patterns to determine, if this heartbeat
 // should be sanitized.
 func ShouldSanitize(subject string, patterns []regex.Regex) bool {
+	_, ok := matchingPattern(subject, patterns)
+
+	return ok
+}
+
+// matchingPattern returns the first of the passed in regex patterns matching the subject.
+func matchingPattern(subject string, patterns []regex.Regex) (string, bool) {
 	for _, p := range patterns {
 		if p.MatchString(subject) {
-			return true
+			return p.String(), true
 		}
 	}
 
-	return false
+	return "", false
 }
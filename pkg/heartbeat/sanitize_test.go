@@ -143,6 +143,28 @@ func TestSanitize_ObfuscateFile_SkipBranchIfNotMatching(t *testing.T) {
 	}, r)
 }
 
+func TestSanitize_ObfuscateFile_HideFileNames(t *testing.T) {
+	h := testHeartbeat()
+	h.HideFileNames = true
+
+	r := heartbeat.Sanitize(h, heartbeat.SanitizeConfig{
+		BranchPatterns: []regex.Regex{regexp.MustCompile("not_matching")},
+	})
+
+	assert.Equal(t, heartbeat.Heartbeat{
+		Branch:        heartbeat.PointerTo("heartbeat"),
+		Category:      heartbeat.CodingCategory,
+		Entity:        "HIDDEN.go",
+		EntityType:    heartbeat.FileType,
+		HideFileNames: true,
+		IsWrite:       heartbeat.PointerTo(true),
+		Language:      heartbeat.PointerTo("Go"),
+		Project:       heartbeat.PointerTo("wakatime"),
+		Time:          1585598060,
+		UserAgent:     "wakatime/13.0.7",
+	}, r)
+}
+
 func TestSanitize_ObfuscateFile_NilFields(t *testing.T) {
 	h := testHeartbeat()
 	h.Branch = nil
@@ -164,6 +186,40 @@ func TestSanitize_ObfuscateFile_NilFields(t *testing.T) {
 	}, r)
 }
 
+func TestSanitize_PseudonymizeFile(t *testing.T) {
+	r := heartbeat.Sanitize(testHeartbeat(), heartbeat.SanitizeConfig{
+		FilePatterns:  []regex.Regex{regexp.MustCompile(".*")},
+		Pseudonymizer: pseudonymizerMock{},
+	})
+
+	assert.Equal(t, heartbeat.Heartbeat{
+		Branch:     heartbeat.PointerTo("token(heartbeat)"),
+		Category:   heartbeat.CodingCategory,
+		Entity:     "token(/tmp/main).go",
+		EntityType: heartbeat.FileType,
+		IsWrite:    heartbeat.PointerTo(true),
+		Language:   heartbeat.PointerTo("Go"),
+		Project:    heartbeat.PointerTo("wakatime"),
+		Time:       1585598060,
+		UserAgent:  "wakatime/13.0.7",
+	}, r)
+}
+
+func TestSanitize_PseudonymizeFile_App(t *testing.T) {
+	h := testHeartbeat()
+	h.Entity = "Slack"
+	h.EntityType = heartbeat.AppType
+
+	r := heartbeat.Sanitize(h, heartbeat.SanitizeConfig{
+		FilePatterns:   []regex.Regex{regexp.MustCompile(".*")},
+		BranchPatterns: []regex.Regex{regexp.MustCompile("not_matching")},
+		Pseudonymizer:  pseudonymizerMock{},
+	})
+
+	assert.Equal(t, "token(Slack)", r.Entity)
+	assert.Equal(t, heartbeat.PointerTo("heartbeat"), r.Branch)
+}
+
 func TestSanitize_ObfuscateProject(t *testing.T) {
 	r := heartbeat.Sanitize(testHeartbeat(), heartbeat.SanitizeConfig{
 		ProjectPatterns: []regex.Regex{regexp.MustCompile(".*")},
@@ -242,6 +298,16 @@ func TestSanitize_ObfuscateBranch(t *testing.T) {
 	}, r)
 }
 
+func TestSanitize_PseudonymizeBranch(t *testing.T) {
+	r := heartbeat.Sanitize(testHeartbeat(), heartbeat.SanitizeConfig{
+		BranchPatterns: []regex.Regex{regexp.MustCompile(".*")},
+		Pseudonymizer:  pseudonymizerMock{},
+	})
+
+	assert.Equal(t, heartbeat.PointerTo("token(heartbeat)"), r.Branch)
+	assert.Equal(t, "/tmp/main.go", r.Entity)
+}
+
 func TestSanitize_ObfuscateBranch_NilFields(t *testing.T) {
 	h := testHeartbeat()
 	h.Branch = nil
@@ -444,3 +510,74 @@ func testHeartbeat() heartbeat.Heartbeat {
 		UserAgent:      "wakatime/13.0.7",
 	}
 }
+
+func TestSanitize_Explanation(t *testing.T) {
+	h := testHeartbeat()
+	h.Explanation = &heartbeat.Explanation{}
+
+	_ = heartbeat.Sanitize(h, heartbeat.SanitizeConfig{
+		FilePatterns:      []regex.Regex{regexp.MustCompile("^/home/")},
+		HideProjectFolder: true,
+		ProjectPatterns:   []regex.Regex{regexp.MustCompile("^wakatime$")},
+	})
+
+	assert.Equal(t, []heartbeat.ExplanationStep{
+		{
+			Stage:   "sanitize",
+			Message: `meta data hidden by hide_project_names pattern "^wakatime$"`,
+		},
+		{
+			Stage:   "sanitize",
+			Message: "branch hidden together with meta data",
+		},
+		{
+			Stage:   "sanitize",
+			Message: "project folder hidden from file path",
+		},
+	}, h.Explanation.Steps)
+}
+
+func TestSanitize_Explanation_NothingHidden(t *testing.T) {
+	h := testHeartbeat()
+	h.Explanation = &heartbeat.Explanation{}
+
+	_ = heartbeat.Sanitize(h, heartbeat.SanitizeConfig{})
+
+	assert.Equal(t, []heartbeat.ExplanationStep{
+		{
+			Stage:   "sanitize",
+			Message: "nothing hidden, as no sanitize pattern matched",
+		},
+	}, h.Explanation.Steps)
+}
+
+func TestSanitize_Explanation_Pseudonymize(t *testing.T) {
+	h := testHeartbeat()
+	h.Explanation = &heartbeat.Explanation{}
+
+	_ = heartbeat.Sanitize(h, heartbeat.SanitizeConfig{
+		FilePatterns:  []regex.Regex{regexp.MustCompile("^/tmp/")},
+		Pseudonymizer: pseudonymizerMock{},
+	})
+
+	assert.Equal(t, []heartbeat.ExplanationStep{
+		{
+			Stage:   "sanitize",
+			Message: `file name and meta data hidden by hide_file_names pattern "^/tmp/"`,
+		},
+		{
+			Stage:   "sanitize",
+			Message: "branch hidden together with meta data",
+		},
+		{
+			Stage:   "sanitize",
+			Message: "hidden names replaced by stable pseudonyms",
+		},
+	}, h.Explanation.Steps)
+}
+
+type pseudonymizerMock struct{}
+
+func (pseudonymizerMock) Pseudonymize(name string) string {
+	return "token(" + name + ")"
+}
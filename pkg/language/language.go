@@ -6,12 +6,18 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/wakatime/wakatime-cli/pkg/cache"
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/log"
 )
 
+// cacheBucket is the detection cache bucket of language detection results.
+const cacheBucket = "language"
+
 // Config defines language detection options.
 type Config struct {
+	// Cache is the detection cache consulted before detecting. Optional.
+	Cache *cache.Cache
 	// GuessLanguage enables detecting lexer language from file contents.
 	GuessLanguage bool
 }
@@ -26,29 +32,51 @@ func WithDetection(config Config) heartbeat.HandleOption {
 
 			for n, h := range hh {
 				if hh[n].Language != nil {
+					h.Explanation.Add("language", "language %q set by plugin", *hh[n].Language)
+
 					continue
 				}
 
-				filepath := h.Entity
+				fp := h.Entity
 
 				if h.LocalFile != "" {
-					filepath = h.LocalFile
+					fp = h.LocalFile
+				}
+
+				// temporary local files are not cached
+				cacheable := h.EntityType == heartbeat.FileType && h.LocalFile == ""
+				key := fmt.Sprintf("%s|%t", fp, config.GuessLanguage)
+
+				var cached string
+				if cacheable && config.Cache.Get(cacheBucket, key, &cached) {
+					hh[n].Language = heartbeat.PointerTo(cached)
+					h.Explanation.Add("language", "language %q found in detection cache", cached)
+
+					continue
 				}
 
-				language, err := Detect(filepath, config.GuessLanguage)
+				d, err := detect(fp, config.GuessLanguage)
 				if err != nil && hh[n].LanguageAlternate != "" {
 					hh[n].Language = heartbeat.PointerTo(hh[n].LanguageAlternate)
+					h.Explanation.Add("language", "%s, using alternate language %q", err, hh[n].LanguageAlternate)
 
 					continue
 				}
 
 				if err != nil {
 					log.Debugf("failed to detect language on file entity %q: %s", h.Entity, err)
+					h.Explanation.Add("language", "%s", err)
 
 					continue
 				}
 
-				hh[n].Language = heartbeat.PointerTo(language.String())
+				hh[n].Language = heartbeat.PointerTo(d.Language.String())
+				h.Explanation.Add("language", "language %q detected by %s", d.Language, d.Method)
+
+				// the folder is recorded, as languages of header files depend on other files in it
+				if cacheable {
+					config.Cache.Set(cacheBucket, key, d.Language.String(), fp, filepath.Dir(fp))
+				}
 			}
 
 			return next(hh)
@@ -59,28 +87,48 @@ func WithDetection(config Config) heartbeat.HandleOption {
 // Detect detects the language of a specific file. If guessLanguage is true,
 // Chroma will be used to detect a language from the file contents.
 func Detect(fp string, guessLanguage bool) (heartbeat.Language, error) {
+	d, err := detect(fp, guessLanguage)
+
+	return d.Language, err
+}
+
+// detection contains a detected language and how it was detected.
+type detection struct {
+	Language heartbeat.Language
+	// Method describes the lexer, which detected the language.
+	Method string
+}
+
+// detect detects the language of a specific file and how it was detected.
+func detect(fp string, guessLanguage bool) (detection, error) {
 	if language, ok := detectSpecialCases(fp); ok {
-		return language, nil
+		return detection{Language: language, Method: "file extension special case"}, nil
 	}
 
-	var language heartbeat.Language
+	d := detection{Language: heartbeat.LanguageUnknown}
 
 	languageChroma, weight, ok := detectChromaCustomized(fp, guessLanguage)
 	if ok {
-		language = languageChroma
+		d = detection{
+			Language: languageChroma,
+			Method:   fmt.Sprintf("chroma lexer %q with weight %.2f", languageChroma.StringChroma(), weight),
+		}
 	}
 
 	languageVim, weightVim, okVim := detectVimModeline(fp)
 	if okVim && weightVim > weight {
 		// use language from vim modeline, if weight is higher
-		language = languageVim
+		d = detection{
+			Language: languageVim,
+			Method:   fmt.Sprintf("vim modeline with weight %.2f", weightVim),
+		}
 	}
 
-	if language == heartbeat.LanguageUnknown {
-		return heartbeat.LanguageUnknown, fmt.Errorf("could not detect the language of file %q", fp)
+	if d.Language == heartbeat.LanguageUnknown {
+		return d, fmt.Errorf("could not detect the language of file %q", fp)
 	}
 
-	return language, nil
+	return d, nil
 }
 
 // detectSpecialCases detects the language by file extension for some special cases.
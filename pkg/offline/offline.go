@@ -1,18 +1,25 @@
 package offline
 
 import (
+	"bufio"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"net/http"
+	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/wakatime/wakatime-cli/pkg/api"
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/ini"
 	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/regex"
 
 	bolt "go.etcd.io/bbolt"
 )
@@ -25,6 +32,9 @@ const (
 	// maxRequeueAttempts defines the maximum number of attempts to requeue heartbeats,
 	// which could not successfully be sent to the WakaTime API.
 	maxRequeueAttempts = 3
+	// maxShrinkAttempts defines the maximum number of attempts to compact the
+	// offline db file below the size limit of the retention policy.
+	maxShrinkAttempts = 3
 	// PrintMaxDefault is the default maximum number of heartbeats to print.
 	PrintMaxDefault = 10
 	// SendLimit is the maximum number of heartbeats, which will be sent at once
@@ -43,13 +53,80 @@ func (Noop) SendHeartbeats(_ []heartbeat.Heartbeat) ([]heartbeat.Result, error)
 	return nil, api.Err{Err: errors.New("skip sending heartbeats and only save to offline db")}
 }
 
+// Retention limits the size of the offline queue. Limits are enforced
+// whenever heartbeats are pushed to the queue, by dropping the oldest
+// heartbeats first. Zero values disable the respective limit.
+type Retention struct {
+	// MaxAge is the maximum age of queued heartbeats.
+	MaxAge time.Duration
+	// MaxCount is the maximum number of queued heartbeats.
+	MaxCount int
+	// MaxSize is the maximum size in bytes of the offline db file.
+	MaxSize int64
+}
+
+// IsZero returns true if no limit is set.
+func (r Retention) IsZero() bool {
+	return r.MaxAge <= 0 && r.MaxCount <= 0 && r.MaxSize <= 0
+}
+
+// String implements fmt.Stringer interface.
+func (r Retention) String() string {
+	return fmt.Sprintf("max age: %s, max count: %d, max size: %d", r.MaxAge, r.MaxCount, r.MaxSize)
+}
+
+// QueueOption is a functional option for offline queue handling.
+type QueueOption func(*queueConfig)
+
+type queueConfig struct {
+	compactionWindow time.Duration
+	concurrency      int
+	retention        Retention
+}
+
+// WithCompaction sets the window, within which consecutive heartbeats for the
+// same entity are compacted before syncing. Zero disables compaction.
+func WithCompaction(window time.Duration) QueueOption {
+	return func(c *queueConfig) {
+		c.compactionWindow = window
+	}
+}
+
+// WithConcurrency sets the number of batches of SendLimit heartbeats, which
+// are popped from the queue per sync run. The api client is expected to send
+// these batches concurrently.
+func WithConcurrency(concurrency int) QueueOption {
+	return func(c *queueConfig) {
+		c.concurrency = concurrency
+	}
+}
+
+// WithRetention sets the retention policy enforced when pushing heartbeats.
+func WithRetention(retention Retention) QueueOption {
+	return func(c *queueConfig) {
+		c.retention = retention
+	}
+}
+
+func newQueueConfig(opts []QueueOption) queueConfig {
+	var c queueConfig
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return c
+}
+
 // WithQueue initializes and returns a heartbeat handle option, which can be
 // used in a heartbeat processing pipeline for automatic handling of failures
 // of heartbeat sending to the API. Upon inability to send due to missing or
 // failing connection to API, failed sending or errors returned by API, the
 // heartbeats will be temporarily stored in a DB and sending will be retried
 // at next usages of the wakatime cli.
-func WithQueue(filepath string) heartbeat.HandleOption {
+func WithQueue(filepath string, opts ...QueueOption) heartbeat.HandleOption {
+	config := newQueueConfig(opts)
+
 	return func(next heartbeat.Handle) heartbeat.Handle {
 		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 			log.Debugf("execute offline queue with file %s", filepath)
@@ -64,7 +141,7 @@ func WithQueue(filepath string) heartbeat.HandleOption {
 			if err != nil {
 				log.Debugf("pushing %d heartbeat(s) to queue due to error", len(hh))
 
-				requeueErr := pushHeartbeatsWithRetry(filepath, hh)
+				requeueErr := pushHeartbeatsWithRetry(filepath, hh, config.retention)
 				if requeueErr != nil {
 					return nil, fmt.Errorf(
 						"failed to push heartbeats to queue after api error: %w. error: %w",
@@ -76,7 +153,7 @@ func WithQueue(filepath string) heartbeat.HandleOption {
 				return nil, err
 			}
 
-			err = handleResults(filepath, results, hh)
+			err = handleResults(filepath, results, hh, config.retention)
 			if err != nil {
 				return nil, fmt.Errorf("failed to handle results: %s", err)
 			}
@@ -101,12 +178,12 @@ func QueueFilepath() (string, error) {
 // WithSync initializes and returns a heartbeat handle option, which
 // can be used in a heartbeat processing pipeline to pop heartbeats
 // from offline queue and send the heartbeats to WakaTime API.
-func WithSync(filepath string, syncLimit int) heartbeat.HandleOption {
+func WithSync(filepath string, syncLimit int, opts ...QueueOption) heartbeat.HandleOption {
 	return func(next heartbeat.Handle) heartbeat.Handle {
 		return func(_ []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 			log.Debugf("execute offline sync with file %s", filepath)
 
-			err := Sync(filepath, syncLimit)(next)
+			err := Sync(filepath, syncLimit, opts...)(next)
 			if err != nil {
 				return nil, fmt.Errorf("failed to sync offline heartbeats: %s", err)
 			}
@@ -117,17 +194,31 @@ func WithSync(filepath string, syncLimit int) heartbeat.HandleOption {
 }
 
 // Sync returns a function to send queued heartbeats to the WakaTime API.
-func Sync(filepath string, syncLimit int) func(next heartbeat.Handle) error {
+// With compaction enabled, the queue is compacted before sending.
+func Sync(filepath string, syncLimit int, opts ...QueueOption) func(next heartbeat.Handle) error {
+	config := newQueueConfig(opts)
+
 	return func(next heartbeat.Handle) error {
 		var (
 			alreadySent int
 			run         int
 		)
 
+		if config.compactionWindow > 0 {
+			compacted, err := Compact(filepath, config.compactionWindow)
+			if err != nil {
+				log.Warnf("failed to compact offline queue: %s", err)
+			}
+
+			log.Debugf("compacted %d redundant heartbeat(s) in offline queue", compacted)
+		}
+
 		if syncLimit == 0 {
 			syncLimit = math.MaxInt32
 		}
 
+		sendLimit := SendLimit * max(config.concurrency, 1)
+
 		for {
 			run++
 
@@ -135,9 +226,9 @@ func Sync(filepath string, syncLimit int) func(next heartbeat.Handle) error {
 				break
 			}
 
-			var num = SendLimit
+			var num = sendLimit
 
-			if alreadySent+SendLimit > syncLimit {
+			if alreadySent+sendLimit > syncLimit {
 				num = syncLimit - alreadySent
 				alreadySent += num
 			}
@@ -157,7 +248,7 @@ func Sync(filepath string, syncLimit int) func(next heartbeat.Handle) error {
 
 			results, err := next(hh)
 			if err != nil {
-				requeueErr := pushHeartbeatsWithRetry(filepath, hh)
+				requeueErr := pushHeartbeatsWithRetry(filepath, hh, config.retention)
 				if requeueErr != nil {
 					log.Warnf("failed to push heartbeats to queue after api error: %s", requeueErr)
 				}
@@ -165,7 +256,7 @@ func Sync(filepath string, syncLimit int) func(next heartbeat.Handle) error {
 				return err
 			}
 
-			err = handleResults(filepath, results, hh)
+			err = handleResults(filepath, results, hh, config.retention)
 			if err != nil {
 				return fmt.Errorf("failed to handle heartbeats api results: %s", err)
 			}
@@ -175,7 +266,7 @@ func Sync(filepath string, syncLimit int) func(next heartbeat.Handle) error {
 	}
 }
 
-func handleResults(filepath string, results []heartbeat.Result, hh []heartbeat.Heartbeat) error {
+func handleResults(filepath string, results []heartbeat.Result, hh []heartbeat.Heartbeat, retention Retention) error {
 	var (
 		err               error
 		withInvalidStatus []heartbeat.Heartbeat
@@ -212,7 +303,7 @@ func handleResults(filepath string, results []heartbeat.Result, hh []heartbeat.H
 	if len(withInvalidStatus) > 0 {
 		log.Debugf("pushing %d heartbeat(s) with invalid result to queue", len(withInvalidStatus))
 
-		err = pushHeartbeatsWithRetry(filepath, withInvalidStatus)
+		err = pushHeartbeatsWithRetry(filepath, withInvalidStatus, retention)
 		if err != nil {
 			log.Warnf("failed to push heartbeats with invalid status to queue: %s", err)
 		}
@@ -225,7 +316,7 @@ func handleResults(filepath string, results []heartbeat.Result, hh []heartbeat.H
 
 		start := len(hh) - leftovers
 
-		err = pushHeartbeatsWithRetry(filepath, hh[start:])
+		err = pushHeartbeatsWithRetry(filepath, hh[start:], retention)
 		if err != nil {
 			log.Warnf("failed to push leftover heartbeats to queue: %s", err)
 		}
@@ -266,7 +357,7 @@ func popHeartbeats(filepath string, limit int) ([]heartbeat.Heartbeat, error) {
 	return queued, nil
 }
 
-func pushHeartbeatsWithRetry(filepath string, hh []heartbeat.Heartbeat) error {
+func pushHeartbeatsWithRetry(filepath string, hh []heartbeat.Heartbeat, retention Retention) error {
 	var (
 		count int
 		err   error
@@ -287,7 +378,7 @@ func pushHeartbeatsWithRetry(filepath string, hh []heartbeat.Heartbeat) error {
 			)
 		}
 
-		err = pushHeartbeats(filepath, hh)
+		err = pushHeartbeats(filepath, hh, retention)
 		if err != nil {
 			count++
 
@@ -304,7 +395,23 @@ func pushHeartbeatsWithRetry(filepath string, hh []heartbeat.Heartbeat) error {
 	return nil
 }
 
-func pushHeartbeats(filepath string, hh []heartbeat.Heartbeat) error {
+func pushHeartbeats(filepath string, hh []heartbeat.Heartbeat, retention Retention) error {
+	if err := pushAndTrimHeartbeats(filepath, hh, retention); err != nil {
+		return err
+	}
+
+	if retention.MaxSize <= 0 {
+		return nil
+	}
+
+	if err := shrinkDB(filepath, retention.MaxSize); err != nil {
+		return fmt.Errorf("failed to shrink offline db: %s", err)
+	}
+
+	return nil
+}
+
+func pushAndTrimHeartbeats(filepath string, hh []heartbeat.Heartbeat, retention Retention) error {
 	db, close, err := openDB(filepath)
 	if err != nil {
 		return err
@@ -324,6 +431,17 @@ func pushHeartbeats(filepath string, hh []heartbeat.Heartbeat) error {
 		return fmt.Errorf("failed to push heartbeat(s) to queue: %s", err)
 	}
 
+	if !retention.IsZero() {
+		dropped, err := queue.Trim(retention, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to enforce retention policy: %s", err)
+		}
+
+		if dropped > 0 {
+			log.Warnf("dropped %d heartbeat(s) from offline queue due to retention policy (%s)", dropped, retention)
+		}
+	}
+
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit db transaction: %s", err)
 	}
@@ -331,6 +449,88 @@ func pushHeartbeats(filepath string, hh []heartbeat.Heartbeat) error {
 	return nil
 }
 
+// shrinkDB keeps the offline db file at or below maxSize bytes. Bolt does not
+// return the pages of deleted heartbeats to the file system, so the db is
+// rewritten into a compacted file instead. If page overhead still keeps the
+// compacted file above maxSize, the oldest heartbeats are dropped by the
+// excess size and the db is compacted again.
+func shrinkDB(filepath string, maxSize int64) error {
+	budget := maxSize
+
+	for attempt := 0; ; attempt++ {
+		info, err := os.Stat(filepath)
+		if err != nil {
+			return fmt.Errorf("failed to stat db file: %s", err)
+		}
+
+		if info.Size() <= maxSize {
+			return nil
+		}
+
+		if attempt == maxShrinkAttempts {
+			log.Warnf("offline db size of %d bytes exceeds limit of %d bytes", info.Size(), maxSize)
+
+			return nil
+		}
+
+		if attempt > 0 {
+			budget = max(budget-(info.Size()-maxSize), 1)
+
+			if err := pushAndTrimHeartbeats(filepath, nil, Retention{MaxSize: budget}); err != nil {
+				return err
+			}
+		}
+
+		if err := compactDB(filepath); err != nil {
+			return err
+		}
+	}
+}
+
+// compactDB copies the offline db into a new file, which leaves out the free
+// pages, and replaces the db file with it.
+func compactDB(filepath string) error {
+	tmpFilepath := filepath + ".compact"
+
+	if err := os.Remove(tmpFilepath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stale compacted db file: %s", err)
+	}
+
+	src, closeSrc, err := openDB(filepath)
+	if err != nil {
+		return err
+	}
+
+	defer closeSrc()
+
+	dst, closeDst, err := openDB(tmpFilepath)
+	if err != nil {
+		return err
+	}
+
+	defer closeDst()
+
+	// grow the file by the pages written only, instead of preallocating
+	dst.AllocSize = 0
+
+	if err := bolt.Compact(dst, src, 0); err != nil {
+		closeDst()
+
+		_ = os.Remove(tmpFilepath)
+
+		return fmt.Errorf("failed to compact db file: %s", err)
+	}
+
+	closeDst()
+	closeSrc()
+
+	if err := os.Rename(tmpFilepath, filepath); err != nil {
+		return fmt.Errorf("failed to replace db file with compacted db file: %s", err)
+	}
+
+	return nil
+}
+
 // CountHeartbeats returns the total number of heartbeats in the offline db.
 func CountHeartbeats(filepath string) (int, error) {
 	db, close, err := openDB(filepath)
@@ -395,6 +595,320 @@ func ReadHeartbeats(filepath string, limit int) ([]heartbeat.Heartbeat, error) {
 	return hh, nil
 }
 
+// Filter selects heartbeats in the offline db. Unset fields match all heartbeats.
+type Filter struct {
+	// Entity optionally matches the heartbeat entity.
+	Entity regex.Regex
+	// Project optionally matches the heartbeat project exactly.
+	Project string
+	// Start optionally excludes heartbeats before this time.
+	Start time.Time
+	// End optionally excludes heartbeats at or after this time.
+	End time.Time
+}
+
+// Match returns true if the passed in heartbeat matches all set filter fields.
+func (f Filter) Match(h heartbeat.Heartbeat) bool {
+	if f.Entity != nil && !f.Entity.MatchString(h.Entity) {
+		return false
+	}
+
+	if f.Project != "" && (h.Project == nil || *h.Project != f.Project) {
+		return false
+	}
+
+	t := time.Unix(0, int64(h.Time*1000000000))
+
+	if !f.Start.IsZero() && t.Before(f.Start) {
+		return false
+	}
+
+	if !f.End.IsZero() && !t.Before(f.End) {
+		return false
+	}
+
+	return true
+}
+
+// QueuedHeartbeat is a heartbeat in the offline db together with its db key.
+type QueuedHeartbeat struct {
+	ID        string              `json:"id"`
+	Heartbeat heartbeat.Heartbeat `json:"heartbeat"`
+}
+
+// ListHeartbeats returns all heartbeats in the offline db matching the passed
+// in filter. Records which cannot be parsed are skipped, logging their id, so
+// they can be deleted via DeleteHeartbeats.
+func ListHeartbeats(filepath string, filter Filter) ([]QueuedHeartbeat, error) {
+	db, close, err := openDB(filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer close()
+
+	tx, err := db.Begin(true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start db transaction: %s", err)
+	}
+
+	defer func() {
+		err := tx.Rollback()
+		if err != nil {
+			log.Warnf("failed to rollback transaction: %s", err)
+		}
+	}()
+
+	queue := NewQueue(tx)
+
+	queued, err := queue.ReadAll(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read offline heartbeats: %s", err)
+	}
+
+	return queued, nil
+}
+
+// DeleteHeartbeats removes the heartbeats with the passed in ids from the
+// offline db. Returns the number of deleted heartbeats.
+func DeleteHeartbeats(filepath string, ids []string) (int, error) {
+	db, close, err := openDB(filepath)
+	if err != nil {
+		return 0, err
+	}
+
+	defer close()
+
+	tx, err := db.Begin(true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start db transaction: %s", err)
+	}
+
+	queue := NewQueue(tx)
+
+	deleted, err := queue.DeleteMany(ids)
+	if err != nil {
+		errrb := tx.Rollback()
+		if errrb != nil {
+			log.Errorf("failed to rollback transaction: %s", errrb)
+		}
+
+		return 0, fmt.Errorf("failed to delete heartbeat(s) from queue: %s", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit db transaction: %s", err)
+	}
+
+	return deleted, nil
+}
+
+// ExportHeartbeats writes all heartbeats in the offline db matching the
+// passed in filter to w, one json object per line. Returns the number of
+// exported heartbeats.
+func ExportHeartbeats(filepath string, w io.Writer, filter Filter) (int, error) {
+	queued, err := ListHeartbeats(filepath, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	for _, q := range queued {
+		if err := encoder.Encode(newRecord(q.Heartbeat)); err != nil {
+			return 0, fmt.Errorf("failed to write heartbeat with id %q: %s", q.ID, err)
+		}
+	}
+
+	return len(queued), nil
+}
+
+// ImportHeartbeats reads heartbeats from r, one json object per line, and
+// stores them in the offline db. Nothing is imported if any line cannot be
+// parsed. Returns the number of imported heartbeats.
+func ImportHeartbeats(filepath string, r io.Reader) (int, error) {
+	var hh []heartbeat.Heartbeat
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	var line int
+
+	for scanner.Scan() {
+		line++
+
+		data := strings.TrimSpace(scanner.Text())
+		if data == "" {
+			continue
+		}
+
+		h, err := decodeHeartbeat([]byte(data))
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse heartbeat on line %d: %s", line, err)
+		}
+
+		hh = append(hh, h)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read heartbeats: %s", err)
+	}
+
+	if len(hh) == 0 {
+		return 0, nil
+	}
+
+	if err := pushHeartbeats(filepath, hh, Retention{}); err != nil {
+		return 0, err
+	}
+
+	return len(hh), nil
+}
+
+// Compact removes redundant heartbeats from the offline db. Within a run of
+// consecutive heartbeats with the same entity, project, branch, category,
+// language and user agent, heartbeats are removed as long as the remaining
+// ones are at most window apart. The first and last heartbeat of every run,
+// all write heartbeats and heartbeats with line changes are kept, so computed
+// durations and line changes do not change.
+// Returns the number of removed heartbeats.
+func Compact(filepath string, window time.Duration) (int, error) {
+	db, close, err := openDB(filepath)
+	if err != nil {
+		return 0, err
+	}
+
+	defer close()
+
+	tx, err := db.Begin(true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start db transaction: %s", err)
+	}
+
+	queue := NewQueue(tx)
+
+	queued, err := queue.ReadAll(Filter{})
+	if err != nil {
+		_ = tx.Rollback()
+
+		return 0, fmt.Errorf("failed to read offline heartbeats: %s", err)
+	}
+
+	ids := redundant(queued, window)
+
+	deleted, err := queue.DeleteMany(ids)
+	if err != nil {
+		errrb := tx.Rollback()
+		if errrb != nil {
+			log.Errorf("failed to rollback transaction: %s", errrb)
+		}
+
+		return 0, fmt.Errorf("failed to delete heartbeat(s) from queue: %s", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit db transaction: %s", err)
+	}
+
+	return deleted, nil
+}
+
+// redundant returns the ids of heartbeats, which can be removed without
+// changing durations. See Compact.
+func redundant(queued []QueuedHeartbeat, window time.Duration) []string {
+	sort.SliceStable(queued, func(i, j int) bool {
+		return queued[i].Heartbeat.Time < queued[j].Heartbeat.Time
+	})
+
+	var ids []string
+
+	for start := 0; start < len(queued); {
+		end := start
+
+		key := compactionKey(queued[start].Heartbeat)
+		for end+1 < len(queued) && compactionKey(queued[end+1].Heartbeat) == key {
+			end++
+		}
+
+		lastKept := queued[start].Heartbeat.Time
+
+		for i := start + 1; i < end; i++ {
+			h := queued[i].Heartbeat
+
+			if (h.IsWrite != nil && *h.IsWrite) || hasLineChanges(h) || queued[i+1].Heartbeat.Time-lastKept > window.Seconds() {
+				lastKept = h.Time
+				continue
+			}
+
+			ids = append(ids, queued[i].ID)
+		}
+
+		start = end + 1
+	}
+
+	return ids
+}
+
+// hasLineChanges returns true, if the heartbeat carries line changes since
+// the previous heartbeat, which would be lost by removing it.
+func hasLineChanges(h heartbeat.Heartbeat) bool {
+	nonZero := func(i *int) bool {
+		return i != nil && *i != 0
+	}
+
+	return nonZero(h.LineAdditions) || nonZero(h.LineDeletions) ||
+		nonZero(h.AILineChanges) || nonZero(h.HumanLineChanges)
+}
+
+func compactionKey(h heartbeat.Heartbeat) string {
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+
+		return *s
+	}
+
+	return strings.Join([]string{
+		h.Entity,
+		h.EntityType.String(),
+		h.Category.String(),
+		deref(h.Project),
+		deref(h.Branch),
+		deref(h.Language),
+		h.UserAgent,
+	}, "\x00")
+}
+
+// record is the encoding of a heartbeat in the offline db and in exported
+// heartbeats. Besides the fields of api requests, it persists the hostname
+// queued heartbeats are sent with.
+type record struct {
+	heartbeat.Heartbeat
+	Hostname string `json:"hostname,omitempty"`
+}
+
+func newRecord(h heartbeat.Heartbeat) record {
+	return record{
+		Heartbeat: h,
+		Hostname:  h.Hostname,
+	}
+}
+
+// decodeHeartbeat decodes a heartbeat from its offline db record.
+func decodeHeartbeat(data []byte) (heartbeat.Heartbeat, error) {
+	var r record
+
+	if err := json.Unmarshal(data, &r); err != nil {
+		return heartbeat.Heartbeat{}, err
+	}
+
+	r.Heartbeat.Hostname = r.Hostname
+
+	return r.Heartbeat, nil
+}
+
 // openDB opens a connection to the offline db.
 // It returns the pointer to bolt.DB, a function to close the connection and an error.
 // Although named parameters should be avoided, this func uses them to access inside the deferred function and set an error.
@@ -470,9 +984,7 @@ func (q *Queue) PopMany(limit int) ([]heartbeat.Heartbeat, error) {
 			break
 		}
 
-		var h heartbeat.Heartbeat
-
-		err := json.Unmarshal(value, &h)
+		h, err := decodeHeartbeat(value)
 		if err != nil {
 			return nil, fmt.Errorf("failed to json unmarshal heartbeat data: %s", err)
 		}
@@ -498,7 +1010,7 @@ func (q *Queue) PushMany(hh []heartbeat.Heartbeat) error {
 	}
 
 	for _, h := range hh {
-		data, err := json.Marshal(h)
+		data, err := json.Marshal(newRecord(h))
 		if err != nil {
 			return fmt.Errorf("failed to json marshal heartbeat: %s", err)
 		}
@@ -529,9 +1041,7 @@ func (q *Queue) ReadMany(limit int) ([]heartbeat.Heartbeat, error) {
 			break
 		}
 
-		var h heartbeat.Heartbeat
-
-		err := json.Unmarshal(value, &h)
+		h, err := decodeHeartbeat(value)
 		if err != nil {
 			return nil, fmt.Errorf("failed to json unmarshal heartbeat data: %s", err)
 		}
@@ -541,3 +1051,147 @@ func (q *Queue) ReadMany(limit int) ([]heartbeat.Heartbeat, error) {
 
 	return heartbeats, nil
 }
+
+// ReadAll reads all heartbeats matching the passed in filter from db without
+// deleting them. Records which cannot be parsed are skipped.
+func (q *Queue) ReadAll(filter Filter) ([]QueuedHeartbeat, error) {
+	b, err := q.tx.CreateBucketIfNotExists([]byte(q.Bucket))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create/load bucket: %s", err)
+	}
+
+	var queued = make([]QueuedHeartbeat, 0)
+
+	c := b.Cursor()
+
+	for key, value := c.First(); key != nil; key, value = c.Next() {
+		h, err := decodeHeartbeat(value)
+		if err != nil {
+			log.Warnf("skipping heartbeat with id %q, failed to json unmarshal heartbeat data: %s", string(key), err)
+			continue
+		}
+
+		if !filter.Match(h) {
+			continue
+		}
+
+		queued = append(queued, QueuedHeartbeat{
+			ID:        string(key),
+			Heartbeat: h,
+		})
+	}
+
+	return queued, nil
+}
+
+// DeleteMany deletes the heartbeats with the specified ids from db. Returns
+// the number of deleted heartbeats.
+func (q *Queue) DeleteMany(ids []string) (int, error) {
+	b, err := q.tx.CreateBucketIfNotExists([]byte(q.Bucket))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create/load bucket: %s", err)
+	}
+
+	var deleted int
+
+	for _, id := range ids {
+		if b.Get([]byte(id)) == nil {
+			log.Debugf("heartbeat with id %q not found", id)
+			continue
+		}
+
+		if err := b.Delete([]byte(id)); err != nil {
+			return 0, fmt.Errorf("failed to delete key %q: %s", id, err)
+		}
+
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// Trim drops the oldest heartbeats from db until the passed in retention
+// policy is met. Returns the number of dropped heartbeats. As the size of
+// the db file is only known after committing, MaxSize is applied to the
+// stored heartbeat data here.
+func (q *Queue) Trim(retention Retention, now time.Time) (int, error) {
+	b, err := q.tx.CreateBucketIfNotExists([]byte(q.Bucket))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create/load bucket: %s", err)
+	}
+
+	type record struct {
+		key  []byte
+		size int64
+		time float64
+	}
+
+	var (
+		records []record
+		count   int
+		size    int64
+	)
+
+	c := b.Cursor()
+
+	for key, value := c.First(); key != nil; key, value = c.Next() {
+		r := record{
+			key:  append([]byte{}, key...),
+			size: int64(len(key) + len(value)),
+			time: recordTime(key, value),
+		}
+
+		records = append(records, r)
+		count++
+		size += r.size
+	}
+
+	// oldest first, keeping records without parsable time last
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].time < records[j].time
+	})
+
+	var minTime float64
+	if retention.MaxAge > 0 {
+		minTime = float64(now.Add(-retention.MaxAge).UnixNano()) / 1000000000
+	}
+
+	var dropped int
+
+	for _, r := range records {
+		expired := retention.MaxAge > 0 && r.time < minTime
+		tooMany := retention.MaxCount > 0 && count > retention.MaxCount
+		tooLarge := retention.MaxSize > 0 && size > retention.MaxSize
+
+		if !expired && !tooMany && !tooLarge {
+			break
+		}
+
+		if err := b.Delete(r.key); err != nil {
+			return 0, fmt.Errorf("failed to delete key %q: %s", string(r.key), err)
+		}
+
+		count--
+		size -= r.size
+		dropped++
+	}
+
+	return dropped, nil
+}
+
+// recordTime returns the heartbeat time of a db record. Records, which cannot
+// be parsed, are treated as most recent, so they are dropped last.
+func recordTime(key, value []byte) float64 {
+	if prefix, _, ok := strings.Cut(string(key), "-"); ok {
+		if t, err := strconv.ParseFloat(prefix, 64); err == nil {
+			return t
+		}
+	}
+
+	h, err := decodeHeartbeat(value)
+	if err != nil {
+		return math.MaxFloat64
+	}
+
+	return h.Time
+}
@@ -1,6 +1,7 @@
 package offline_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,11 +9,13 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/offline"
+	"github.com/wakatime/wakatime-cli/pkg/regex"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -1026,6 +1029,478 @@ func TestReadHeartbeats_Empty(t *testing.T) {
 	assert.Len(t, hh, 0)
 }
 
+func TestListHeartbeats(t *testing.T) {
+	fp := setupQueue(t, testHeartbeats())
+
+	tests := map[string]struct {
+		Filter   offline.Filter
+		Expected []string
+	}{
+		"no filter": {
+			Expected: []string{"/tmp/main.go", "/tmp/main.py", "/tmp/main.js"},
+		},
+		"project": {
+			Filter:   offline.Filter{Project: "wakatime"},
+			Expected: []string{"/tmp/main.py", "/tmp/main.js"},
+		},
+		"entity": {
+			Filter:   offline.Filter{Entity: regex.MustCompile(`\.(go|js)$`)},
+			Expected: []string{"/tmp/main.go", "/tmp/main.js"},
+		},
+		"time range": {
+			Filter: offline.Filter{
+				Start: time.Unix(1592868386, 0),
+				End:   time.Unix(1592868390, 0),
+			},
+			Expected: []string{"/tmp/main.py"},
+		},
+		"no match": {
+			Filter:   offline.Filter{Project: "unknown"},
+			Expected: []string{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			queued, err := offline.ListHeartbeats(fp, test.Filter)
+			require.NoError(t, err)
+
+			entities := []string{}
+			for _, q := range queued {
+				assert.Equal(t, q.Heartbeat.ID(), q.ID)
+
+				entities = append(entities, q.Heartbeat.Entity)
+			}
+
+			assert.Equal(t, test.Expected, entities)
+		})
+	}
+}
+
+func TestListHeartbeats_SkipCorrupted(t *testing.T) {
+	fp := setupQueue(t, testHeartbeats()[:1])
+
+	db, err := bolt.Open(fp, 0600, nil)
+	require.NoError(t, err)
+
+	insertHeartbeatRecord(t, db, "heartbeats", heartbeatRecord{
+		ID:        "corrupted",
+		Heartbeat: "{invalid",
+	})
+
+	db.Close()
+
+	queued, err := offline.ListHeartbeats(fp, offline.Filter{})
+	require.NoError(t, err)
+
+	require.Len(t, queued, 1)
+	assert.Equal(t, "/tmp/main.go", queued[0].Heartbeat.Entity)
+
+	deleted, err := offline.DeleteHeartbeats(fp, []string{"corrupted"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, deleted)
+
+	count, err := offline.CountHeartbeats(fp)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, count)
+}
+
+func TestDeleteHeartbeats(t *testing.T) {
+	hh := testHeartbeats()
+
+	fp := setupQueue(t, hh)
+
+	deleted, err := offline.DeleteHeartbeats(fp, []string{hh[0].ID(), hh[2].ID(), "non-existing"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, deleted)
+
+	queued, err := offline.ListHeartbeats(fp, offline.Filter{})
+	require.NoError(t, err)
+
+	require.Len(t, queued, 1)
+	assert.Equal(t, hh[1].ID(), queued[0].ID)
+}
+
+func TestExportHeartbeats(t *testing.T) {
+	fp := setupQueue(t, testHeartbeats())
+
+	var buf bytes.Buffer
+
+	count, err := offline.ExportHeartbeats(fp, &buf, offline.Filter{Project: "wakatime"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, count)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var h heartbeat.Heartbeat
+
+	err = json.Unmarshal([]byte(lines[0]), &h)
+	require.NoError(t, err)
+
+	assert.Equal(t, testHeartbeats()[1], h)
+}
+
+func TestImportHeartbeats(t *testing.T) {
+	var buf bytes.Buffer
+
+	count, err := offline.ExportHeartbeats(setupQueue(t, testHeartbeats()), &buf, offline.Filter{})
+	require.NoError(t, err)
+
+	require.Equal(t, 3, count)
+
+	fp := filepath.Join(t.TempDir(), "offline.bdb")
+
+	count, err = offline.ImportHeartbeats(fp, strings.NewReader(buf.String()+"\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, count)
+
+	hh, err := offline.ReadHeartbeats(fp, offline.PrintMaxDefault)
+	require.NoError(t, err)
+
+	assert.Equal(t, testHeartbeats(), hh)
+}
+
+func TestImportHeartbeats_InvalidLine(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "offline.bdb")
+
+	data, err := json.Marshal(testHeartbeats()[0])
+	require.NoError(t, err)
+
+	_, err = offline.ImportHeartbeats(fp, strings.NewReader(string(data)+"\n{invalid\n"))
+	require.Error(t, err)
+
+	assert.Contains(t, err.Error(), "failed to parse heartbeat on line 2")
+
+	count, err := offline.CountHeartbeats(fp)
+	require.NoError(t, err)
+
+	assert.Zero(t, count)
+}
+
+func TestWithQueue_Retention(t *testing.T) {
+	fp := setupQueue(t, testHeartbeats())
+
+	recent := testHeartbeats()[0]
+	recent.Time = float64(time.Now().Unix())
+
+	opt := offline.WithQueue(fp, offline.WithRetention(offline.Retention{
+		MaxAge: 24 * time.Hour,
+	}))
+
+	handle := opt(func(_ []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		return nil, errors.New("error")
+	})
+
+	_, err := handle([]heartbeat.Heartbeat{recent})
+	require.Error(t, err)
+
+	queued, err := offline.ListHeartbeats(fp, offline.Filter{})
+	require.NoError(t, err)
+
+	require.Len(t, queued, 1)
+	assert.Equal(t, recent, queued[0].Heartbeat)
+}
+
+func TestWithQueue_Retention_MaxSize(t *testing.T) {
+	var hh []heartbeat.Heartbeat
+
+	for i := 0; i < 2000; i++ {
+		h := testHeartbeats()[0]
+		h.Time = 1592868000 + float64(i)
+
+		hh = append(hh, h)
+	}
+
+	fp := setupQueue(t, hh)
+
+	info, err := os.Stat(fp)
+	require.NoError(t, err)
+
+	maxSize := int64(64 * 1024)
+
+	require.Greater(t, info.Size(), maxSize)
+
+	recent := testHeartbeats()[0]
+	recent.Time = 1592868000 + 2000
+
+	opt := offline.WithQueue(fp, offline.WithRetention(offline.Retention{
+		MaxSize: maxSize,
+	}))
+
+	handle := opt(func(_ []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		return nil, errors.New("error")
+	})
+
+	_, err = handle([]heartbeat.Heartbeat{recent})
+	require.Error(t, err)
+
+	info, err = os.Stat(fp)
+	require.NoError(t, err)
+
+	assert.LessOrEqual(t, info.Size(), maxSize)
+
+	queued, err := offline.ListHeartbeats(fp, offline.Filter{})
+	require.NoError(t, err)
+
+	require.NotEmpty(t, queued)
+	assert.Less(t, len(queued), len(hh))
+	assert.Equal(t, recent, queued[len(queued)-1].Heartbeat)
+
+	_, err = os.Stat(fp + ".compact")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestQueue_Trim(t *testing.T) {
+	hh := testHeartbeats()
+
+	now := time.Unix(int64(hh[2].Time), 0)
+
+	tests := map[string]struct {
+		Retention offline.Retention
+		Expected  []string
+	}{
+		"max age": {
+			Retention: offline.Retention{MaxAge: 20 * time.Second},
+			Expected:  []string{hh[1].ID(), hh[2].ID()},
+		},
+		"max count": {
+			Retention: offline.Retention{MaxCount: 1},
+			Expected:  []string{hh[2].ID()},
+		},
+		"max size": {
+			Retention: offline.Retention{MaxSize: 1},
+			Expected:  []string{},
+		},
+		"within limits": {
+			Retention: offline.Retention{MaxAge: time.Hour, MaxCount: 3, MaxSize: 1024 * 1024},
+			Expected:  []string{hh[0].ID(), hh[1].ID(), hh[2].ID()},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, cleanup := initDB(t)
+			defer cleanup()
+
+			tx, err := db.Begin(true)
+			require.NoError(t, err)
+
+			q := offline.NewQueue(tx)
+
+			err = q.PushMany(hh)
+			require.NoError(t, err)
+
+			dropped, err := q.Trim(test.Retention, now)
+			require.NoError(t, err)
+
+			assert.Equal(t, 3-len(test.Expected), dropped)
+
+			queued, err := q.ReadAll(offline.Filter{})
+			require.NoError(t, err)
+
+			ids := []string{}
+			for _, q := range queued {
+				ids = append(ids, q.ID)
+			}
+
+			assert.Equal(t, test.Expected, ids)
+
+			err = tx.Rollback()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCompact(t *testing.T) {
+	var hh []heartbeat.Heartbeat
+
+	// heartbeats every 30 seconds for main.go, interrupted by main.py
+	for i, entity := range []string{
+		"/tmp/main.go", "/tmp/main.go", "/tmp/main.go", "/tmp/main.go", "/tmp/main.go",
+		"/tmp/main.py",
+		"/tmp/main.go", "/tmp/main.go",
+	} {
+		h := testHeartbeats()[0]
+		h.Entity = entity
+		h.IsWrite = heartbeat.PointerTo(false)
+		h.Time = 1592868000 + float64(i*30)
+
+		hh = append(hh, h)
+	}
+
+	// writes are never compacted
+	hh[3].IsWrite = heartbeat.PointerTo(true)
+
+	fp := setupQueue(t, hh)
+
+	compacted, err := offline.Compact(fp, time.Minute)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, compacted)
+
+	queued, err := offline.ListHeartbeats(fp, offline.Filter{})
+	require.NoError(t, err)
+
+	var times []float64
+	for _, q := range queued {
+		times = append(times, q.Heartbeat.Time-1592868000)
+	}
+
+	assert.Equal(t, []float64{0, 60, 90, 120, 150, 180, 210}, times)
+}
+
+func TestCompact_LineChanges(t *testing.T) {
+	var hh []heartbeat.Heartbeat
+
+	for i := 0; i < 5; i++ {
+		h := testHeartbeats()[0]
+		h.IsWrite = heartbeat.PointerTo(false)
+		h.LineAdditions = heartbeat.PointerTo(0)
+		h.LineDeletions = heartbeat.PointerTo(0)
+		h.Time = 1592868000 + float64(i*30)
+
+		hh = append(hh, h)
+	}
+
+	// line changes are never compacted
+	hh[1].LineAdditions = heartbeat.PointerTo(3)
+	hh[3].LineDeletions = heartbeat.PointerTo(1)
+
+	fp := setupQueue(t, hh)
+
+	compacted, err := offline.Compact(fp, time.Minute)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, compacted)
+
+	queued, err := offline.ListHeartbeats(fp, offline.Filter{})
+	require.NoError(t, err)
+
+	var (
+		times     []float64
+		additions int
+		deletions int
+	)
+
+	for _, q := range queued {
+		times = append(times, q.Heartbeat.Time-1592868000)
+		additions += *q.Heartbeat.LineAdditions
+		deletions += *q.Heartbeat.LineDeletions
+	}
+
+	assert.Equal(t, []float64{0, 30, 90, 120}, times)
+	assert.Equal(t, 3, additions)
+	assert.Equal(t, 1, deletions)
+}
+
+func TestCompact_AIAndHumanLineChanges(t *testing.T) {
+	var hh []heartbeat.Heartbeat
+
+	for i := 0; i < 5; i++ {
+		h := testHeartbeats()[0]
+		h.IsWrite = heartbeat.PointerTo(false)
+		h.Time = 1592868000 + float64(i*30)
+
+		hh = append(hh, h)
+	}
+
+	hh[1].AILineChanges = heartbeat.PointerTo(12)
+	hh[3].HumanLineChanges = heartbeat.PointerTo(2)
+
+	fp := setupQueue(t, hh)
+
+	compacted, err := offline.Compact(fp, time.Minute)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, compacted)
+
+	queued, err := offline.ListHeartbeats(fp, offline.Filter{})
+	require.NoError(t, err)
+
+	require.Len(t, queued, 4)
+	assert.Equal(t, 12, *queued[1].Heartbeat.AILineChanges)
+	assert.Equal(t, 2, *queued[2].Heartbeat.HumanLineChanges)
+}
+
+func TestSync_Compaction(t *testing.T) {
+	var hh []heartbeat.Heartbeat
+
+	for i := 0; i < 10; i++ {
+		h := testHeartbeats()[1]
+		h.Time = 1592868000 + float64(i*10)
+
+		hh = append(hh, h)
+	}
+
+	fp := setupQueue(t, hh)
+
+	syncFn := offline.Sync(fp, 0, offline.WithCompaction(2*time.Minute))
+
+	err := syncFn(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		require.Len(t, hh, 2)
+		assert.Equal(t, float64(1592868000), hh[0].Time)
+		assert.Equal(t, float64(1592868090), hh[1].Time)
+
+		return []heartbeat.Result{
+			{Status: http.StatusCreated, Heartbeat: hh[0]},
+			{Status: http.StatusCreated, Heartbeat: hh[1]},
+		}, nil
+	})
+	require.NoError(t, err)
+
+	count, err := offline.CountHeartbeats(fp)
+	require.NoError(t, err)
+
+	assert.Zero(t, count)
+}
+
+func TestSync_Concurrency(t *testing.T) {
+	var hh []heartbeat.Heartbeat
+
+	for i := 0; i < 60; i++ {
+		h := testHeartbeats()[0]
+		h.Time = 1592868000 + float64(i)
+
+		hh = append(hh, h)
+	}
+
+	fp := setupQueue(t, hh)
+
+	syncFn := offline.Sync(fp, 0, offline.WithConcurrency(2))
+
+	var sizes []int
+
+	err := syncFn(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		sizes = append(sizes, len(hh))
+
+		results := make([]heartbeat.Result, len(hh))
+		for i, h := range hh {
+			results[i] = heartbeat.Result{Status: http.StatusCreated, Heartbeat: h}
+		}
+
+		// a failed batch is reported by an empty result and must be requeued
+		if len(sizes) == 1 {
+			results[0] = heartbeat.Result{Errors: []string{"failed"}}
+		}
+
+		return results, nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{50, 11}, sizes)
+
+	count, err := offline.CountHeartbeats(fp)
+	require.NoError(t, err)
+
+	assert.Zero(t, count)
+}
+
 func TestQueue_Count(t *testing.T) {
 	// setup
 	db, cleanup := initDB(t)
@@ -1230,6 +1705,34 @@ func TestQueue_PushMany(t *testing.T) {
 	assert.JSONEq(t, string(dataJs), stored[2].Heartbeat)
 }
 
+func TestQueue_PushMany_Hostname(t *testing.T) {
+	db, cleanup := initDB(t)
+	defer cleanup()
+
+	h := testHeartbeats()[0]
+	h.Hostname = "my-computer"
+
+	tx, err := db.Begin(true)
+	require.NoError(t, err)
+
+	q := offline.NewQueue(tx)
+
+	err = q.PushMany([]heartbeat.Heartbeat{h})
+	require.NoError(t, err)
+
+	value := tx.Bucket([]byte("heartbeats")).Get([]byte(h.ID()))
+	assert.Contains(t, string(value), `"hostname":"my-computer"`)
+
+	hh, err := q.PopMany(1)
+	require.NoError(t, err)
+
+	require.Len(t, hh, 1)
+	assert.Equal(t, "my-computer", hh[0].Hostname)
+
+	err = tx.Rollback()
+	require.NoError(t, err)
+}
+
 func TestQueue_ReadMany(t *testing.T) {
 	// setup
 	f, err := os.CreateTemp(t.TempDir(), "")
@@ -1353,6 +1856,27 @@ func initDB(t *testing.T) (*bolt.DB, func()) {
 	}
 }
 
+func setupQueue(t *testing.T, hh []heartbeat.Heartbeat) string {
+	fp := filepath.Join(t.TempDir(), "offline.bdb")
+
+	db, err := bolt.Open(fp, 0600, nil)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	for _, h := range hh {
+		data, err := json.Marshal(h)
+		require.NoError(t, err)
+
+		insertHeartbeatRecord(t, db, "heartbeats", heartbeatRecord{
+			ID:        h.ID(),
+			Heartbeat: string(data),
+		})
+	}
+
+	return fp
+}
+
 func testHeartbeats() []heartbeat.Heartbeat {
 	return []heartbeat.Heartbeat{
 		{
@@ -0,0 +1,112 @@
+package project
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// maxBzrBranchConfLines is the maximum number of lines read from the branch.conf file.
+const maxBzrBranchConfLines = 100
+
+// Bazaar contains bazaar data.
+type Bazaar struct {
+	// Filepath contains the entity path.
+	Filepath string
+}
+
+// Detect gets information about the bazaar project for a given file.
+// The branch is the branch nickname. Branches inside a shared repository
+// use the repository folder as project.
+func (b Bazaar) Detect() (Result, bool, error) {
+	var fp string
+
+	// Take only the directory
+	if fileOrDirExists(b.Filepath) {
+		fp = filepath.Dir(b.Filepath)
+	}
+
+	// Find for .bzr/branch folder
+	branchDirectory, ok := FindFileOrDirectory(fp, filepath.Join(".bzr", "branch"))
+	if !ok {
+		return Result{}, false, nil
+	}
+
+	folder := filepath.Dir(filepath.Dir(branchDirectory))
+
+	branch, err := findBzrNickname(branchDirectory)
+	if err != nil {
+		log.Errorf(
+			"error finding for branch nickname from %q: %s",
+			branchDirectory,
+			err,
+		)
+	}
+
+	if branch == "" {
+		branch = filepath.Base(folder)
+	}
+
+	project := filepath.Base(folder)
+
+	// standalone branches contain their own repository
+	if !fileOrDirExists(filepath.Join(folder, ".bzr", "repository")) {
+		if repo, ok := FindFileOrDirectory(filepath.Dir(folder), filepath.Join(".bzr", "repository")); ok {
+			project = filepath.Base(filepath.Dir(filepath.Dir(repo)))
+		}
+	}
+
+	return Result{
+		Project: project,
+		Branch:  branch,
+		Folder:  folder,
+	}, true, nil
+}
+
+// findBzrNickname returns the nickname of the branch from .bzr/branch/branch.conf.
+// For lightweight checkouts, the last path segment of the bound branch location
+// is returned instead.
+func findBzrNickname(fp string) (string, error) {
+	conf := filepath.Join(fp, "branch.conf")
+	if fileOrDirExists(conf) {
+		lines, err := ReadFile(conf, maxBzrBranchConfLines)
+		if err != nil {
+			return "", fmt.Errorf("failed while opening file %q: %s", conf, err)
+		}
+
+		for _, line := range lines {
+			key, value, ok := strings.Cut(line, "=")
+			if ok && strings.TrimSpace(key) == "nickname" {
+				return strings.TrimSpace(value), nil
+			}
+		}
+	}
+
+	location := filepath.Join(fp, "location")
+	if !fileOrDirExists(location) {
+		return "", nil
+	}
+
+	lines, err := ReadFile(location, 1)
+	if err != nil {
+		return "", fmt.Errorf("failed while opening file %q: %s", location, err)
+	}
+
+	if len(lines) == 0 {
+		return "", nil
+	}
+
+	location = strings.TrimRight(strings.TrimSpace(lines[0]), "/")
+	if location == "" {
+		return "", nil
+	}
+
+	return location[strings.LastIndex(location, "/")+1:], nil
+}
+
+// ID returns its id.
+func (Bazaar) ID() DetectorID {
+	return BazaarDetector
+}
@@ -0,0 +1,102 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/project"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBazaar_Detect(t *testing.T) {
+	fp := setupTestBazaar(t, "wakatime-cli")
+
+	copyFile(t, "testdata/bzr/branch.conf", filepath.Join(fp, "wakatime-cli/.bzr/branch/branch.conf"))
+
+	err := os.Mkdir(filepath.Join(fp, "wakatime-cli/.bzr/repository"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	b := project.Bazaar{
+		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+	}
+
+	result, detected, err := b.Detect()
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Contains(t, result.Folder, fp)
+	assert.Equal(t, project.Result{
+		Project: "wakatime-cli",
+		Branch:  "feature-login",
+		Folder:  result.Folder,
+	}, result)
+}
+
+func TestBazaar_Detect_SharedRepository(t *testing.T) {
+	fp := setupTestBazaar(t, "wakatime-cli/trunk")
+
+	err := os.MkdirAll(filepath.Join(fp, "wakatime-cli/.bzr/repository"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	b := project.Bazaar{
+		Filepath: filepath.Join(fp, "wakatime-cli/trunk/src/pkg/file.go"),
+	}
+
+	result, detected, err := b.Detect()
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Contains(t, result.Folder, fp)
+	assert.Equal(t, project.Result{
+		Project: "wakatime-cli",
+		Branch:  "trunk",
+		Folder:  result.Folder,
+	}, result)
+}
+
+func TestBazaar_Detect_LightweightCheckout(t *testing.T) {
+	fp := setupTestBazaar(t, "wakatime-cli")
+
+	copyFile(t, "testdata/bzr/location", filepath.Join(fp, "wakatime-cli/.bzr/branch/location"))
+
+	b := project.Bazaar{
+		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+	}
+
+	result, detected, err := b.Detect()
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Contains(t, result.Folder, fp)
+	assert.Equal(t, project.Result{
+		Project: "wakatime-cli",
+		Branch:  "hotfix",
+		Folder:  result.Folder,
+	}, result)
+}
+
+func TestBazaar_ID(t *testing.T) {
+	b := project.Bazaar{}
+
+	assert.Equal(t, project.BazaarDetector, b.ID())
+}
+
+func setupTestBazaar(t *testing.T, tree string) (fp string) {
+	tmpDir := t.TempDir()
+
+	err := os.MkdirAll(filepath.Join(tmpDir, tree, "src/pkg"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	tmpFile, err := os.Create(filepath.Join(tmpDir, tree, "src/pkg/file.go"))
+	require.NoError(t, err)
+
+	defer tmpFile.Close()
+
+	err = os.MkdirAll(filepath.Join(tmpDir, tree, ".bzr/branch"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	return tmpDir
+}
@@ -0,0 +1,150 @@
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/remote"
+)
+
+// cacheBucket is the detection cache bucket of project detection results.
+const cacheBucket = "project"
+
+// cacheStateFiles are files and folders, which affect project detection when
+// created or modified. They are recorded for every parent folder of an entity,
+// in which they exist.
+// nolint:gochecknoglobals
+var cacheStateFiles = []string{
+	WakaTimeProjectFile,
+	".git",
+	filepath.Join(".git", "HEAD"),
+	filepath.Join(".git", "config"),
+	filepath.Join(".git", "packed-refs"),
+	".hg",
+	filepath.Join(".hg", "branch"),
+	".jj",
+	".fslckout",
+	"_FOSSIL_",
+	filepath.Join(".pijul", "config"),
+	filepath.Join(".bzr", "branch"),
+	"go.mod",
+	"package.json",
+	"Cargo.toml",
+}
+
+// detection contains the project detection result of a heartbeat.
+type detection struct {
+	Result        Result
+	Category      *heartbeat.Category
+	HideFileNames bool
+	// Source describes how the project was detected.
+	Source string
+
+	cached bool
+}
+
+// detectWithCache finds the project of a heartbeat and consults the detection
+// cache first for local file entities. Cache entries are invalidated, once a
+// parent folder of the entity or a version control file in one of them changes.
+func detectWithCache(h heartbeat.Heartbeat, config Config, pool *remote.Pool) detection {
+	if config.Cache == nil || h.EntityType != heartbeat.FileType || h.IsRemote() {
+		return detect(h, config, pool)
+	}
+
+	key := cacheKey(h, config)
+
+	var d detection
+	if config.Cache.Get(cacheBucket, key, &d) {
+		d.cached = true
+
+		return d
+	}
+
+	d = detect(h, config, pool)
+
+	config.Cache.Set(cacheBucket, key, d, cacheFiles(h.Entity)...)
+
+	return d
+}
+
+// cacheKey returns the cache key of a heartbeat. It contains all heartbeat
+// fields and configurations, which affect project detection.
+func cacheKey(h heartbeat.Heartbeat, config Config) string {
+	// pseudonymizers differ per invocation, so only record whether one is set
+	pseudonymize := config.Pseudonymizer != nil
+
+	config.Cache = nil
+	config.Pseudonymizer = nil
+	config.RemotePool = nil
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%v|%t", config, pseudonymize)))
+
+	return strings.Join([]string{
+		h.Entity,
+		h.ProjectOverride,
+		h.ProjectPathOverride,
+		h.ProjectAlternate,
+		h.BranchAlternate,
+		hex.EncodeToString(sum[:]),
+	}, "|")
+}
+
+// cacheFiles returns the files and folders a project detection result depends on.
+func cacheFiles(entity string) []string {
+	var files []string
+
+	dir := filepath.Dir(entity)
+
+	for i := 0; i < maxRecursiveIteration && !isRootPath(dir); i++ {
+		files = append(files, dir)
+
+		for _, name := range cacheStateFiles {
+			if fp := filepath.Join(dir, name); fileOrDirExists(fp) {
+				files = append(files, fp)
+			}
+		}
+
+		if info, err := os.Stat(filepath.Join(dir, ".git")); err == nil && !info.IsDir() {
+			files = append(files, gitdirStateFiles(filepath.Join(dir, ".git"))...)
+		}
+
+		dir = filepath.Dir(dir)
+	}
+
+	return files
+}
+
+// gitdirStateFiles returns the files and folders of the git folder, which a
+// .git file of a linked worktree or submodule points to. For linked worktrees,
+// the files of the common git folder are returned as well.
+func gitdirStateFiles(dotGit string) []string {
+	gitdir, err := findGitdir(dotGit)
+	if err != nil || gitdir == "" {
+		return nil
+	}
+
+	dirs := []string{gitdir}
+
+	if commondir, ok, err := findCommondir(gitdir); err == nil && ok {
+		dirs = append(dirs, commondir)
+	}
+
+	var files []string
+
+	for _, dir := range dirs {
+		files = append(files, dir)
+
+		for _, name := range []string{"HEAD", "config", "config.worktree", "packed-refs"} {
+			if fp := filepath.Join(dir, name); fileOrDirExists(fp) {
+				files = append(files, fp)
+			}
+		}
+	}
+
+	return files
+}
@@ -6,9 +6,24 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/regex"
+
+	"gopkg.in/ini.v1"
+)
+
+// maxProjectFileLines is the maximum number of lines read from a .wakatime-project file.
+const maxProjectFileLines = 1000
+
+// projectFileKeyRegex matches a line setting one of the keys of the key/value
+// .wakatime-project file format.
+var projectFileKeyRegex = regexp.MustCompile(
+	`^(project|branch|category|include|exclude|hide_file_names|api_key)\s*=`,
 )
 
 // File contains file data.
@@ -16,37 +31,198 @@ type File struct {
 	Filepath string
 }
 
+// FileConfig contains the settings of a .wakatime-project file.
+type FileConfig struct {
+	// APIKey overrides the default api key.
+	APIKey string
+	// Branch overrides the detected branch.
+	Branch string
+	// Category overrides the heartbeat category.
+	Category *heartbeat.Category
+	// Exclude contains patterns of files to skip, matched against the path
+	// relative to Folder.
+	Exclude []regex.Regex
+	// Folder is the folder containing the .wakatime-project file.
+	Folder string
+	// HideFileNames determines if file names should be obfuscated.
+	HideFileNames bool
+	// Include contains patterns of files to track, even if excluded. Matched
+	// against the path relative to Folder.
+	Include []regex.Regex
+	// Project is the project name. Defaults to the name of Folder.
+	Project string
+}
+
 // Detect get information from a .wakatime-project file about the project for
 // a given file. First line of .wakatime-project sets the project
-// name. Second line sets the current branch name.
+// name. Second line sets the current branch name. Alternatively, the file
+// can set project and branch keys, as described at ReadFileConfig.
 func (f File) Detect() (Result, bool, error) {
-	fp, ok := FindFileOrDirectory(f.Filepath, WakaTimeProjectFile)
+	config, ok, err := ReadFileConfig(f.Filepath)
+	if err != nil {
+		return Result{}, false, err
+	}
+
 	if !ok {
 		return Result{}, false, nil
 	}
 
-	log.Debugf("wakatime project file found at: %s", fp)
+	return Result{
+		Project: config.Project,
+		Branch:  config.Branch,
+		Folder:  config.Folder,
+	}, true, nil
+}
+
+// ReadFileConfig finds the .wakatime-project file for a given path and reads
+// its settings. Two formats are supported. In the line format, the first
+// line sets the project name and the second line the branch name. In the
+// key/value format, INI style keys set project, branch, category, include,
+// exclude, hide_file_names and api_key. Include and exclude accept multiple
+// patterns on indented lines. The key/value format is used, if the first line
+// which is neither empty nor a comment sets one of these keys.
+func ReadFileConfig(fp string) (FileConfig, bool, error) {
+	projectFile, ok := FindFileOrDirectory(fp, WakaTimeProjectFile)
+	if !ok {
+		return FileConfig{}, false, nil
+	}
+
+	log.Debugf("wakatime project file found at: %s", projectFile)
 
-	lines, err := ReadFile(fp, 2)
+	lines, err := ReadFile(projectFile, maxProjectFileLines)
 	if err != nil {
-		return Result{}, false, fmt.Errorf("error reading file: %s", err)
+		return FileConfig{}, false, fmt.Errorf("error reading file: %s", err)
 	}
 
-	result := Result{
-		Folder: filepath.Dir(fp),
+	config, err := parseFileConfig(lines, filepath.Dir(projectFile), filepath.Base(filepath.Dir(projectFile)))
+	if err != nil {
+		return FileConfig{}, false, fmt.Errorf("error parsing file %q: %s", projectFile, err)
 	}
 
-	result.Project = filepath.Base(filepath.Dir(fp))
+	return config, true, nil
+}
 
-	if len(lines) > 0 {
-		result.Project = strings.TrimSpace(lines[0])
+// parseFileConfig parses the lines of a .wakatime-project file in folder.
+// The project defaults to the passed in folder name.
+func parseFileConfig(lines []string, folder, folderName string) (FileConfig, error) {
+	config := FileConfig{
+		Folder:  folder,
+		Project: folderName,
 	}
 
-	if len(lines) > 1 {
-		result.Branch = strings.TrimSpace(lines[1])
+	if !isKeyValueProjectFile(lines) {
+		if len(lines) > 0 {
+			config.Project = strings.TrimSpace(lines[0])
+		}
+
+		if len(lines) > 1 {
+			config.Branch = strings.TrimSpace(lines[1])
+		}
+
+		return config, nil
+	}
+
+	if err := parseProjectFileKeys(&config, strings.Join(lines, "\n")); err != nil {
+		return FileConfig{}, err
+	}
+
+	return config, nil
+}
+
+// isKeyValueProjectFile returns true if the first line, which is neither empty
+// nor a comment, sets a key of the key/value format.
+func isKeyValueProjectFile(lines []string) bool {
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		return projectFileKeyRegex.MatchString(line)
+	}
+
+	return false
+}
+
+func parseProjectFileKeys(config *FileConfig, data string) error {
+	file, err := ini.LoadSources(ini.LoadOptions{
+		AllowPythonMultilineValues: true,
+		SkipUnrecognizableLines:    true,
+	}, []byte(data))
+	if err != nil {
+		return fmt.Errorf("failed to parse key/value format: %s", err)
+	}
+
+	section := file.Section(ini.DefaultSection)
+
+	if project := strings.TrimSpace(section.Key("project").String()); project != "" {
+		config.Project = project
+	}
+
+	config.Branch = strings.TrimSpace(section.Key("branch").String())
+	config.APIKey = strings.TrimSpace(section.Key("api_key").String())
+
+	if value := strings.TrimSpace(section.Key("category").String()); value != "" {
+		category, err := heartbeat.ParseCategory(value)
+		if err != nil {
+			log.Warnf("invalid .wakatime-project category: %s", err)
+		} else {
+			config.Category = &category
+		}
+	}
+
+	if value := strings.TrimSpace(section.Key("hide_file_names").String()); value != "" {
+		hide, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Warnf("invalid .wakatime-project hide_file_names value %q: %s", value, err)
+		}
+
+		config.HideFileNames = hide
+	}
+
+	config.Include = parseProjectFilePatterns(section.Key("include").String())
+	config.Exclude = parseProjectFilePatterns(section.Key("exclude").String())
+
+	return nil
+}
+
+// parseProjectFilePatterns parses a list of patterns separated by new lines.
+// Patterns are case insensitive. Invalid patterns are skipped.
+func parseProjectFilePatterns(s string) []regex.Regex {
+	var patterns []regex.Regex
+
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		// make all regex case insensitive
+		if !strings.HasPrefix(line, "(?i)") {
+			line = "(?i)" + line
+		}
+
+		compiled, err := regex.Compile(line)
+		if err != nil {
+			log.Warnf("failed to compile .wakatime-project regex pattern %q: %s", line, err)
+			continue
+		}
+
+		patterns = append(patterns, compiled)
+	}
+
+	return patterns
+}
+
+// RelativePath returns the path of a file relative to Folder, using forward
+// slashes. It is matched against Include and Exclude patterns.
+func (c FileConfig) RelativePath(fp string) string {
+	rel, err := filepath.Rel(c.Folder, fp)
+	if err != nil {
+		return filepath.ToSlash(fp)
 	}
 
-	return result, true, nil
+	return filepath.ToSlash(rel)
 }
 
 // fileOrDirExists checks if a file or directory exist.
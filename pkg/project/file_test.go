@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/project"
 
 	"github.com/gandarez/go-realpath"
@@ -71,6 +72,33 @@ func TestFile_Detect_ParentFolderExists(t *testing.T) {
 	assert.Equal(t, expected, result)
 }
 
+func TestFile_Detect_KeyValue(t *testing.T) {
+	tmpDir, err := realpath.Realpath(t.TempDir())
+	require.NoError(t, err)
+
+	copyFile(
+		t,
+		"testdata/wakatime-project-settings",
+		filepath.Join(tmpDir, ".wakatime-project"),
+	)
+
+	f := project.File{
+		Filepath: filepath.Join(tmpDir, ".wakatime-project"),
+	}
+
+	result, detected, err := f.Detect()
+	require.NoError(t, err)
+
+	expected := project.Result{
+		Branch:  "feature/settings",
+		Folder:  tmpDir,
+		Project: "wakatime-cli",
+	}
+
+	assert.True(t, detected)
+	assert.Equal(t, expected, result)
+}
+
 func TestFile_Detect_NoFileFound(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -108,6 +136,110 @@ func TestFile_Detect_InvalidPath(t *testing.T) {
 	assert.False(t, detected)
 }
 
+func TestReadFileConfig(t *testing.T) {
+	tmpDir, err := realpath.Realpath(t.TempDir())
+	require.NoError(t, err)
+
+	copyFile(
+		t,
+		"testdata/wakatime-project-settings",
+		filepath.Join(tmpDir, ".wakatime-project"),
+	)
+
+	config, ok, err := project.ReadFileConfig(filepath.Join(tmpDir, "docs", "index.md"))
+	require.NoError(t, err)
+
+	require.True(t, ok)
+
+	assert.Equal(t, "wakatime-cli", config.Project)
+	assert.Equal(t, "feature/settings", config.Branch)
+	assert.Equal(t, tmpDir, config.Folder)
+	assert.Equal(t, "00000000-0000-4000-8000-000000000000", config.APIKey)
+	assert.True(t, config.HideFileNames)
+
+	require.NotNil(t, config.Category)
+	assert.Equal(t, heartbeat.WritingDocsCategory, *config.Category)
+
+	require.Len(t, config.Include, 1)
+	assert.Equal(t, "(?i)^docs/public/", config.Include[0].String())
+
+	require.Len(t, config.Exclude, 2)
+	assert.Equal(t, "(?i)^docs/", config.Exclude[0].String())
+	assert.Equal(t, `(?i)\.env$`, config.Exclude[1].String())
+
+	assert.Equal(t, "docs/index.md", config.RelativePath(filepath.Join(tmpDir, "docs", "index.md")))
+}
+
+func TestReadFileConfig_KeyValueMinimal(t *testing.T) {
+	tmpDir, err := realpath.Realpath(t.TempDir())
+	require.NoError(t, err)
+
+	copyFile(
+		t,
+		"testdata/wakatime-project-settings-minimal",
+		filepath.Join(tmpDir, ".wakatime-project"),
+	)
+
+	config, ok, err := project.ReadFileConfig(tmpDir)
+	require.NoError(t, err)
+
+	require.True(t, ok)
+
+	category := heartbeat.CodeReviewingCategory
+
+	assert.Equal(t, project.FileConfig{
+		Category: &category,
+		Folder:   tmpDir,
+		Project:  filepath.Base(tmpDir),
+	}, config)
+}
+
+func TestReadFileConfig_Lines(t *testing.T) {
+	tmpDir, err := realpath.Realpath(t.TempDir())
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		Content  string
+		Expected project.FileConfig
+	}{
+		"empty": {
+			Content: "",
+			Expected: project.FileConfig{
+				Folder:  tmpDir,
+				Project: filepath.Base(tmpDir),
+			},
+		},
+		"project and branch": {
+			Content: "wakatime-cli\nmaster\n",
+			Expected: project.FileConfig{
+				Branch:  "master",
+				Folder:  tmpDir,
+				Project: "wakatime-cli",
+			},
+		},
+		"project with equal sign": {
+			Content: "a = b\n",
+			Expected: project.FileConfig{
+				Folder:  tmpDir,
+				Project: "a = b",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := os.WriteFile(filepath.Join(tmpDir, ".wakatime-project"), []byte(test.Content), 0600)
+			require.NoError(t, err)
+
+			config, ok, err := project.ReadFileConfig(tmpDir)
+			require.NoError(t, err)
+
+			assert.True(t, ok)
+			assert.Equal(t, test.Expected, config)
+		})
+	}
+}
+
 func TestFindFileOrDirectory(t *testing.T) {
 	tmpDir := t.TempDir()
 
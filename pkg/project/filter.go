@@ -28,6 +28,7 @@ func WithFiltering(config FilterConfig) heartbeat.HandleOption {
 				err := Filter(h, config)
 				if err != nil {
 					log.Debugln(err.Error())
+					h.Explanation.Add("project filter", "heartbeat skipped: %s", err)
 
 					if h.LocalFileNeedsCleanup {
 						err = os.Remove(h.LocalFile)
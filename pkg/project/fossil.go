@@ -0,0 +1,121 @@
+package project
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// maxFossilManifestTagsLines is the maximum number of lines read from the
+// manifest.tags file.
+const maxFossilManifestTagsLines = 1000
+
+// Fossil contains fossil data.
+type Fossil struct {
+	// Filepath contains the entity path.
+	Filepath string
+}
+
+// Detect gets information about the fossil project for a given file.
+func (f Fossil) Detect() (Result, bool, error) {
+	var fp string
+
+	// Take only the directory
+	if fileOrDirExists(f.Filepath) {
+		fp = filepath.Dir(f.Filepath)
+	}
+
+	checkoutFileName := ".fslckout"
+	if runtime.GOOS == "windows" {
+		checkoutFileName = "_FOSSIL_"
+	}
+
+	// Find for .fslckout file
+	checkoutFile, ok := FindFileOrDirectory(fp, checkoutFileName)
+	if !ok {
+		return Result{}, false, nil
+	}
+
+	folder := filepath.Dir(checkoutFile)
+
+	branch, err := findFossilBranch(folder)
+	if err != nil {
+		log.Errorf(
+			"error finding for branch name from %q: %s",
+			folder,
+			err,
+		)
+	}
+
+	return Result{
+		Project: filepath.Base(folder),
+		Branch:  branch,
+		Folder:  folder,
+	}, true, nil
+}
+
+// findFossilBranch returns the current branch of the checkout. It is read from
+// the manifest.tags file, if the manifest setting enables it, or from the
+// fossil binary otherwise.
+func findFossilBranch(fp string) (string, error) {
+	p := filepath.Join(fp, "manifest.tags")
+	if fileOrDirExists(p) {
+		lines, err := ReadFile(p, maxFossilManifestTagsLines)
+		if err != nil {
+			return "", fmt.Errorf("failed while opening file %q: %s", p, err)
+		}
+
+		for _, line := range lines {
+			if branch, ok := strings.CutPrefix(strings.TrimSpace(line), "branch "); ok {
+				return strings.TrimSpace(branch), nil
+			}
+		}
+	}
+
+	binary, ok := findFossilBinary()
+	if !ok {
+		log.Debugln("fossil binary not found")
+		return "", nil
+	}
+
+	cmd := exec.Command(binary, "branch", "current") // nolint:gosec
+	cmd.Dir = fp
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("error getting fossil branch: %s", err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+func findFossilBinary() (string, bool) {
+	locations := []string{
+		"fossil",
+		"/usr/bin/fossil",
+		"/usr/local/bin/fossil",
+	}
+
+	for _, loc := range locations {
+		cmd := exec.Command(loc, "version") // nolint:gosec
+
+		err := cmd.Run()
+		if err != nil {
+			log.Debugf("failed while calling %s version: %s", loc, err)
+			continue
+		}
+
+		return loc, true
+	}
+
+	return "", false
+}
+
+// ID returns its id.
+func (Fossil) ID() DetectorID {
+	return FossilDetector
+}
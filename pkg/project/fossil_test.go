@@ -0,0 +1,79 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/project"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFossil_Detect(t *testing.T) {
+	fp := setupTestFossil(t)
+
+	copyFile(t, "testdata/fossil/manifest.tags", filepath.Join(fp, "wakatime-cli/manifest.tags"))
+
+	f := project.Fossil{
+		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+	}
+
+	result, detected, err := f.Detect()
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Contains(t, result.Folder, fp)
+	assert.Equal(t, project.Result{
+		Project: "wakatime-cli",
+		Branch:  "feature-login",
+		Folder:  result.Folder,
+	}, result)
+}
+
+func TestFossil_Detect_NoManifestTags(t *testing.T) {
+	fp := setupTestFossil(t)
+
+	f := project.Fossil{
+		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+	}
+
+	result, detected, err := f.Detect()
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Contains(t, result.Folder, fp)
+	assert.Equal(t, "wakatime-cli", result.Project)
+}
+
+func TestFossil_ID(t *testing.T) {
+	f := project.Fossil{}
+
+	assert.Equal(t, project.FossilDetector, f.ID())
+}
+
+func setupTestFossil(t *testing.T) (fp string) {
+	tmpDir := t.TempDir()
+
+	err := os.MkdirAll(filepath.Join(tmpDir, "wakatime-cli/src/pkg"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	tmpFile, err := os.Create(filepath.Join(tmpDir, "wakatime-cli/src/pkg/file.go"))
+	require.NoError(t, err)
+
+	defer tmpFile.Close()
+
+	checkoutFileName := ".fslckout"
+	if runtime.GOOS == "windows" {
+		checkoutFileName = "_FOSSIL_"
+	}
+
+	tmpCheckoutFile, err := os.Create(filepath.Join(tmpDir, "wakatime-cli", checkoutFileName))
+	require.NoError(t, err)
+
+	defer tmpCheckoutFile.Close()
+
+	return tmpDir
+}
@@ -2,6 +2,7 @@ package project
 
 import (
 	"fmt"
+	"net/url"
 	"path/filepath"
 	"strings"
 
@@ -13,6 +14,9 @@ import (
 type Git struct {
 	// Filepath contains the entity path.
 	Filepath string
+	// PreferredRemote is the name of the git remote used as project name when ProjectFromGitRemote
+	// is enabled. Falls back to origin, then to the first remote. Defaults to origin.
+	PreferredRemote string
 	// ProjectFromGitRemote when enabled uses the git remote as the project name instead of local git folder.
 	ProjectFromGitRemote bool
 	// SubmoduleDisabledPatterns will be matched against the submodule path and if matching, will skip it.
@@ -38,14 +42,7 @@ func (g Git) Detect() (Result, bool, error) {
 	}
 
 	if ok {
-		project := projectOrRemote(filepath.Base(gitdirSubmodule), g.ProjectFromGitRemote, gitdirSubmodule)
-
-		// If submodule has a project map, then use it.
-		if result, ok := matchPattern(gitdirSubmodule, g.SubmoduleProjectMapPatterns); ok {
-			project = result
-		}
-
-		branch, err := findGitBranch(filepath.Join(gitdirSubmodule, "HEAD"))
+		branch, err := findGitBranch(gitdirSubmodule, gitdirSubmodule)
 		if err != nil {
 			log.Errorf(
 				"error finding branch from %q: %s",
@@ -54,6 +51,13 @@ func (g Git) Detect() (Result, bool, error) {
 			)
 		}
 
+		project := g.projectOrRemote(filepath.Base(gitdirSubmodule), branch, gitdirSubmodule, gitdirSubmodule)
+
+		// If submodule has a project map, then use it.
+		if result, ok := matchPattern(gitdirSubmodule, g.SubmoduleProjectMapPatterns); ok {
+			project = result
+		}
+
 		return Result{
 			Project: project,
 			Branch:  branch,
@@ -93,9 +97,7 @@ func (g Git) Detect() (Result, bool, error) {
 			dir = commondir
 		}
 
-		project := projectOrRemote(filepath.Base(dir), g.ProjectFromGitRemote, commondir)
-
-		branch, err := findGitBranch(filepath.Join(gitdir, "HEAD"))
+		branch, err := findGitBranch(gitdir, commondir)
 		if err != nil {
 			log.Errorf(
 				"error finding branch from %q: %s",
@@ -104,6 +106,8 @@ func (g Git) Detect() (Result, bool, error) {
 			)
 		}
 
+		project := g.projectOrRemote(filepath.Base(dir), branch, gitdir, commondir)
+
 		return Result{
 			Project: project,
 			Branch:  branch,
@@ -113,9 +117,7 @@ func (g Git) Detect() (Result, bool, error) {
 
 	// Otherwise it's only a plain .git file and not a submodule
 	if gitdir != "" && !strings.Contains(gitdir, "modules") {
-		project := projectOrRemote(filepath.Base(filepath.Join(dotGit, "..")), g.ProjectFromGitRemote, gitdir)
-
-		branch, err := findGitBranch(filepath.Join(gitdir, "HEAD"))
+		branch, err := findGitBranch(gitdir, gitdir)
 		if err != nil {
 			log.Errorf(
 				"error finding branch from %q: %s",
@@ -124,6 +126,8 @@ func (g Git) Detect() (Result, bool, error) {
 			)
 		}
 
+		project := g.projectOrRemote(filepath.Base(filepath.Join(dotGit, "..")), branch, gitdir, gitdir)
+
 		return Result{
 			Project: project,
 			Branch:  branch,
@@ -138,7 +142,7 @@ func (g Git) Detect() (Result, bool, error) {
 		gitDir := filepath.Dir(gitConfigFile)
 		projectDir := filepath.Join(gitDir, "..")
 
-		branch, err := findGitBranch(filepath.Join(gitDir, "HEAD"))
+		branch, err := findGitBranch(gitDir, gitDir)
 		if err != nil {
 			log.Errorf(
 				"error finding branch from %q: %s",
@@ -147,7 +151,7 @@ func (g Git) Detect() (Result, bool, error) {
 			)
 		}
 
-		project := projectOrRemote(filepath.Base(projectDir), g.ProjectFromGitRemote, gitDir)
+		project := g.projectOrRemote(filepath.Base(projectDir), branch, gitDir, gitDir)
 
 		return Result{
 			Project: project,
@@ -258,16 +262,36 @@ func resolveCommondir(fp string) (string, bool, error) {
 	return gitdir, true, nil
 }
 
-func projectOrRemote(projectName string, projectFromGitRemote bool, dotGitFolder string) string {
-	if !projectFromGitRemote {
+// projectOrRemote returns the project name from the git remote, if enabled
+// and found. Otherwise the passed in project name is returned. The config is
+// read from the common git directory and, for linked worktrees with
+// extensions.worktreeConfig enabled, from the worktree specific
+// config.worktree file.
+func (g Git) projectOrRemote(projectName, branch, gitdir, commondir string) string {
+	if !g.ProjectFromGitRemote {
 		return projectName
 	}
 
-	configFile := filepath.Join(dotGitFolder, "config")
+	config, err := readGitConfig(gitdir, branch, filepath.Join(commondir, "config"))
+	if err != nil {
+		log.Errorf("error reading git config from %q: %s", commondir, err)
 
-	remote, err := findGitRemote(configFile)
+		return projectName
+	}
+
+	worktreeConfig := filepath.Join(gitdir, "config.worktree")
+
+	if gitdir != commondir && config.getBool("extensions.worktreeconfig") && fileOrDirExists(worktreeConfig) {
+		if err := config.read(worktreeConfig, 0); err != nil {
+			log.Errorf("error reading git config from %q: %s", worktreeConfig, err)
+
+			return projectName
+		}
+	}
+
+	remote, err := findGitRemote(config, g.PreferredRemote)
 	if err != nil {
-		log.Errorf("error finding git remote from %q: %s", configFile, err)
+		log.Errorf("error finding git remote from %q: %s", commondir, err)
 
 		return projectName
 	}
@@ -279,7 +303,12 @@ func projectOrRemote(projectName string, projectFromGitRemote bool, dotGitFolder
 	return projectName
 }
 
-func findGitBranch(fp string) (string, error) {
+// findGitBranch returns the current branch from the HEAD file of gitdir. For a
+// detached HEAD, the name of a tag pointing to the commit is returned, if any,
+// or the short commit hash otherwise. Tags are looked up in commondir.
+func findGitBranch(gitdir, commondir string) (string, error) {
+	fp := filepath.Join(gitdir, "HEAD")
+
 	if !fileOrDirExists(fp) {
 		return "master", nil
 	}
@@ -289,65 +318,119 @@ func findGitBranch(fp string) (string, error) {
 		return "", fmt.Errorf("failed while opening file %q: %s", fp, err)
 	}
 
-	if len(lines) > 0 && strings.HasPrefix(strings.TrimSpace(lines[0]), "ref: ") {
-		parts := strings.SplitN(lines[0], "/", 3)
-		if len(parts) < 3 {
-			log.Warnf("invalid branch from %q: %s", fp, lines[0])
+	if len(lines) == 0 {
+		return "", nil
+	}
 
-			return "", nil
-		}
+	branch, hash, ok := parseGitHead(lines[0])
+	if !ok {
+		log.Warnf("invalid branch from %q: %s", fp, lines[0])
 
-		return strings.TrimSpace(strings.SplitN(lines[0], "/", 3)[2]), nil
+		return "", nil
 	}
 
-	return "", nil
+	if hash == "" {
+		return branch, nil
+	}
+
+	if tag := findGitTag(commondir, hash); tag != "" {
+		return tag, nil
+	}
+
+	return hash[:shortGitHashLength], nil
 }
 
-func findGitRemote(fp string) (string, error) {
-	if !fileOrDirExists(fp) {
-		return "", nil
+// parseGitHead parses the content of a HEAD file. The current branch is
+// returned, or for a detached HEAD the commit hash. False is returned for a
+// ref without branch name.
+func parseGitHead(head string) (branch, hash string, ok bool) {
+	head = strings.TrimSpace(head)
+
+	if strings.HasPrefix(head, "ref: ") {
+		parts := strings.SplitN(head, "/", 3)
+		if len(parts) < 3 {
+			return "", "", false
+		}
+
+		return strings.TrimSpace(parts[2]), "", true
 	}
 
-	lines, err := ReadFile(fp, 1000)
-	if err != nil {
-		return "", fmt.Errorf("failed while opening file %q: %s", fp, err)
+	if !isGitHash(head) {
+		return "", "", true
 	}
 
-	for i, line := range lines {
-		if strings.Trim(line, "\n\r\t") != "[remote \"origin\"]" {
+	return "", head, true
+}
+
+// findGitRemote returns the repository path of the preferred remote's url,
+// like wakatime/wakatime-cli. Falls back to origin, then to the first remote.
+func findGitRemote(config *gitConfig, preferred string) (string, error) {
+	names := []string{preferred, "origin"}
+	names = append(names, config.remotes...)
+
+	for _, name := range names {
+		if name == "" {
 			continue
 		}
 
-		if i >= len(lines) {
+		remoteURL := config.get("remote." + name + ".url")
+		if remoteURL == "" {
 			continue
 		}
 
-		for _, subline := range lines[i+1:] {
-			if strings.HasPrefix(subline, "[") {
-				break
-			}
+		remote, err := parseGitRemoteURL(remoteURL)
+		if err != nil {
+			return "", fmt.Errorf("invalid url of remote %q: %s", name, err)
+		}
+
+		return remote, nil
+	}
 
-			if strings.HasPrefix(strings.TrimSpace(subline), "url = ") {
-				remote := strings.Trim(subline, "\n\r\t")
+	return "", nil
+}
 
-				parts := strings.SplitN(remote, "=", 2)
-				if len(parts) != 2 {
-					return "", fmt.Errorf("invalid origin url from %q: %s", fp, subline)
-				}
+// parseGitRemoteURL returns the repository path of a remote url without
+// .git suffix. Supported are scp-like urls, like git@github.com:wakatime/wakatime-cli.git,
+// and urls with scheme, like https://github.com/wakatime/wakatime-cli.git.
+// Local paths return an empty string.
+func parseGitRemoteURL(remote string) (string, error) {
+	remote = strings.TrimSpace(remote)
 
-				remote = parts[1]
+	var path string
 
-				parts = strings.SplitN(remote, ":", 2)
-				if len(parts) != 2 {
-					return "", fmt.Errorf("invalid origin url from %q: %s", fp, subline)
-				}
+	switch {
+	case strings.Contains(remote, "://"):
+		parsed, err := url.Parse(remote)
+		if err != nil {
+			return "", err
+		}
 
-				return strings.TrimSpace(strings.TrimSuffix(parts[1], ".git")), nil
-			}
+		if parsed.Scheme == "file" || parsed.Host == "" {
+			return "", nil
 		}
+
+		path = parsed.Path
+	case strings.Contains(remote, ":"):
+		host, p, _ := strings.Cut(remote, ":")
+
+		// a colon after the first slash is part of a local path
+		if strings.Contains(host, "/") {
+			return "", nil
+		}
+
+		path = p
+	default:
+		return "", nil
 	}
 
-	return "", nil
+	path = strings.Trim(path, "/")
+	path = strings.TrimSuffix(path, ".git")
+
+	if path == "" {
+		return "", fmt.Errorf("missing repository path: %s", remote)
+	}
+
+	return path, nil
 }
 
 // ID returns its id.
@@ -69,11 +69,167 @@ func TestGit_Detect_DetachedHead(t *testing.T) {
 	assert.Contains(t, result.Folder, filepath.Join(fp, "wakatime-cli"))
 	assert.Equal(t, project.Result{
 		Project: "wakatime-cli",
-		Branch:  "",
+		Branch:  "f4f242d",
 		Folder:  result.Folder,
 	}, result)
 }
 
+func TestGit_Detect_DetachedHead_Tag(t *testing.T) {
+	tests := map[string]struct {
+		Head     string
+		Expected string
+	}{
+		"annotated packed tag": {
+			Head:     "HEAD_ANNOTATED_PACKED",
+			Expected: "v1.0.0",
+		},
+		"lightweight packed tag": {
+			Head:     "HEAD_LIGHTWEIGHT_PACKED",
+			Expected: "v1.1.0",
+		},
+		"annotated loose tag": {
+			Head:     "HEAD_ANNOTATED_LOOSE",
+			Expected: "v2.0.0",
+		},
+		"lightweight loose tag": {
+			Head:     "HEAD_LIGHTWEIGHT_LOOSE",
+			Expected: "v2.1.0",
+		},
+		"untagged": {
+			Head:     "HEAD_UNTAGGED",
+			Expected: "ef80be7",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fp := setupTestGitDetachedHead(t, test.Head)
+
+			g := project.Git{
+				Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+			}
+
+			result, detected, err := g.Detect()
+			require.NoError(t, err)
+
+			assert.True(t, detected)
+			assert.Equal(t, project.Result{
+				Project: "wakatime-cli",
+				Branch:  test.Expected,
+				Folder:  result.Folder,
+			}, result)
+		})
+	}
+}
+
+func TestGit_Detect_GitRemote_PreferredRemote(t *testing.T) {
+	tests := map[string]struct {
+		Config          string
+		PreferredRemote string
+		Expected        string
+	}{
+		"origin by default": {
+			Config:   "testdata/git_remotes/config",
+			Expected: "wakatime/wakatime-cli",
+		},
+		"preferred remote with ssh url": {
+			Config:          "testdata/git_remotes/config",
+			PreferredRemote: "upstream",
+			Expected:        "upstream/wakatime-cli",
+		},
+		"preferred remote with scp-like url": {
+			Config:          "testdata/git_remotes/config",
+			PreferredRemote: "fork",
+			Expected:        "alanhamlett/wakatime-cli",
+		},
+		"missing preferred remote falls back to origin": {
+			Config:          "testdata/git_remotes/config",
+			PreferredRemote: "nonexisting",
+			Expected:        "wakatime/wakatime-cli",
+		},
+		"missing origin falls back to first remote": {
+			Config:   "testdata/git_remotes/config_without_origin",
+			Expected: "alanhamlett/wakatime-cli",
+		},
+		"local remote falls back to folder": {
+			Config:   "testdata/git_remotes/config_local",
+			Expected: "wakatime-cli",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fp := setupTestGitBasic(t)
+
+			copyFile(t, test.Config, filepath.Join(fp, "wakatime-cli/.git/config"))
+
+			g := project.Git{
+				Filepath:             filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+				PreferredRemote:      test.PreferredRemote,
+				ProjectFromGitRemote: true,
+			}
+
+			result, detected, err := g.Detect()
+			require.NoError(t, err)
+
+			assert.True(t, detected)
+			assert.Equal(t, project.Result{
+				Project: test.Expected,
+				Branch:  "master",
+				Folder:  result.Folder,
+			}, result)
+		})
+	}
+}
+
+func TestGit_Detect_GitRemote_Include(t *testing.T) {
+	tests := map[string]struct {
+		Head            string
+		PreferredRemote string
+		Expected        string
+		ExpectedBranch  string
+	}{
+		"include": {
+			Head:            "testdata/git_basic/HEAD",
+			PreferredRemote: "upstream",
+			Expected:        "upstream/wakatime-cli",
+			ExpectedBranch:  "master",
+		},
+		"includeIf gitdir": {
+			Head:           "testdata/git_basic/HEAD",
+			Expected:       "wakatime/wakatime-cli",
+			ExpectedBranch: "master",
+		},
+		"includeIf onbranch": {
+			Head:           "testdata/git_basic/HEAD_WITH_SLASH",
+			Expected:       "wakatime/feature",
+			ExpectedBranch: "feature/detection",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fp := setupTestGitInclude(t, test.Head)
+
+			g := project.Git{
+				Filepath:             filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+				PreferredRemote:      test.PreferredRemote,
+				ProjectFromGitRemote: true,
+			}
+
+			result, detected, err := g.Detect()
+			require.NoError(t, err)
+
+			assert.True(t, detected)
+			assert.Equal(t, project.Result{
+				Project: test.Expected,
+				Branch:  test.ExpectedBranch,
+				Folder:  result.Folder,
+			}, result)
+		})
+	}
+}
+
 func TestGit_Detect_GitConfigFile_File(t *testing.T) {
 	fp := setupTestGitFile(t)
 
@@ -177,6 +333,74 @@ func TestGit_Detect_WorktreeGitRemote(t *testing.T) {
 	}, result)
 }
 
+func TestGit_Detect_Worktree_Sparse(t *testing.T) {
+	fp := setupTestGitWorktree(t)
+
+	// sparse checkout keeps worktree specific config in config.worktree
+	worktreeDir := filepath.Join(fp, "wakatime-cli/.git/worktrees/api")
+
+	copyFile(t, "testdata/git_worktree/config.worktree", filepath.Join(worktreeDir, "config.worktree"))
+
+	f, err := os.OpenFile(filepath.Join(fp, "wakatime-cli/.git/config"), os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+
+	_, err = f.WriteString("[extensions]\n\tworktreeConfig = true\n")
+	require.NoError(t, err)
+
+	err = f.Close()
+	require.NoError(t, err)
+
+	err = os.Mkdir(filepath.Join(worktreeDir, "info"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	copyFile(t, "testdata/git_worktree/sparse-checkout", filepath.Join(worktreeDir, "info/sparse-checkout"))
+
+	g := project.Git{
+		Filepath:             filepath.Join(fp, "api/src/pkg/file.go"),
+		PreferredRemote:      "sparse",
+		ProjectFromGitRemote: true,
+	}
+
+	result, detected, err := g.Detect()
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Contains(t, result.Folder, filepath.Join(fp, "wakatime-cli"))
+	assert.Equal(t, project.Result{
+		Project: "wakatime/sparse",
+		Branch:  "feature/api",
+		Folder:  result.Folder,
+	}, result)
+}
+
+func TestGit_Detect_Worktree_ConfigWorktreeDisabled(t *testing.T) {
+	fp := setupTestGitWorktree(t)
+
+	// config.worktree is ignored without extensions.worktreeConfig
+	copyFile(
+		t,
+		"testdata/git_worktree/config.worktree",
+		filepath.Join(fp, "wakatime-cli/.git/worktrees/api/config.worktree"),
+	)
+
+	g := project.Git{
+		Filepath:             filepath.Join(fp, "api/src/pkg/file.go"),
+		PreferredRemote:      "sparse",
+		ProjectFromGitRemote: true,
+	}
+
+	result, detected, err := g.Detect()
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Contains(t, result.Folder, filepath.Join(fp, "wakatime-cli"))
+	assert.Equal(t, project.Result{
+		Project: "wakatime/wakatime-cli",
+		Branch:  "feature/api",
+		Folder:  result.Folder,
+	}, result)
+}
+
 func TestGit_Detect_Worktree_BareRepo(t *testing.T) {
 	fp := setupTestGitWorktreeBareRepo(t)
 
@@ -408,6 +632,47 @@ func setupTestGitBasicDetachedHead(t *testing.T) (fp string) {
 	return tmpDir
 }
 
+func setupTestGitDetachedHead(t *testing.T, head string) (fp string) {
+	fp = setupTestGitBasic(t)
+
+	gitDir := filepath.Join(fp, "wakatime-cli/.git")
+
+	err := os.MkdirAll(filepath.Join(gitDir, "refs/tags"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	err = os.MkdirAll(filepath.Join(gitDir, "objects/aa"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	copyFile(t, filepath.Join("testdata/git_detached", head), filepath.Join(gitDir, "HEAD"))
+	copyFile(t, "testdata/git_detached/packed-refs", filepath.Join(gitDir, "packed-refs"))
+	copyFile(t, "testdata/git_detached/refs/tags/v2.0.0", filepath.Join(gitDir, "refs/tags/v2.0.0"))
+	copyFile(t, "testdata/git_detached/refs/tags/v2.1.0", filepath.Join(gitDir, "refs/tags/v2.1.0"))
+
+	// loose annotated tag object of v2.0.0
+	copyFile(
+		t,
+		"testdata/git_detached/objects/aa/017f277a2fc929ac52280b4db713dc86182b25",
+		filepath.Join(gitDir, "objects/aa/017f277a2fc929ac52280b4db713dc86182b25"),
+	)
+
+	return fp
+}
+
+func setupTestGitInclude(t *testing.T, head string) (fp string) {
+	fp = setupTestGitBasic(t)
+
+	gitDir := filepath.Join(fp, "wakatime-cli/.git")
+
+	copyFile(t, head, filepath.Join(gitDir, "HEAD"))
+	copyFile(t, "testdata/git_include/config", filepath.Join(gitDir, "config"))
+
+	for _, name := range []string{"config.feature", "config.origin", "config.upstream", "config.wrong"} {
+		copyFile(t, filepath.Join("testdata/git_include", name), filepath.Join(gitDir, name))
+	}
+
+	return fp
+}
+
 func setupTestGitFile(t *testing.T) (fp string) {
 	tmpDir := t.TempDir()
 
@@ -0,0 +1,386 @@
+package project
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// maxGitConfigIncludeDepth is the maximum depth of nested config includes, as enforced by git.
+const maxGitConfigIncludeDepth = 10
+
+// gitConfig contains the values of a git config file and its included files.
+type gitConfig struct {
+	// values contains the values per key. Section and variable names of keys
+	// are lowercase, subsection names are case sensitive.
+	values map[string][]string
+	// remotes contains the names of remotes in order of appearance.
+	remotes []string
+	// branch is the current branch, used to evaluate onbranch conditions.
+	branch string
+	// gitdir is the git directory, used to evaluate gitdir conditions.
+	gitdir string
+	// skipIncludes ignores include and includeIf sections. Used for config
+	// files of remote hosts, as included paths are not readable locally.
+	skipIncludes bool
+}
+
+// readGitConfig reads the git config files of a repository. Config files
+// are read in the passed in order, so values of later files take precedence.
+// Missing config files are skipped. Include and includeIf sections are
+// followed, with conditions evaluated against gitdir and branch.
+func readGitConfig(gitdir, branch string, fps ...string) (*gitConfig, error) {
+	c := &gitConfig{
+		values: make(map[string][]string),
+		branch: branch,
+		gitdir: gitdir,
+	}
+
+	for _, fp := range fps {
+		if !fileOrDirExists(fp) {
+			continue
+		}
+
+		if err := c.read(fp, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// get returns the last value of the passed in key.
+func (c *gitConfig) get(key string) string {
+	values := c.values[key]
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[len(values)-1]
+}
+
+// getBool returns the last value of the passed in key as boolean. Like git,
+// true, yes, on and 1 are accepted as true.
+func (c *gitConfig) getBool(key string) bool {
+	switch strings.ToLower(c.get(key)) {
+	case "true", "yes", "on", "1":
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *gitConfig) read(fp string, depth int) error {
+	if depth > maxGitConfigIncludeDepth {
+		return fmt.Errorf("exceeded maximum include depth of %d at %q", maxGitConfigIncludeDepth, fp)
+	}
+
+	reader, err := file.OpenNoLock(fp) // nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to open file %q: %s", fp, err)
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	return c.parse(fp, reader, depth)
+}
+
+// parse parses a config file read from r. Include paths are relative to fp.
+func (c *gitConfig) parse(fp string, r io.Reader, depth int) error {
+	var (
+		section string
+		scanner = bufio.NewScanner(r)
+	)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// join continuation lines
+		for strings.HasSuffix(line, `\`) && !strings.HasSuffix(line, `\\`) && scanner.Scan() {
+			line = line[:len(line)-1] + scanner.Text()
+		}
+
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if line[0] == '[' {
+			section = parseGitConfigSection(line)
+			continue
+		}
+
+		name, value := parseGitConfigEntry(line)
+		if section == "" || name == "" {
+			continue
+		}
+
+		if err := c.set(fp, section, name, value, depth); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read file %q: %s", fp, err)
+	}
+
+	return nil
+}
+
+func (c *gitConfig) set(fp, section, name, value string, depth int) error {
+	key := section + "." + name
+
+	switch {
+	case (key == "include.path" || strings.HasPrefix(section, "includeif.") && name == "path") && c.skipIncludes:
+		return nil
+	case key == "include.path":
+		return c.include(fp, value, depth)
+	case strings.HasPrefix(section, "includeif.") && name == "path":
+		condition := strings.TrimPrefix(section, "includeif.")
+		if !c.matchCondition(fp, condition) {
+			return nil
+		}
+
+		return c.include(fp, value, depth)
+	}
+
+	if name == "url" && strings.HasPrefix(section, "remote.") {
+		remote := strings.TrimPrefix(section, "remote.")
+		if _, ok := c.values[key]; !ok {
+			c.remotes = append(c.remotes, remote)
+		}
+	}
+
+	c.values[key] = append(c.values[key], value)
+
+	return nil
+}
+
+// include reads an included config file. Relative paths are relative to
+// the including config file.
+func (c *gitConfig) include(fp, path string, depth int) error {
+	if path == "" {
+		return nil
+	}
+
+	path = expandHome(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(fp), path)
+	}
+
+	// git silently ignores missing include files
+	if !fileOrDirExists(path) {
+		log.Debugf("git config include file %q not found", path)
+		return nil
+	}
+
+	return c.read(path, depth+1)
+}
+
+// matchCondition evaluates the condition of an includeIf section.
+// Supported are the gitdir, gitdir/i and onbranch conditions.
+func (c *gitConfig) matchCondition(fp, condition string) bool {
+	keyword, pattern, ok := strings.Cut(condition, ":")
+	if !ok {
+		return false
+	}
+
+	switch keyword {
+	case "gitdir", "gitdir/i":
+		if c.gitdir == "" {
+			return false
+		}
+
+		// a trailing slash matches everything inside the directory
+		prefix := strings.HasSuffix(pattern, "/")
+
+		pattern = expandHome(pattern)
+
+		if strings.HasPrefix(pattern, "./") {
+			pattern = filepath.Join(filepath.Dir(fp), pattern)
+		} else if !filepath.IsAbs(pattern) && !strings.HasPrefix(pattern, "**") {
+			pattern = "**/" + pattern
+		}
+
+		if prefix {
+			pattern = strings.TrimSuffix(pattern, "/") + "/**"
+		}
+
+		gitdirs := []string{c.gitdir}
+		if realpath, err := filepath.EvalSymlinks(c.gitdir); err == nil && realpath != c.gitdir {
+			gitdirs = append(gitdirs, realpath)
+		}
+
+		for _, gitdir := range gitdirs {
+			if matchGitGlob(pattern, gitdir, keyword == "gitdir/i") {
+				return true
+			}
+		}
+
+		return false
+	case "onbranch":
+		if c.branch == "" {
+			return false
+		}
+
+		if strings.HasSuffix(pattern, "/") {
+			pattern += "**"
+		}
+
+		return matchGitGlob(pattern, c.branch, false)
+	default:
+		return false
+	}
+}
+
+// parseGitConfigSection parses a section header like [section "subsection"]
+// or the deprecated [section.subsection] into section.subsection.
+func parseGitConfigSection(line string) string {
+	line = strings.TrimPrefix(line, "[")
+
+	end := strings.LastIndex(line, "]")
+	if end == -1 {
+		return ""
+	}
+
+	line = line[:end]
+
+	name, subsection, ok := strings.Cut(line, " ")
+	if !ok {
+		name, subsection, ok = strings.Cut(line, ".")
+		if !ok {
+			return strings.ToLower(strings.TrimSpace(line))
+		}
+
+		return strings.ToLower(name) + "." + strings.ToLower(subsection)
+	}
+
+	subsection = strings.TrimSpace(subsection)
+	subsection = strings.TrimSuffix(strings.TrimPrefix(subsection, `"`), `"`)
+	subsection = strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(subsection)
+
+	return strings.ToLower(strings.TrimSpace(name)) + "." + subsection
+}
+
+// parseGitConfigEntry parses a variable line like name = value. Quotes,
+// escape sequences and trailing comments are handled. A variable without
+// value is a boolean true.
+func parseGitConfigEntry(line string) (string, string) {
+	name, raw, ok := strings.Cut(line, "=")
+
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !ok {
+		return name, "true"
+	}
+
+	var (
+		value strings.Builder
+		// unquoted is the length of value without trailing unquoted whitespaces
+		unquoted int
+		quoted   bool
+		escaped  bool
+	)
+
+loop:
+	for _, r := range strings.TrimSpace(raw) {
+		switch {
+		case escaped:
+			switch r {
+			case 'n':
+				value.WriteRune('\n')
+			case 't':
+				value.WriteRune('\t')
+			case 'b':
+				value.WriteRune('\b')
+			default:
+				value.WriteRune(r)
+			}
+
+			escaped = false
+		case r == '\\':
+			escaped = true
+			continue
+		case r == '"':
+			quoted = !quoted
+		case !quoted && (r == '#' || r == ';'):
+			break loop
+		default:
+			value.WriteRune(r)
+
+			if !quoted && (r == ' ' || r == '\t') {
+				continue
+			}
+		}
+
+		unquoted = value.Len()
+	}
+
+	return name, value.String()[:unquoted]
+}
+
+// matchGitGlob matches a value against a wildmatch pattern, as used by
+// includeIf conditions. Supported are *, ** and ?.
+func matchGitGlob(pattern, value string, caseInsensitive bool) bool {
+	pattern = filepath.ToSlash(pattern)
+	value = filepath.ToSlash(value)
+
+	var expr strings.Builder
+
+	if caseInsensitive {
+		expr.WriteString("(?i)")
+	}
+
+	expr.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expr.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			expr.WriteString("[^/]*")
+		case pattern[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		}
+	}
+
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		log.Debugf("failed to compile git config pattern %q: %s", pattern, err)
+		return false
+	}
+
+	return re.MatchString(value)
+}
+
+// expandHome expands a leading ~/ to the user's home directory.
+func expandHome(fp string) string {
+	if !strings.HasPrefix(fp, "~/") {
+		return fp
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		log.Debugf("failed to get user home dir: %s", err)
+		return fp
+	}
+
+	return filepath.Join(home, fp[2:])
+}
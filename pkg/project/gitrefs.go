@@ -0,0 +1,268 @@
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+const (
+	// shortGitHashLength is the length of an abbreviated commit hash.
+	shortGitHashLength = 7
+	// maxGitTagPeelDepth is the maximum number of nested tag objects to peel.
+	maxGitTagPeelDepth = 5
+)
+
+// isGitHash returns true if the passed in value is a full sha1 or sha256 object name.
+func isGitHash(value string) bool {
+	if len(value) != 40 && len(value) != 64 {
+		return false
+	}
+
+	for _, r := range value {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// findGitTag returns the name of a tag pointing to the commit with the passed
+// in hash. Loose tags take precedence over packed tags with the same name.
+// If multiple tags point to the commit, the first in alphabetical order is
+// returned.
+func findGitTag(commondir, hash string) string {
+	return findGitRef(commondir, "refs/tags/", hash)
+}
+
+// findGitHead returns the name of a branch pointing to the commit with the
+// passed in hash. If multiple branches point to the commit, the first in
+// alphabetical order is returned.
+func findGitHead(commondir, hash string) string {
+	return findGitRef(commondir, "refs/heads/", hash)
+}
+
+// findGitRef returns the name, without namespace, of a ref in the passed in
+// namespace pointing to the commit with the passed in hash.
+func findGitRef(commondir, namespace, hash string) string {
+	refs := readPackedRefs(commondir, namespace)
+
+	for name, target := range readLooseRefs(commondir, namespace) {
+		refs[name] = target
+	}
+
+	return matchGitRef(refs, hash)
+}
+
+// matchGitRef returns the first name in alphabetical order of the refs
+// pointing to the commit with the passed in hash.
+func matchGitRef(refs map[string]string, hash string) string {
+	var names []string
+
+	for name, target := range refs {
+		if target == hash {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return ""
+	}
+
+	sort.Strings(names)
+
+	return names[0]
+}
+
+// readPackedRefs reads the refs in the passed in namespace from the packed-refs
+// file. Annotated tags are peeled to the commit hash, if the peeled hash is stored.
+func readPackedRefs(commondir, namespace string) map[string]string {
+	refs := make(map[string]string)
+
+	fp := filepath.Join(commondir, "packed-refs")
+	if !fileOrDirExists(fp) {
+		return refs
+	}
+
+	reader, err := file.OpenNoLock(fp) // nolint:gosec
+	if err != nil {
+		log.Debugf("failed to open file %q: %s", fp, err)
+		return refs
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	refs, err = parsePackedRefs(reader, namespace)
+	if err != nil {
+		log.Debugf("failed to read file %q: %s", fp, err)
+	}
+
+	return refs
+}
+
+// parsePackedRefs parses the refs in the passed in namespace from the content
+// of a packed-refs file. The refs parsed so far are returned on error.
+func parsePackedRefs(r io.Reader, namespace string) (map[string]string, error) {
+	refs := make(map[string]string)
+
+	var last string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// peeled hash of the preceding annotated tag
+		if strings.HasPrefix(line, "^") {
+			if last != "" {
+				refs[last] = strings.TrimPrefix(line, "^")
+			}
+
+			continue
+		}
+
+		last = ""
+
+		hash, ref, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+
+		name, ok := strings.CutPrefix(ref, namespace)
+		if !ok {
+			continue
+		}
+
+		refs[name] = hash
+		last = name
+	}
+
+	return refs, scanner.Err()
+}
+
+// readLooseRefs reads the refs in the passed in namespace from the refs
+// directory. Annotated tags are peeled to the commit hash, if their tag
+// object is a loose object.
+func readLooseRefs(commondir, namespace string) map[string]string {
+	refs := make(map[string]string)
+
+	dir := filepath.Join(commondir, filepath.FromSlash(namespace))
+	if !fileOrDirExists(dir) {
+		return refs
+	}
+
+	err := filepath.WalkDir(dir, func(fp string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		lines, err := ReadFile(fp, 1)
+		if err != nil || len(lines) == 0 {
+			log.Debugf("failed to read ref %q: %s", fp, err)
+			return nil
+		}
+
+		name, err := filepath.Rel(dir, fp)
+		if err != nil {
+			return nil
+		}
+
+		refs[filepath.ToSlash(name)] = peelGitTag(commondir, strings.TrimSpace(lines[0]))
+
+		return nil
+	})
+	if err != nil {
+		log.Debugf("failed to read refs from %q: %s", dir, err)
+	}
+
+	return refs
+}
+
+// peelGitTag returns the hash of the object an annotated tag points to. The
+// passed in hash is returned, if it is no loose tag object.
+func peelGitTag(commondir, hash string) string {
+	for i := 0; i < maxGitTagPeelDepth; i++ {
+		target, ok := readGitTagObject(commondir, hash)
+		if !ok {
+			return hash
+		}
+
+		hash = target
+	}
+
+	return hash
+}
+
+// readGitTagObject reads the target of a loose tag object.
+func readGitTagObject(commondir, hash string) (string, bool) {
+	if !isGitHash(hash) {
+		return "", false
+	}
+
+	fp := filepath.Join(commondir, "objects", hash[:2], hash[2:])
+
+	f, err := os.Open(fp) // nolint:gosec
+	if err != nil {
+		return "", false
+	}
+
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	target, err := parseGitTagObject(f)
+	if err != nil {
+		log.Debugf("failed to read object %q: %s", fp, err)
+		return "", false
+	}
+
+	return target, target != ""
+}
+
+// parseGitTagObject parses the target of a tag object from its compressed
+// content. An empty target is returned for other objects.
+func parseGitTagObject(compressed io.Reader) (string, error) {
+	r, err := zlib.NewReader(compressed)
+	if err != nil {
+		return "", err
+	}
+
+	// the header of a tag object and its first line are small
+	data, err := io.ReadAll(io.LimitReader(r, 512))
+	if err != nil {
+		return "", err
+	}
+
+	header, content, ok := bytes.Cut(data, []byte{0})
+	if !ok || !bytes.HasPrefix(header, []byte("tag ")) {
+		return "", nil
+	}
+
+	line, _, _ := bytes.Cut(content, []byte("\n"))
+
+	target, ok := bytes.CutPrefix(line, []byte("object "))
+	if !ok {
+		return "", nil
+	}
+
+	return string(target), nil
+}
@@ -0,0 +1,138 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// Jujutsu contains jujutsu data.
+type Jujutsu struct {
+	// Filepath contains the entity path.
+	Filepath string
+}
+
+// Detect gets information about the jujutsu project for a given file.
+// The branch is the bookmark pointing to the parent of the working copy
+// commit, which requires a git backed repository.
+func (j Jujutsu) Detect() (Result, bool, error) {
+	var fp string
+
+	// Take only the directory
+	if fileOrDirExists(j.Filepath) {
+		fp = filepath.Dir(j.Filepath)
+	}
+
+	// Find for .jj/repo folder or file
+	repo, ok := FindFileOrDirectory(fp, filepath.Join(".jj", "repo"))
+	if !ok {
+		return Result{}, false, nil
+	}
+
+	jjDirectory := filepath.Dir(repo)
+	folder := filepath.Dir(jjDirectory)
+
+	// secondary workspaces point to the repo of the main workspace
+	repo, err := resolveJjRepo(repo)
+	if err != nil {
+		return Result{}, false, fmt.Errorf("failed to resolve jujutsu repo: %s", err)
+	}
+
+	branch, err := findJjBookmark(repo)
+	if err != nil {
+		log.Errorf(
+			"error finding for bookmark name from %q: %s",
+			repo,
+			err,
+		)
+	}
+
+	return Result{
+		Project: filepath.Base(filepath.Dir(filepath.Dir(repo))),
+		Branch:  branch,
+		Folder:  folder,
+	}, true, nil
+}
+
+// resolveJjRepo returns the repo directory. In secondary workspaces .jj/repo
+// is a file containing the path to the repo directory of the main workspace.
+func resolveJjRepo(fp string) (string, error) {
+	info, err := os.Stat(fp)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat %q: %s", fp, err)
+	}
+
+	if info.IsDir() {
+		return fp, nil
+	}
+
+	lines, err := ReadFile(fp, 1)
+	if err != nil {
+		return "", fmt.Errorf("failed while opening file %q: %s", fp, err)
+	}
+
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) == "" {
+		return "", fmt.Errorf("empty repo path in %q", fp)
+	}
+
+	repo := strings.TrimSpace(lines[0])
+	if !filepath.IsAbs(repo) {
+		repo = filepath.Join(filepath.Dir(fp), repo)
+	}
+
+	return filepath.Clean(repo), nil
+}
+
+// findJjBookmark returns the name of a bookmark pointing to the HEAD commit of
+// the backing git repository. Jujutsu keeps HEAD at the parent of the working
+// copy commit and exports bookmarks as git branches.
+func findJjBookmark(repo string) (string, error) {
+	fp := filepath.Join(repo, "store", "git_target")
+	if !fileOrDirExists(fp) {
+		return "", nil
+	}
+
+	lines, err := ReadFile(fp, 1)
+	if err != nil {
+		return "", fmt.Errorf("failed while opening file %q: %s", fp, err)
+	}
+
+	if len(lines) == 0 {
+		return "", nil
+	}
+
+	// git_target is relative to the store directory
+	gitdir := strings.TrimSpace(lines[0])
+	if !filepath.IsAbs(gitdir) {
+		gitdir = filepath.Join(repo, "store", gitdir)
+	}
+
+	head := filepath.Join(gitdir, "HEAD")
+	if !fileOrDirExists(head) {
+		return "", nil
+	}
+
+	lines, err = ReadFile(head, 1)
+	if err != nil {
+		return "", fmt.Errorf("failed while opening file %q: %s", head, err)
+	}
+
+	if len(lines) == 0 {
+		return "", nil
+	}
+
+	hash := strings.TrimSpace(lines[0])
+	if !isGitHash(hash) {
+		return "", nil
+	}
+
+	return findGitHead(filepath.Clean(gitdir), hash), nil
+}
+
+// ID returns its id.
+func (Jujutsu) ID() DetectorID {
+	return JujutsuDetector
+}
@@ -0,0 +1,119 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/project"
+
+	"github.com/gandarez/go-realpath"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJujutsu_Detect(t *testing.T) {
+	fp := setupTestJujutsu(t)
+
+	j := project.Jujutsu{
+		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+	}
+
+	result, detected, err := j.Detect()
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, project.Result{
+		Project: "wakatime-cli",
+		Branch:  "feature/login",
+		Folder:  filepath.Join(fp, "wakatime-cli"),
+	}, result)
+}
+
+func TestJujutsu_Detect_Workspace(t *testing.T) {
+	fp := setupTestJujutsu(t)
+
+	err := os.MkdirAll(filepath.Join(fp, "wakatime-cli-workspace/.jj"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	err = os.WriteFile(
+		filepath.Join(fp, "wakatime-cli-workspace/.jj/repo"),
+		[]byte(filepath.FromSlash("../../wakatime-cli/.jj/repo")),
+		0600,
+	)
+	require.NoError(t, err)
+
+	tmpFile, err := os.Create(filepath.Join(fp, "wakatime-cli-workspace/file.go"))
+	require.NoError(t, err)
+
+	defer tmpFile.Close()
+
+	j := project.Jujutsu{
+		Filepath: filepath.Join(fp, "wakatime-cli-workspace/file.go"),
+	}
+
+	result, detected, err := j.Detect()
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, project.Result{
+		Project: "wakatime-cli",
+		Branch:  "feature/login",
+		Folder:  filepath.Join(fp, "wakatime-cli-workspace"),
+	}, result)
+}
+
+func TestJujutsu_Detect_NoGitBackend(t *testing.T) {
+	fp := setupTestJujutsu(t)
+
+	err := os.Remove(filepath.Join(fp, "wakatime-cli/.jj/repo/store/git_target"))
+	require.NoError(t, err)
+
+	j := project.Jujutsu{
+		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+	}
+
+	result, detected, err := j.Detect()
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, project.Result{
+		Project: "wakatime-cli",
+		Folder:  filepath.Join(fp, "wakatime-cli"),
+	}, result)
+}
+
+func TestJujutsu_ID(t *testing.T) {
+	j := project.Jujutsu{}
+
+	assert.Equal(t, project.JujutsuDetector, j.ID())
+}
+
+// setupTestJujutsu creates a jujutsu repository colocated with git.
+func setupTestJujutsu(t *testing.T) (fp string) {
+	tmpDir, err := realpath.Realpath(t.TempDir())
+	require.NoError(t, err)
+
+	dirs := []string{
+		"wakatime-cli/src/pkg",
+		"wakatime-cli/.jj/repo/store",
+		"wakatime-cli/.git/refs/heads/feature",
+	}
+
+	for _, dir := range dirs {
+		err = os.MkdirAll(filepath.Join(tmpDir, dir), os.FileMode(int(0700)))
+		require.NoError(t, err)
+	}
+
+	tmpFile, err := os.Create(filepath.Join(tmpDir, "wakatime-cli/src/pkg/file.go"))
+	require.NoError(t, err)
+
+	defer tmpFile.Close()
+
+	copyFile(t, "testdata/jj/git_target", filepath.Join(tmpDir, "wakatime-cli/.jj/repo/store/git_target"))
+	copyFile(t, "testdata/jj/HEAD", filepath.Join(tmpDir, "wakatime-cli/.git/HEAD"))
+	copyFile(t, "testdata/jj/packed-refs", filepath.Join(tmpDir, "wakatime-cli/.git/packed-refs"))
+	copyFile(t, "testdata/jj/refs/heads/feature/login", filepath.Join(tmpDir, "wakatime-cli/.git/refs/heads/feature/login"))
+
+	return tmpDir
+}
@@ -0,0 +1,86 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// defaultPijulChannel is the channel of a pijul repository without configured channel.
+const defaultPijulChannel = "main"
+
+// Pijul contains pijul data.
+type Pijul struct {
+	// Filepath contains the entity path.
+	Filepath string
+}
+
+// Detect gets information about the pijul project for a given file.
+// The branch is the current channel.
+func (p Pijul) Detect() (Result, bool, error) {
+	var fp string
+
+	// Take only the directory
+	if fileOrDirExists(p.Filepath) {
+		fp = filepath.Dir(p.Filepath)
+	}
+
+	// Find for .pijul folder
+	pijulDirectory, ok := FindFileOrDirectory(fp, ".pijul")
+	if !ok {
+		return Result{}, false, nil
+	}
+
+	folder := filepath.Dir(pijulDirectory)
+
+	branch, err := findPijulChannel(pijulDirectory)
+	if err != nil {
+		log.Errorf(
+			"error finding for channel name from %q: %s",
+			pijulDirectory,
+			err,
+		)
+	}
+
+	return Result{
+		Project: filepath.Base(folder),
+		Branch:  branch,
+		Folder:  folder,
+	}, true, nil
+}
+
+// findPijulChannel returns the current channel from the .pijul/config file.
+func findPijulChannel(fp string) (string, error) {
+	p := filepath.Join(fp, "config")
+	if !fileOrDirExists(p) {
+		return defaultPijulChannel, nil
+	}
+
+	data, err := os.ReadFile(p) // nolint:gosec
+	if err != nil {
+		return "", fmt.Errorf("failed while opening file %q: %s", p, err)
+	}
+
+	var config struct {
+		CurrentChannel string `toml:"current_channel"`
+	}
+
+	if err := toml.Unmarshal(data, &config); err != nil {
+		return "", fmt.Errorf("failed to parse file %q: %s", p, err)
+	}
+
+	if config.CurrentChannel == "" {
+		return defaultPijulChannel, nil
+	}
+
+	return config.CurrentChannel, nil
+}
+
+// ID returns its id.
+func (Pijul) ID() DetectorID {
+	return PijulDetector
+}
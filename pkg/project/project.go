@@ -11,9 +11,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/wakatime/wakatime-cli/pkg/cache"
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/log"
 	"github.com/wakatime/wakatime-cli/pkg/regex"
+	"github.com/wakatime/wakatime-cli/pkg/remote"
 	"github.com/wakatime/wakatime-cli/pkg/windows"
 
 	"github.com/gandarez/go-realpath"
@@ -51,6 +53,14 @@ const (
 	SubversionDetector
 	// TfvcDetector is the detector ID for tfvc detector.
 	TfvcDetector
+	// JujutsuDetector is the detector ID for jujutsu detector.
+	JujutsuDetector
+	// FossilDetector is the detector ID for fossil detector.
+	FossilDetector
+	// PijulDetector is the detector ID for pijul detector.
+	PijulDetector
+	// BazaarDetector is the detector ID for bazaar detector.
+	BazaarDetector
 )
 
 const (
@@ -60,6 +70,10 @@ const (
 	mercurialDetectorString  = "mercurial-detector"
 	subversionDetectorString = "svn-detector"
 	tfvcDetectorString       = "tfvc-detector"
+	jujutsuDetectorString    = "jujutsu-detector"
+	fossilDetectorString     = "fossil-detector"
+	pijulDetectorString      = "pijul-detector"
+	bazaarDetectorString     = "bazaar-detector"
 )
 
 // String implements fmt.Stringer interface.
@@ -77,6 +91,14 @@ func (d DetectorID) String() string {
 		return subversionDetectorString
 	case TfvcDetector:
 		return tfvcDetectorString
+	case JujutsuDetector:
+		return jujutsuDetectorString
+	case FossilDetector:
+		return fossilDetectorString
+	case PijulDetector:
+		return pijulDetectorString
+	case BazaarDetector:
+		return bazaarDetectorString
 	default:
 		return ""
 	}
@@ -104,14 +126,29 @@ type (
 
 	// Config contains project detection configurations.
 	Config struct {
+		// Cache is the detection cache consulted before detecting. Optional.
+		Cache *cache.Cache
 		// HideProjectNames determines if the project name should be obfuscated by matching its path.
 		HideProjectNames []regex.Regex
 		// Patterns contains the overridden project name per path.
 		MapPatterns []MapPattern
+		// PreferredGitRemote is the name of the git remote used as project name when
+		// ProjectFromGitRemote is enabled. Defaults to origin.
+		PreferredGitRemote string
 		// ProjectFromGitRemote when enabled uses the git remote as the project name instead of local git folder.
 		ProjectFromGitRemote bool
+		// Pseudonymizer, if set, replaces hidden project names with stable tokens instead of
+		// writing a random project name to a .wakatime-project file.
+		Pseudonymizer heartbeat.Pseudonymizer
+		// RemotePool shares connections to remote hosts with other stages of the
+		// pipeline and is closed by the caller. If not set, a pool is created and
+		// closed per batch.
+		RemotePool *remote.Pool
 		// Submodule contains the submodule configurations.
 		Submodule Submodule
+		// Subprojects contains the patterns of monorepo sub-project folders.
+		// Sub-project detection is disabled, if empty.
+		Subprojects []SubprojectPattern
 	}
 
 	// MapPattern contains the project name and regular expression for a specific path.
@@ -139,68 +176,26 @@ type (
 func WithDetection(config Config) heartbeat.HandleOption {
 	return func(next heartbeat.Handle) heartbeat.Handle {
 		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			// connections to remote hosts are shared by the heartbeats of a batch
+			pool := config.RemotePool
+			if pool == nil {
+				pool = remote.NewPool()
+			}
+
 			for n, h := range hh {
 				log.Debugln("execute project detection for:", h.Entity)
 
-				// first, use .wakatime-project or [projectmap] section with entity path.
-				// Then, detect with project folder. This tries to use the same project name
-				// across all IDEs instead of sometimes using alternate project when file is unsaved
-				result, detector := Detect(config.MapPatterns,
-					DetecterArg{Filepath: h.Entity, ShouldRun: h.EntityType == heartbeat.FileType},
-					DetecterArg{Filepath: h.ProjectPathOverride, ShouldRun: true},
-				)
-
-				// second, use project override
-				if result.Project == "" && h.ProjectOverride != "" {
-					result.Project = h.ProjectOverride
-					result.Folder = h.ProjectPathOverride
-				}
-
-				// third, autodetect with revision control with entity path.
-				// Then, autodetect with project folder. This tries to use the same project name
-				// across all IDEs instead of sometimes using alternate project when file is unsaved
-				if result.Project == "" || result.Branch == "" || result.Folder == "" {
-					revControlResult := DetectWithRevControl(
-						config.Submodule.DisabledPatterns,
-						config.Submodule.MapPatterns,
-						config.ProjectFromGitRemote,
-						DetecterArg{Filepath: h.Entity, ShouldRun: h.EntityType == heartbeat.FileType},
-						DetecterArg{Filepath: h.ProjectPathOverride, ShouldRun: true},
-					)
-
-					result.Project = firstNonEmptyString(result.Project, revControlResult.Project)
-					result.Branch = firstNonEmptyString(result.Branch, revControlResult.Branch)
-					result.Folder = firstNonEmptyString(result.Folder, revControlResult.Folder)
+				d := detectWithCache(h, config, pool)
+				if d.Category != nil {
+					hh[n].Category = *d.Category
+					h.Explanation.Add("project", "category %q set by .wakatime-project file", *d.Category)
 				}
 
-				// fourth, use alternate project
-				if result.Project == "" && h.ProjectAlternate != "" {
-					result.Project = h.ProjectAlternate
-					result.Folder = firstNonEmptyString(h.ProjectPathOverride, result.Folder)
-				}
+				hh[n].HideFileNames = hh[n].HideFileNames || d.HideFileNames
 
-				// fifth, use alternate branch
-				if result.Branch == "" && h.BranchAlternate != "" {
-					result.Branch = h.BranchAlternate
-				}
-
-				// sixth, use project folder found or entity's path
-				result.Folder = firstNonEmptyString(result.Folder, h.ProjectPathOverride)
-
-				// seventh, if no folder is found, use entity's directory
-				if h.EntityType == heartbeat.FileType && result.Folder == "" {
-					result.Folder = filepath.Dir(h.Entity)
-				}
-
-				if runtime.GOOS == "windows" && result.Folder != "" {
-					result.Folder = windows.FormatFilePath(result.Folder)
-				}
+				explainDetection(h.Explanation, d)
 
-				// finally, obfuscate project name if necessary
-				if heartbeat.ShouldSanitize(result.Folder, config.HideProjectNames) &&
-					result.Project != "" && detector != FileDetector {
-					result.Project = obfuscateProjectName(result.Folder)
-				}
+				result := d.Result
 
 				result.Folder = FormatProjectFolder(result.Folder)
 
@@ -217,11 +212,169 @@ func WithDetection(config Config) heartbeat.HandleOption {
 				hh[n].ProjectPath = result.Folder
 			}
 
+			if config.RemotePool == nil {
+				pool.Close()
+			}
+
 			return next(hh)
 		}
 	}
 }
 
+// detect finds the project of a heartbeat, before formatting its folder.
+// Remote files are detected on their host, using the connections of pool.
+func detect(h heartbeat.Heartbeat, config Config, pool *remote.Pool) detection {
+	// first, use .wakatime-project or [projectmap] section with entity path.
+	// Then, detect with project folder. This tries to use the same project name
+	// across all IDEs instead of sometimes using alternate project when file is unsaved
+	result, detector := Detect(config.MapPatterns,
+		DetecterArg{Filepath: h.Entity, ShouldRun: h.EntityType == heartbeat.FileType},
+		DetecterArg{Filepath: h.ProjectPathOverride, ShouldRun: true},
+	)
+
+	var source string
+	if result.Project != "" {
+		source = detector.String()
+	}
+
+	// remote files don't exist locally, so use .wakatime-project file or
+	// revision control repository on the remote host
+	var remoteFileConfig *FileConfig
+
+	if result.Project == "" && h.IsRemote() {
+		result, detector, remoteFileConfig = detectRemote(h.Entity, pool, config)
+		if result.Project != "" {
+			source = detector.String() + " on remote host"
+		}
+	}
+
+	// second, use project override
+	if result.Project == "" && h.ProjectOverride != "" {
+		result.Project = h.ProjectOverride
+		result.Folder = h.ProjectPathOverride
+		source = "project override"
+	}
+
+	var (
+		revControlResult       Result
+		revControlDetector     DetectorID
+		projectFromRevControl  = result.Project == ""
+		detectSubprojectInFile = len(config.Subprojects) > 0 && detector == FileDetector
+	)
+
+	// third, autodetect with revision control with entity path.
+	// Then, autodetect with project folder. This tries to use the same project name
+	// across all IDEs instead of sometimes using alternate project when file is unsaved.
+	// Sub-project detection needs the repository root, also when a .wakatime-project file was found
+	if !h.IsRemote() && (result.Project == "" || result.Branch == "" || result.Folder == "" || detectSubprojectInFile) {
+		revControlResult, revControlDetector = detectWithRevControl(
+			config.Submodule.DisabledPatterns,
+			config.Submodule.MapPatterns,
+			config.ProjectFromGitRemote,
+			config.PreferredGitRemote,
+			DetecterArg{Filepath: h.Entity, ShouldRun: h.EntityType == heartbeat.FileType},
+			DetecterArg{Filepath: h.ProjectPathOverride, ShouldRun: true},
+		)
+
+		if result.Project == "" && revControlResult.Project != "" {
+			source = revControlDetector.String()
+		}
+
+		result.Project = firstNonEmptyString(result.Project, revControlResult.Project)
+		result.Branch = firstNonEmptyString(result.Branch, revControlResult.Branch)
+		result.Folder = firstNonEmptyString(result.Folder, revControlResult.Folder)
+	}
+
+	// then, detect monorepo sub-project between entity and repository root
+	if h.EntityType == heartbeat.FileType && revControlResult.Folder != "" &&
+		(projectFromRevControl || detectSubprojectInFile && isSubfolder(result.Folder, revControlResult.Folder)) {
+		repo := revControlResult.Project
+
+		// a .wakatime-project file in the repository root renames the repository
+		if detector == FileDetector && result.Folder == revControlResult.Folder {
+			repo = result.Project
+		}
+
+		if subproject, ok := DetectSubproject(h.Entity, revControlResult.Folder, repo, config.Subprojects); ok {
+			result.Project = subproject.Project
+			result.Folder = subproject.Folder
+			source = fmt.Sprintf("sub-project detection in %s repository", revControlDetector)
+		}
+	}
+
+	// fourth, use alternate project
+	if result.Project == "" && h.ProjectAlternate != "" {
+		result.Project = h.ProjectAlternate
+		result.Folder = firstNonEmptyString(h.ProjectPathOverride, result.Folder)
+		source = "alternate project"
+	}
+
+	// fifth, use alternate branch
+	if result.Branch == "" && h.BranchAlternate != "" {
+		result.Branch = h.BranchAlternate
+	}
+
+	// sixth, use project folder found or entity's path
+	result.Folder = firstNonEmptyString(result.Folder, h.ProjectPathOverride)
+
+	// seventh, if no folder is found, use entity's directory
+	if h.EntityType == heartbeat.FileType && result.Folder == "" {
+		result.Folder = filepath.Dir(h.Entity)
+	}
+
+	if runtime.GOOS == "windows" && result.Folder != "" {
+		result.Folder = windows.FormatFilePath(result.Folder)
+	}
+
+	var d detection
+
+	// category and file name hiding of a .wakatime-project file
+	if fileConfig, ok := findFileConfig(
+		DetecterArg{Filepath: h.Entity, ShouldRun: h.EntityType == heartbeat.FileType},
+		DetecterArg{Filepath: h.ProjectPathOverride, ShouldRun: true},
+	); ok {
+		d.Category = fileConfig.Category
+		d.HideFileNames = fileConfig.HideFileNames
+	}
+
+	if remoteFileConfig != nil {
+		d.Category = remoteFileConfig.Category
+		d.HideFileNames = remoteFileConfig.HideFileNames
+	}
+
+	// finally, obfuscate project name if necessary
+	if heartbeat.ShouldSanitize(result.Folder, config.HideProjectNames) &&
+		result.Project != "" && detector != FileDetector {
+		if config.Pseudonymizer != nil {
+			result.Project = config.Pseudonymizer.Pseudonymize(result.Project)
+			source = "pseudonymization, as the folder matches hide_project_names"
+		} else {
+			result.Project = obfuscateProjectName(result.Folder)
+			source = "obfuscation, as the folder matches hide_project_names"
+		}
+	}
+
+	d.Result = result
+	d.Source = source
+
+	return d
+}
+
+// explainDetection records the project detection result.
+func explainDetection(e *heartbeat.Explanation, d detection) {
+	if d.Result.Project == "" {
+		e.Add("project", "no project detected")
+		return
+	}
+
+	var cached string
+	if d.cached {
+		cached = " (from detection cache)"
+	}
+
+	e.Add("project", "project %q with branch %q detected by %s%s", d.Result.Project, d.Result.Branch, d.Source, cached)
+}
+
 // Detect finds the current project and branch from config plugins.
 func Detect(patterns []MapPattern, args ...DetecterArg) (Result, DetectorID) {
 	for _, arg := range args {
@@ -257,20 +410,66 @@ func Detect(patterns []MapPattern, args ...DetecterArg) (Result, DetectorID) {
 	return Result{}, UnknownDetector
 }
 
+// findFileConfig returns the settings of the first .wakatime-project file found.
+func findFileConfig(args ...DetecterArg) (FileConfig, bool) {
+	for _, arg := range args {
+		if !arg.ShouldRun || arg.Filepath == "" {
+			continue
+		}
+
+		config, ok, err := ReadFileConfig(arg.Filepath)
+		if err != nil {
+			log.Warnf("failed to read .wakatime-project file: %s", err)
+			continue
+		}
+
+		if ok {
+			return config, true
+		}
+	}
+
+	return FileConfig{}, false
+}
+
 // DetectWithRevControl finds the current project and branch from rev control.
 func DetectWithRevControl(
 	submoduleDisabledPatterns []regex.Regex,
 	submoduleProjectMapPatterns []MapPattern,
 	projectFromGitRemote bool,
+	preferredGitRemote string,
 	args ...DetecterArg) Result {
+	result, _ := detectWithRevControl(
+		submoduleDisabledPatterns,
+		submoduleProjectMapPatterns,
+		projectFromGitRemote,
+		preferredGitRemote,
+		args...,
+	)
+
+	return result
+}
+
+// detectWithRevControl finds the current project and branch from rev control
+// and returns the id of the detector, which found them.
+func detectWithRevControl(
+	submoduleDisabledPatterns []regex.Regex,
+	submoduleProjectMapPatterns []MapPattern,
+	projectFromGitRemote bool,
+	preferredGitRemote string,
+	args ...DetecterArg) (Result, DetectorID) {
 	for _, arg := range args {
 		if !arg.ShouldRun || arg.Filepath == "" {
 			continue
 		}
 
 		var revControlPlugins = []Detecter{
+			// jujutsu runs before git, as its repositories are usually colocated with git
+			Jujutsu{
+				Filepath: arg.Filepath,
+			},
 			Git{
 				Filepath:                    arg.Filepath,
+				PreferredRemote:             preferredGitRemote,
 				ProjectFromGitRemote:        projectFromGitRemote,
 				SubmoduleDisabledPatterns:   submoduleDisabledPatterns,
 				SubmoduleProjectMapPatterns: submoduleProjectMapPatterns,
@@ -284,6 +483,15 @@ func DetectWithRevControl(
 			Tfvc{
 				Filepath: arg.Filepath,
 			},
+			Fossil{
+				Filepath: arg.Filepath,
+			},
+			Pijul{
+				Filepath: arg.Filepath,
+			},
+			Bazaar{
+				Filepath: arg.Filepath,
+			},
 		}
 
 		for _, p := range revControlPlugins {
@@ -300,12 +508,12 @@ func DetectWithRevControl(
 					Project: result.Project,
 					Branch:  result.Branch,
 					Folder:  result.Folder,
-				}
+				}, p.ID()
 			}
 		}
 	}
 
-	return Result{}
+	return Result{}, UnknownDetector
 }
 
 func obfuscateProjectName(folder string) string {
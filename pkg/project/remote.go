@@ -0,0 +1,332 @@
+package project
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/remote"
+)
+
+const (
+	// maxRemoteFileSize is the maximum number of bytes read from a file on a remote host.
+	maxRemoteFileSize = 65536
+	// maxRemoteLooseRefs is the maximum number of loose refs read from a remote host.
+	maxRemoteLooseRefs = 100
+)
+
+// remoteFS reads files on the host of a remote entity.
+type remoteFS interface {
+	Stat(fp string) (os.FileInfo, error)
+	ReadDir(fp string) ([]os.FileInfo, error)
+	ReadFile(fp string) ([]byte, error)
+}
+
+// remoteHost reads files on the host of a remote client, sharing the
+// connections of a pool.
+type remoteHost struct {
+	client remote.Client
+	pool   *remote.Pool
+}
+
+// Stat implements remoteFS interface.
+func (r remoteHost) Stat(fp string) (os.FileInfo, error) {
+	return r.pool.Stat(r.client, fp)
+}
+
+// ReadDir implements remoteFS interface.
+func (r remoteHost) ReadDir(fp string) ([]os.FileInfo, error) {
+	return r.pool.ReadDir(r.client, fp)
+}
+
+// ReadFile implements remoteFS interface.
+func (r remoteHost) ReadFile(fp string) ([]byte, error) {
+	return r.pool.ReadFile(r.client, fp, maxRemoteFileSize)
+}
+
+// detectRemote finds the project and branch of a remote file on its host.
+// Parent folders are searched for a .wakatime-project file and a git
+// repository. The settings of a .wakatime-project file are returned as well.
+func detectRemote(entity string, pool *remote.Pool, config Config) (Result, DetectorID, *FileConfig) {
+	c, err := remote.NewClient(entity)
+	if err != nil {
+		log.Warnf("failed to create new remote client: %s", err)
+		return Result{}, UnknownDetector, nil
+	}
+
+	return detectOnRemoteFS(remoteHost{client: c, pool: pool}, c.Path, config)
+}
+
+// detectOnRemoteFS finds the project and branch of a file on a remote
+// filesystem. A .wakatime-project file takes precedence over the git
+// repository, which is used for the branch, if not set by the file.
+func detectOnRemoteFS(fs remoteFS, fp string, config Config) (Result, DetectorID, *FileConfig) {
+	var fileConfig *FileConfig
+
+	folder := path.Dir(fp)
+
+	for i := 0; i < maxRecursiveIteration; i++ {
+		if fileConfig == nil {
+			fileConfig = readRemoteFileConfig(fs, folder)
+			if fileConfig != nil && fileConfig.Branch != "" {
+				return Result{
+					Project: fileConfig.Project,
+					Branch:  fileConfig.Branch,
+					Folder:  fileConfig.Folder,
+				}, FileDetector, fileConfig
+			}
+		}
+
+		if result, ok := detectRemoteGit(fs, folder, config); ok {
+			if fileConfig == nil {
+				return result, GitDetector, nil
+			}
+
+			return Result{
+				Project: fileConfig.Project,
+				Branch:  result.Branch,
+				Folder:  fileConfig.Folder,
+			}, FileDetector, fileConfig
+		}
+
+		parent := path.Dir(folder)
+		if parent == folder {
+			break
+		}
+
+		folder = parent
+	}
+
+	if fileConfig != nil {
+		return Result{
+			Project: fileConfig.Project,
+			Folder:  fileConfig.Folder,
+		}, FileDetector, fileConfig
+	}
+
+	return Result{}, UnknownDetector, nil
+}
+
+// readRemoteFileConfig reads the .wakatime-project file in a remote folder.
+// Returns nil, if there is none.
+func readRemoteFileConfig(fs remoteFS, folder string) *FileConfig {
+	fp := path.Join(folder, WakaTimeProjectFile)
+
+	data, err := fs.ReadFile(fp)
+	if err != nil {
+		return nil
+	}
+
+	log.Debugf("wakatime project file found on remote host at: %s", fp)
+
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r", ""), "\n")
+	if len(lines) > maxProjectFileLines {
+		lines = lines[:maxProjectFileLines]
+	}
+
+	config, err := parseFileConfig(lines, folder, path.Base(folder))
+	if err != nil {
+		log.Warnf("failed to parse remote .wakatime-project file %q: %s", fp, err)
+		return nil
+	}
+
+	return &config
+}
+
+// detectRemoteGit reads the git repository in a remote folder. Git folders
+// and .git files pointing to the git folder of a worktree or submodule are
+// supported. Like for local repositories, a detached HEAD is named by a tag
+// pointing to the commit, if any, or the short commit hash otherwise.
+func detectRemoteGit(fs remoteFS, folder string, config Config) (Result, bool) {
+	dotGit := path.Join(folder, ".git")
+
+	info, err := fs.Stat(dotGit)
+	if err != nil {
+		return Result{}, false
+	}
+
+	gitdir := dotGit
+
+	if !info.IsDir() {
+		data, err := fs.ReadFile(dotGit)
+		if err != nil {
+			log.Warnf("failed to read remote .git file %q: %s", dotGit, err)
+			return Result{}, false
+		}
+
+		line, _, _ := strings.Cut(string(data), "\n")
+		if !strings.HasPrefix(line, "gitdir: ") {
+			return Result{}, false
+		}
+
+		gitdir = strings.TrimSpace(strings.TrimPrefix(line, "gitdir: "))
+		if !path.IsAbs(gitdir) {
+			gitdir = path.Join(folder, gitdir)
+		}
+	}
+
+	branch := "master"
+
+	if data, err := fs.ReadFile(path.Join(gitdir, "HEAD")); err == nil {
+		line, _, _ := strings.Cut(string(data), "\n")
+
+		b, hash, ok := parseGitHead(line)
+		if !ok {
+			log.Warnf("invalid branch from remote %q: %s", path.Join(gitdir, "HEAD"), line)
+		}
+
+		branch = b
+		if hash != "" {
+			branch = hash[:shortGitHashLength]
+			if tag := findRemoteGitTag(fs, remoteCommondir(fs, gitdir), hash); tag != "" {
+				branch = tag
+			}
+		}
+	}
+
+	project := path.Base(folder)
+
+	if config.ProjectFromGitRemote {
+		if gitRemote := findRemoteGitRemote(fs, gitdir, branch, config.PreferredGitRemote); gitRemote != "" {
+			project = gitRemote
+		}
+	}
+
+	return Result{
+		Project: project,
+		Branch:  branch,
+		Folder:  folder,
+	}, true
+}
+
+// findRemoteGitRemote returns the repository path of the preferred git remote
+// from the config of a remote git folder. The config of the main repository
+// is read for worktrees. Included config files are ignored.
+func findRemoteGitRemote(fs remoteFS, gitdir, branch, preferred string) string {
+	commondir := remoteCommondir(fs, gitdir)
+
+	fp := path.Join(commondir, "config")
+
+	data, err := fs.ReadFile(fp)
+	if err != nil {
+		log.Debugf("failed to read remote git config %q: %s", fp, err)
+		return ""
+	}
+
+	cfg := &gitConfig{
+		values:       make(map[string][]string),
+		branch:       branch,
+		gitdir:       gitdir,
+		skipIncludes: true,
+	}
+
+	if err := cfg.parse(fp, bytes.NewReader(data), 0); err != nil {
+		log.Errorf("error reading remote git config from %q: %s", fp, err)
+		return ""
+	}
+
+	gitRemote, err := findGitRemote(cfg, preferred)
+	if err != nil {
+		log.Errorf("error finding git remote from remote %q: %s", commondir, err)
+		return ""
+	}
+
+	return gitRemote
+}
+
+// remoteCommondir returns the common git folder of a remote git folder, which
+// differs for worktrees.
+func remoteCommondir(fs remoteFS, gitdir string) string {
+	data, err := fs.ReadFile(path.Join(gitdir, "commondir"))
+	if err != nil {
+		return gitdir
+	}
+
+	line, _, _ := strings.Cut(string(data), "\n")
+	if line = strings.TrimSpace(line); line == "" {
+		return gitdir
+	}
+
+	if !path.IsAbs(line) {
+		return path.Join(gitdir, line)
+	}
+
+	return line
+}
+
+// findRemoteGitTag returns the name of a tag pointing to the commit with the
+// passed in hash in a remote git folder, like findGitTag does for local ones.
+// At most maxRemoteLooseRefs loose tags are read.
+func findRemoteGitTag(fs remoteFS, commondir, hash string) string {
+	refs := make(map[string]string)
+
+	if data, err := fs.ReadFile(path.Join(commondir, "packed-refs")); err == nil {
+		refs, err = parsePackedRefs(bytes.NewReader(data), "refs/tags/")
+		if err != nil {
+			log.Debugf("failed to parse remote packed-refs in %q: %s", commondir, err)
+		}
+	}
+
+	var (
+		count int
+		walk  func(dir, prefix string)
+	)
+
+	walk = func(dir, prefix string) {
+		entries, err := fs.ReadDir(dir)
+		if err != nil {
+			return
+		}
+
+		for _, entry := range entries {
+			if count >= maxRemoteLooseRefs {
+				return
+			}
+
+			fp := path.Join(dir, entry.Name())
+
+			if entry.IsDir() {
+				walk(fp, prefix+entry.Name()+"/")
+				continue
+			}
+
+			count++
+
+			data, err := fs.ReadFile(fp)
+			if err != nil {
+				continue
+			}
+
+			line, _, _ := strings.Cut(string(data), "\n")
+
+			refs[prefix+entry.Name()] = peelRemoteGitTag(fs, commondir, strings.TrimSpace(line))
+		}
+	}
+
+	walk(path.Join(commondir, "refs", "tags"), "")
+
+	return matchGitRef(refs, hash)
+}
+
+// peelRemoteGitTag returns the hash of the object an annotated tag points to
+// in a remote git folder. The passed in hash is returned, if it is no loose
+// tag object.
+func peelRemoteGitTag(fs remoteFS, commondir, hash string) string {
+	for i := 0; i < maxGitTagPeelDepth && isGitHash(hash); i++ {
+		data, err := fs.ReadFile(path.Join(commondir, "objects", hash[:2], hash[2:]))
+		if err != nil {
+			return hash
+		}
+
+		target, err := parseGitTagObject(bytes.NewReader(data))
+		if err != nil || target == "" {
+			return hash
+		}
+
+		hash = target
+	}
+
+	return hash
+}
@@ -0,0 +1,223 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectOnRemoteFS_Git(t *testing.T) {
+	root := filepath.ToSlash(t.TempDir())
+
+	writeRemoteTestFile(t, root+"/wakatime-cli/.git/HEAD", "ref: refs/heads/feature/remote\n")
+	writeRemoteTestFile(t, root+"/wakatime-cli/cmd/main.go", "package main\n")
+
+	result, detector, fileConfig := detectOnRemoteFS(localRemoteFS{}, root+"/wakatime-cli/cmd/main.go", Config{})
+
+	assert.Equal(t, Result{
+		Project: "wakatime-cli",
+		Branch:  "feature/remote",
+		Folder:  root + "/wakatime-cli",
+	}, result)
+	assert.Equal(t, GitDetector, detector)
+	assert.Nil(t, fileConfig)
+}
+
+func TestDetectOnRemoteFS_GitDetachedHead(t *testing.T) {
+	root := filepath.ToSlash(t.TempDir())
+
+	writeRemoteTestFile(t, root+"/wakatime-cli/.git/HEAD", "9a8dfbbe4f3a5a8e6d7f2f4b0e1c6c0d9e8f7a6b\n")
+	writeRemoteTestFile(t, root+"/wakatime-cli/main.go", "package main\n")
+
+	result, _, _ := detectOnRemoteFS(localRemoteFS{}, root+"/wakatime-cli/main.go", Config{})
+
+	assert.Equal(t, "9a8dfbb", result.Branch)
+}
+
+func TestDetectOnRemoteFS_GitDetachedHead_Tag(t *testing.T) {
+	tests := map[string]struct {
+		Head     string
+		Expected string
+	}{
+		"annotated packed tag": {
+			Head:     "HEAD_ANNOTATED_PACKED",
+			Expected: "v1.0.0",
+		},
+		"lightweight packed tag": {
+			Head:     "HEAD_LIGHTWEIGHT_PACKED",
+			Expected: "v1.1.0",
+		},
+		"annotated loose tag": {
+			Head:     "HEAD_ANNOTATED_LOOSE",
+			Expected: "v2.0.0",
+		},
+		"lightweight loose tag": {
+			Head:     "HEAD_LIGHTWEIGHT_LOOSE",
+			Expected: "v2.1.0",
+		},
+		"untagged": {
+			Head:     "HEAD_UNTAGGED",
+			Expected: "ef80be7",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			root := filepath.ToSlash(t.TempDir())
+			gitdir := root + "/wakatime-cli/.git"
+
+			for src, dst := range map[string]string{
+				test.Head:          "HEAD",
+				"packed-refs":      "packed-refs",
+				"refs/tags/v2.0.0": "refs/tags/v2.0.0",
+				"refs/tags/v2.1.0": "refs/tags/v2.1.0",
+				"objects/aa/017f277a2fc929ac52280b4db713dc86182b25": "objects/aa/017f277a2fc929ac52280b4db713dc86182b25",
+			} {
+				data, err := os.ReadFile(filepath.Join("testdata/git_detached", filepath.FromSlash(src)))
+				require.NoError(t, err)
+
+				writeRemoteTestFile(t, gitdir+"/"+dst, string(data))
+			}
+
+			writeRemoteTestFile(t, root+"/wakatime-cli/main.go", "package main\n")
+
+			result, _, _ := detectOnRemoteFS(localRemoteFS{}, root+"/wakatime-cli/main.go", Config{})
+
+			assert.Equal(t, test.Expected, result.Branch)
+		})
+	}
+}
+
+func TestDetectOnRemoteFS_GitFile(t *testing.T) {
+	root := filepath.ToSlash(t.TempDir())
+
+	writeRemoteTestFile(t, root+"/repo/.git/worktrees/feature/HEAD", "ref: refs/heads/feature\n")
+	writeRemoteTestFile(t, root+"/feature/.git", "gitdir: ../repo/.git/worktrees/feature\n")
+	writeRemoteTestFile(t, root+"/feature/main.go", "package main\n")
+
+	result, detector, _ := detectOnRemoteFS(localRemoteFS{}, root+"/feature/main.go", Config{})
+
+	assert.Equal(t, Result{
+		Project: "feature",
+		Branch:  "feature",
+		Folder:  root + "/feature",
+	}, result)
+	assert.Equal(t, GitDetector, detector)
+}
+
+func TestDetectOnRemoteFS_ProjectFromGitRemote(t *testing.T) {
+	root := filepath.ToSlash(t.TempDir())
+
+	writeRemoteTestFile(t, root+"/repo/.git/HEAD", "ref: refs/heads/master\n")
+	writeRemoteTestFile(t, root+"/repo/.git/config", `[include]
+	path = ~/.gitconfig-work
+[remote "origin"]
+	url = git@github.com:wakatime/wakatime-cli.git
+[remote "upstream"]
+	url = https://github.com/upstream/wakatime-cli.git
+`)
+	writeRemoteTestFile(t, root+"/repo/main.go", "package main\n")
+
+	result, _, _ := detectOnRemoteFS(localRemoteFS{}, root+"/repo/main.go", Config{
+		ProjectFromGitRemote: true,
+		PreferredGitRemote:   "upstream",
+	})
+
+	assert.Equal(t, "upstream/wakatime-cli", result.Project)
+}
+
+func TestDetectOnRemoteFS_ProjectFile(t *testing.T) {
+	root := filepath.ToSlash(t.TempDir())
+
+	writeRemoteTestFile(t, root+"/repo/.git/HEAD", "ref: refs/heads/master\n")
+	writeRemoteTestFile(t, root+"/repo/service/.wakatime-project", "my-service\n")
+	writeRemoteTestFile(t, root+"/repo/service/main.go", "package main\n")
+
+	result, detector, fileConfig := detectOnRemoteFS(localRemoteFS{}, root+"/repo/service/main.go", Config{})
+
+	assert.Equal(t, Result{
+		Project: "my-service",
+		Branch:  "master",
+		Folder:  root + "/repo/service",
+	}, result)
+	assert.Equal(t, FileDetector, detector)
+	require.NotNil(t, fileConfig)
+	assert.Equal(t, "my-service", fileConfig.Project)
+}
+
+func TestDetectOnRemoteFS_ProjectFile_Keys(t *testing.T) {
+	root := filepath.ToSlash(t.TempDir())
+
+	writeRemoteTestFile(
+		t,
+		root+"/project/.wakatime-project",
+		"project = my-project\nbranch = my-branch\ncategory = debugging\n",
+	)
+	writeRemoteTestFile(t, root+"/project/main.go", "package main\n")
+
+	result, detector, fileConfig := detectOnRemoteFS(localRemoteFS{}, root+"/project/main.go", Config{})
+
+	assert.Equal(t, Result{
+		Project: "my-project",
+		Branch:  "my-branch",
+		Folder:  root + "/project",
+	}, result)
+	assert.Equal(t, FileDetector, detector)
+	require.NotNil(t, fileConfig)
+	require.NotNil(t, fileConfig.Category)
+	assert.Equal(t, "debugging", fileConfig.Category.String())
+}
+
+func TestDetectOnRemoteFS_NotFound(t *testing.T) {
+	root := filepath.ToSlash(t.TempDir())
+
+	writeRemoteTestFile(t, root+"/project/main.go", "package main\n")
+
+	result, detector, fileConfig := detectOnRemoteFS(localRemoteFS{}, root+"/project/main.go", Config{})
+
+	assert.Equal(t, Result{}, result)
+	assert.Equal(t, UnknownDetector, detector)
+	assert.Nil(t, fileConfig)
+}
+
+// localRemoteFS reads local files in place of a remote host.
+type localRemoteFS struct{}
+
+func (localRemoteFS) Stat(fp string) (os.FileInfo, error) {
+	return os.Stat(filepath.FromSlash(fp))
+}
+
+func (localRemoteFS) ReadDir(fp string) ([]os.FileInfo, error) {
+	entries, err := os.ReadDir(filepath.FromSlash(fp))
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]os.FileInfo, 0, len(entries))
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+func (localRemoteFS) ReadFile(fp string) ([]byte, error) {
+	return os.ReadFile(filepath.FromSlash(fp))
+}
+
+func writeRemoteTestFile(t *testing.T, fp, content string) {
+	err := os.MkdirAll(filepath.Dir(filepath.FromSlash(fp)), 0750)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.FromSlash(fp), []byte(content), 0600)
+	require.NoError(t, err)
+}
@@ -0,0 +1,202 @@
+package project
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// defaultSubprojectName is the project name template used, if a sub-project
+// pattern has no template.
+const defaultSubprojectName = "{repo}/{name}"
+
+// SubprojectPattern contains the project name template for sub-projects in
+// folders matching a glob pattern.
+type SubprojectPattern struct {
+	// Glob is matched against the sub-project folder relative to the repository
+	// root. Supported are *, ** and ?. Matching is case insensitive.
+	Glob string
+	// Name is the project name template. {repo} is replaced with the repository
+	// project name, {name} with the sub-project name and {path} with the
+	// sub-project folder relative to the repository root.
+	Name string
+}
+
+// Subproject contains a detected monorepo sub-project.
+type Subproject struct {
+	// Folder is the sub-project folder.
+	Folder string
+	// Project is the project name.
+	Project string
+}
+
+// DetectSubproject finds the sub-project of a file inside a repository. A folder
+// between the file and the repository root is a sub-project, if it contains a
+// marker and matches a pattern. Markers are a go.mod file, a package.json file
+// with name, a Cargo.toml file of a workspace member or a .wakatime-project file.
+// The nearest matching folder is used. The sub-project name is the project
+// name of a .wakatime-project file or the folder name otherwise.
+func DetectSubproject(fp, root, repo string, patterns []SubprojectPattern) (Subproject, bool) {
+	if len(patterns) == 0 || root == "" {
+		return Subproject{}, false
+	}
+
+	if !isSubfolder(fp, root) {
+		return Subproject{}, false
+	}
+
+	dir := filepath.Dir(fp)
+
+	for i := 0; i < maxRecursiveIteration && dir != root && !isRootPath(dir); i++ {
+		subpath, err := filepath.Rel(root, dir)
+		if err != nil {
+			return Subproject{}, false
+		}
+
+		subpath = filepath.ToSlash(subpath)
+
+		if name, ok := subprojectMarker(dir, root); ok {
+			for _, pattern := range patterns {
+				if !matchGitGlob(pattern.Glob, subpath, true) {
+					continue
+				}
+
+				log.Debugf("sub-project pattern %q matched folder %q", pattern.Glob, subpath)
+
+				template := pattern.Name
+				if template == "" {
+					template = defaultSubprojectName
+				}
+
+				return Subproject{
+					Folder: dir,
+					Project: strings.NewReplacer(
+						"{repo}", repo,
+						"{name}", name,
+						"{path}", subpath,
+					).Replace(template),
+				}, true
+			}
+		}
+
+		dir = filepath.Dir(dir)
+	}
+
+	return Subproject{}, false
+}
+
+// subprojectMarker checks if a folder contains a sub-project marker and
+// returns the sub-project name.
+func subprojectMarker(dir, root string) (string, bool) {
+	if fileOrDirExists(filepath.Join(dir, WakaTimeProjectFile)) {
+		config, ok, err := ReadFileConfig(dir)
+		if err != nil {
+			log.Warnf("failed to read .wakatime-project file: %s", err)
+		}
+
+		if ok && config.Folder == dir {
+			return config.Project, true
+		}
+	}
+
+	name := filepath.Base(dir)
+
+	if fileOrDirExists(filepath.Join(dir, "go.mod")) {
+		return name, true
+	}
+
+	if hasPackageJSONName(filepath.Join(dir, "package.json")) {
+		return name, true
+	}
+
+	if fileOrDirExists(filepath.Join(dir, "Cargo.toml")) && isCargoWorkspaceMember(dir, root) {
+		return name, true
+	}
+
+	return "", false
+}
+
+// hasPackageJSONName returns true if the package.json file sets a name.
+func hasPackageJSONName(fp string) bool {
+	if !fileOrDirExists(fp) {
+		return false
+	}
+
+	data, err := os.ReadFile(fp) // nolint:gosec
+	if err != nil {
+		log.Debugf("failed to read file %q: %s", fp, err)
+		return false
+	}
+
+	var pkg struct {
+		Name string `json:"name"`
+	}
+
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		log.Debugf("failed to parse file %q: %s", fp, err)
+		return false
+	}
+
+	return pkg.Name != ""
+}
+
+// isCargoWorkspaceMember returns true if the folder matches the members of a
+// cargo workspace in one of its parent folders up to the repository root.
+func isCargoWorkspaceMember(dir, root string) bool {
+	parent := dir
+
+	for parent != root && !isRootPath(parent) {
+		parent = filepath.Dir(parent)
+
+		fp := filepath.Join(parent, "Cargo.toml")
+		if !fileOrDirExists(fp) {
+			continue
+		}
+
+		data, err := os.ReadFile(fp) // nolint:gosec
+		if err != nil {
+			log.Debugf("failed to read file %q: %s", fp, err)
+			continue
+		}
+
+		var manifest struct {
+			Workspace struct {
+				Members []string `toml:"members"`
+			} `toml:"workspace"`
+		}
+
+		if err := toml.Unmarshal(data, &manifest); err != nil {
+			log.Debugf("failed to parse file %q: %s", fp, err)
+			continue
+		}
+
+		rel, err := filepath.Rel(parent, dir)
+		if err != nil {
+			return false
+		}
+
+		for _, member := range manifest.Workspace.Members {
+			if ok, _ := filepath.Match(filepath.FromSlash(member), rel); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isSubfolder returns true if the folder is the passed in parent folder or
+// inside of it.
+func isSubfolder(folder, parent string) bool {
+	rel, err := filepath.Rel(parent, folder)
+	if err != nil {
+		return false
+	}
+
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,176 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/project"
+
+	"github.com/gandarez/go-realpath"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectSubproject(t *testing.T) {
+	root := setupTestMonorepo(t)
+
+	tests := map[string]struct {
+		Entity   string
+		Patterns []project.SubprojectPattern
+		Expected project.Subproject
+	}{
+		"go module": {
+			Entity:   "services/billing/cmd/main.go",
+			Patterns: []project.SubprojectPattern{{Glob: "services/*"}},
+			Expected: project.Subproject{
+				Folder:  filepath.Join(root, "services", "billing"),
+				Project: "monorepo/billing",
+			},
+		},
+		"package json with name": {
+			Entity:   "packages/web/src/index.ts",
+			Patterns: []project.SubprojectPattern{{Glob: "packages/*", Name: "{path}"}},
+			Expected: project.Subproject{
+				Folder:  filepath.Join(root, "packages", "web"),
+				Project: "packages/web",
+			},
+		},
+		"cargo workspace member": {
+			Entity:   "crates/cli/src/main.rs",
+			Patterns: []project.SubprojectPattern{{Glob: "**"}},
+			Expected: project.Subproject{
+				Folder:  filepath.Join(root, "crates", "cli"),
+				Project: "monorepo/cli",
+			},
+		},
+		"wakatime project file": {
+			Entity:   "services/api/main.go",
+			Patterns: []project.SubprojectPattern{{Glob: "services/*", Name: "{repo}-{name}"}},
+			Expected: project.Subproject{
+				Folder:  filepath.Join(root, "services", "api"),
+				Project: "monorepo-billing-api",
+			},
+		},
+		"nearest marker not matching": {
+			Entity:   "packages/web/node_modules/dep/index.js",
+			Patterns: []project.SubprojectPattern{{Glob: "packages/*"}},
+			Expected: project.Subproject{
+				Folder:  filepath.Join(root, "packages", "web"),
+				Project: "monorepo/web",
+			},
+		},
+		"case insensitive glob": {
+			Entity:   "services/billing/cmd/main.go",
+			Patterns: []project.SubprojectPattern{{Glob: "SERVICES/*"}},
+			Expected: project.Subproject{
+				Folder:  filepath.Join(root, "services", "billing"),
+				Project: "monorepo/billing",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			subproject, ok := project.DetectSubproject(
+				filepath.Join(root, filepath.FromSlash(test.Entity)),
+				root,
+				"monorepo",
+				test.Patterns,
+			)
+			require.True(t, ok)
+
+			assert.Equal(t, test.Expected, subproject)
+		})
+	}
+}
+
+func TestDetectSubproject_NotDetected(t *testing.T) {
+	root := setupTestMonorepo(t)
+
+	tests := map[string]struct {
+		Entity   string
+		Patterns []project.SubprojectPattern
+	}{
+		"no patterns": {
+			Entity: "services/billing/cmd/main.go",
+		},
+		"glob not matching": {
+			Entity:   "services/billing/cmd/main.go",
+			Patterns: []project.SubprojectPattern{{Glob: "packages/*"}},
+		},
+		"package json without name": {
+			Entity:   "packages/docs/index.md",
+			Patterns: []project.SubprojectPattern{{Glob: "packages/*"}},
+		},
+		"cargo package outside of workspace": {
+			Entity:   "tools/release/src/main.rs",
+			Patterns: []project.SubprojectPattern{{Glob: "**"}},
+		},
+		"file in repository root": {
+			Entity:   "README.md",
+			Patterns: []project.SubprojectPattern{{Glob: "**"}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, ok := project.DetectSubproject(
+				filepath.Join(root, filepath.FromSlash(test.Entity)),
+				root,
+				"monorepo",
+				test.Patterns,
+			)
+
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestDetectSubproject_OutsideRepository(t *testing.T) {
+	root := setupTestMonorepo(t)
+
+	_, ok := project.DetectSubproject(
+		filepath.Join(root, "services", "billing", "cmd", "main.go"),
+		filepath.Join(root, "packages"),
+		"monorepo",
+		[]project.SubprojectPattern{{Glob: "**"}},
+	)
+
+	assert.False(t, ok)
+}
+
+func setupTestMonorepo(t *testing.T) (fp string) {
+	tmpDir, err := realpath.Realpath(t.TempDir())
+	require.NoError(t, err)
+
+	root := filepath.Join(tmpDir, "monorepo")
+
+	dirs := []string{
+		"services/billing/cmd",
+		"services/api",
+		"packages/web/src",
+		"packages/web/node_modules/dep",
+		"packages/docs",
+		"crates/cli/src",
+		"tools/release/src",
+	}
+
+	for _, dir := range dirs {
+		err = os.MkdirAll(filepath.Join(root, filepath.FromSlash(dir)), os.FileMode(int(0700)))
+		require.NoError(t, err)
+	}
+
+	err = os.WriteFile(filepath.Join(root, "services/billing/go.mod"), []byte("module example.com/billing\n"), 0600)
+	require.NoError(t, err)
+
+	copyFile(t, "testdata/monorepo/wakatime-project", filepath.Join(root, "services/api/.wakatime-project"))
+	copyFile(t, "testdata/monorepo/package.json", filepath.Join(root, "packages/web/package.json"))
+	copyFile(t, "testdata/monorepo/package.json", filepath.Join(root, "packages/web/node_modules/dep/package.json"))
+	copyFile(t, "testdata/monorepo/package_without_name.json", filepath.Join(root, "packages/docs/package.json"))
+	copyFile(t, "testdata/monorepo/Cargo.toml", filepath.Join(root, "Cargo.toml"))
+	copyFile(t, "testdata/monorepo/crate_Cargo.toml", filepath.Join(root, "crates/cli/Cargo.toml"))
+	copyFile(t, "testdata/monorepo/crate_Cargo.toml", filepath.Join(root, "tools/release/Cargo.toml"))
+
+	return root
+}
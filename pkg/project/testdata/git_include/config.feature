@@ -0,0 +1,2 @@
+[remote "origin"]
+	url = git@github.com:wakatime/feature.git
@@ -0,0 +1,231 @@
+package pseudonym
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/ini"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
+
+	"github.com/spf13/viper"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// dbFilename is the default bolt db filename.
+	dbFilename = "pseudonyms.bdb"
+	// dbBucket is the bolt bucket name.
+	dbBucket = "pseudonyms"
+	// openTimeout is the time to wait for the db file lock.
+	openTimeout = time.Second
+	// secretKey is the internal config key of the pseudonymization secret.
+	secretKey = "pseudonym_secret"
+	// secretSize is the size of generated secrets in bytes.
+	secretSize = 32
+	// tokenPrefix is prepended to every token.
+	tokenPrefix = "HIDDEN-"
+	// tokenSize is the size of the hmac prefix used in tokens in bytes.
+	tokenSize = 8
+)
+
+// tokenRegex matches tokens within a string.
+var tokenRegex = regexp.MustCompile(`HIDDEN-[0-9a-f]{16}`) // nolint:gochecknoglobals
+
+// Pseudonymizer replaces names with stable tokens, keyed by a local secret.
+// Tokens are recorded in a local db, so that they can be revealed later.
+type Pseudonymizer struct {
+	filepath string
+	secret   []byte
+
+	mu       sync.Mutex
+	recorded map[string]bool
+}
+
+// New creates a new Pseudonymizer recording tokens in the passed in db file.
+// Tokens are not recorded, if filepath is empty.
+func New(secret []byte, filepath string) *Pseudonymizer {
+	return &Pseudonymizer{
+		filepath: filepath,
+		secret:   secret,
+		recorded: make(map[string]bool),
+	}
+}
+
+// NewDefault creates a new Pseudonymizer recording tokens in the default db file.
+func NewDefault(secret []byte) *Pseudonymizer {
+	fp, err := Filepath()
+	if err != nil {
+		log.Warnf("failed to get pseudonyms filepath: %s", err)
+	}
+
+	return New(secret, fp)
+}
+
+// Filepath returns the path for the pseudonyms db file.
+func Filepath() (string, error) {
+	folder, err := ini.WakaResourcesDir()
+	if err != nil {
+		return "", fmt.Errorf("failed getting resource directory: %s", err)
+	}
+
+	return filepath.Join(folder, dbFilename), nil
+}
+
+// Pseudonymize returns the token of a name and records it for revealing.
+func (p *Pseudonymizer) Pseudonymize(name string) string {
+	token := Token(p.secret, name)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.recorded[token] || p.filepath == "" {
+		return token
+	}
+
+	if err := record(p.filepath, token, name); err != nil {
+		log.Warnf("failed to record pseudonym: %s", err)
+		return token
+	}
+
+	p.recorded[token] = true
+
+	return token
+}
+
+// Token returns the stable token of a name. It is derived from a hmac sha256
+// of the name, keyed by secret.
+func Token(secret []byte, name string) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write([]byte(name))
+
+	return tokenPrefix + hex.EncodeToString(mac.Sum(nil)[:tokenSize])
+}
+
+// Reveal replaces all tokens recorded in the passed in db file within s
+// by their names. Returns the number of revealed tokens.
+func Reveal(filepath, s string) (string, int, error) {
+	if _, err := os.Stat(filepath); err != nil {
+		return "", 0, fmt.Errorf("failed to stat db file: %s", err)
+	}
+
+	db, close, err := openDB(filepath, true)
+	if err != nil {
+		return "", 0, err
+	}
+
+	defer close()
+
+	var count int
+
+	err = db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(dbBucket))
+		if b == nil {
+			return nil
+		}
+
+		s = tokenRegex.ReplaceAllStringFunc(s, func(token string) string {
+			name := b.Get([]byte(token))
+			if name == nil {
+				return token
+			}
+
+			count++
+
+			return string(name)
+		})
+
+		return nil
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read db: %s", err)
+	}
+
+	return s, count, nil
+}
+
+// LoadSecret returns the pseudonymization secret from the internal config.
+// A new secret is generated and saved, if none exists yet.
+func LoadSecret(v *viper.Viper) ([]byte, error) {
+	if str := vipertools.GetString(v, "internal."+secretKey); str != "" {
+		secret, err := hex.DecodeString(strings.TrimSpace(str))
+		if err == nil && len(secret) > 0 {
+			return secret, nil
+		}
+
+		log.Warnf("invalid pseudonymization secret in internal config. generating new one")
+	}
+
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %s", err)
+	}
+
+	w, err := ini.NewWriter(v, ini.InternalFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse internal config file: %s", err)
+	}
+
+	if err := w.Write("internal", map[string]string{secretKey: hex.EncodeToString(secret)}); err != nil {
+		return nil, fmt.Errorf("failed to write to internal config file: %s", err)
+	}
+
+	v.Set("internal."+secretKey, hex.EncodeToString(secret))
+
+	return secret, nil
+}
+
+// record saves the name of a token in the db file.
+func record(filepath, token, name string) error {
+	db, close, err := openDB(filepath, false)
+	if err != nil {
+		return err
+	}
+
+	defer close()
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(dbBucket))
+		if err != nil {
+			return fmt.Errorf("failed to create/load bucket: %s", err)
+		}
+
+		return b.Put([]byte(token), []byte(name))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store pseudonym: %s", err)
+	}
+
+	return nil
+}
+
+// openDB opens a connection to the pseudonyms db. A read only connection
+// shares the file lock with other readers.
+// It returns the pointer to bolt.DB, a function to close the connection and an error.
+func openDB(filepath string, readOnly bool) (db *bolt.DB, _ func(), err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panicked: %v", r)
+		}
+	}()
+
+	db, err = bolt.Open(filepath, 0600, &bolt.Options{ReadOnly: readOnly, Timeout: openTimeout})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open db file: %s", err)
+	}
+
+	return db, func() {
+		if err := db.Close(); err != nil {
+			log.Debugf("failed to close db file: %s", err)
+		}
+	}, err
+}
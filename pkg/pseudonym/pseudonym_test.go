@@ -0,0 +1,99 @@
+package pseudonym_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/pseudonym"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToken(t *testing.T) {
+	token := pseudonym.Token([]byte("secret"), "/path/to/main.go")
+
+	assert.Regexp(t, "^HIDDEN-[0-9a-f]{16}$", token)
+	assert.Equal(t, token, pseudonym.Token([]byte("secret"), "/path/to/main.go"))
+	assert.NotEqual(t, token, pseudonym.Token([]byte("secret"), "/path/to/other.go"))
+	assert.NotEqual(t, token, pseudonym.Token([]byte("other secret"), "/path/to/main.go"))
+}
+
+func TestPseudonymizer_Pseudonymize(t *testing.T) {
+	dbFilepath := filepath.Join(t.TempDir(), "pseudonyms.bdb")
+
+	p := pseudonym.New([]byte("secret"), dbFilepath)
+
+	file := p.Pseudonymize("/path/to/main")
+	branch := p.Pseudonymize("feature/secret")
+
+	assert.Equal(t, pseudonym.Token([]byte("secret"), "/path/to/main"), file)
+	assert.Equal(t, pseudonym.Token([]byte("secret"), "feature/secret"), branch)
+
+	revealed, count, err := pseudonym.Reveal(dbFilepath, file+".go on branch "+branch)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/path/to/main.go on branch feature/secret", revealed)
+	assert.Equal(t, 2, count)
+}
+
+func TestPseudonymizer_Pseudonymize_NoFilepath(t *testing.T) {
+	p := pseudonym.New([]byte("secret"), "")
+
+	assert.Equal(t, pseudonym.Token([]byte("secret"), "main"), p.Pseudonymize("main"))
+}
+
+func TestReveal_UnknownToken(t *testing.T) {
+	dbFilepath := filepath.Join(t.TempDir(), "pseudonyms.bdb")
+
+	p := pseudonym.New([]byte("secret"), dbFilepath)
+	_ = p.Pseudonymize("/path/to/main.go")
+
+	revealed, count, err := pseudonym.Reveal(dbFilepath, "HIDDEN-0123456789abcdef.go")
+	require.NoError(t, err)
+
+	assert.Equal(t, "HIDDEN-0123456789abcdef.go", revealed)
+	assert.Zero(t, count)
+}
+
+func TestReveal_MissingDB(t *testing.T) {
+	_, _, err := pseudonym.Reveal(filepath.Join(t.TempDir(), "missing.bdb"), "HIDDEN-0123456789abcdef")
+
+	assert.Error(t, err)
+}
+
+func TestLoadSecret(t *testing.T) {
+	v := viper.New()
+	v.Set("internal.pseudonym_secret", "0123456789abcdef")
+
+	secret, err := pseudonym.LoadSecret(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}, secret)
+}
+
+func TestLoadSecret_Generate(t *testing.T) {
+	internalConfig := filepath.Join(t.TempDir(), "wakatime-internal.cfg")
+
+	v := viper.New()
+	v.Set("internal-config", internalConfig)
+
+	secret, err := pseudonym.LoadSecret(v)
+	require.NoError(t, err)
+
+	assert.Len(t, secret, 32)
+
+	data, err := os.ReadFile(internalConfig)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(data), "[internal]")
+	assert.Contains(t, string(data), "pseudonym_secret")
+
+	// the generated secret is reused afterwards
+	reloaded, err := pseudonym.LoadSecret(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, secret, reloaded)
+}
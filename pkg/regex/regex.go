@@ -13,6 +13,7 @@ import (
 type Regex interface {
 	FindStringSubmatch(s string) []string
 	MatchString(s string) bool
+	ReplaceAllString(src, repl string) string
 	String() string
 }
 
@@ -90,6 +91,18 @@ func (re *regexp2Wrap) MatchString(s string) bool {
 	return matched
 }
 
+// ReplaceAllString returns a copy of src, replacing matches of the regular
+// expression with the replacement string repl.
+func (re *regexp2Wrap) ReplaceAllString(src, repl string) string {
+	replaced, err := re.rgx.Replace(src, repl, -1, -1)
+	if err != nil {
+		log.Warnf("failed to replace string %q: %s", src, err)
+		return src
+	}
+
+	return replaced
+}
+
 // String returns the source text used to compile the regular expression.
 func (re *regexp2Wrap) String() string {
 	return re.rgx.String()
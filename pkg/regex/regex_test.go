@@ -40,3 +40,18 @@ func TestMustCompile(t *testing.T) {
 		})
 	}
 }
+
+func TestReplaceAllString(t *testing.T) {
+	tests := map[string]string{
+		"standard":           `token=\w+`,
+		"negative lookahead": `token=(?!public)\w+`,
+	}
+
+	for name, pattern := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := regex.MustCompile(pattern)
+
+			assert.Equal(t, "?REDACTED&a=1", r.ReplaceAllString("?token=abc123&a=1", "REDACTED"))
+		})
+	}
+}
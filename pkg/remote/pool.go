@@ -0,0 +1,126 @@
+package remote
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// Pool caches ssh and sftp connections, so that heartbeats of a batch share
+// one connection per host. Failed connections are cached as well, to not
+// retry an unreachable host for every heartbeat.
+type Pool struct {
+	mu    sync.Mutex
+	conns map[string]connection
+}
+
+type connection struct {
+	sshClient  *ssh.Client
+	sftpClient *sftp.Client
+	err        error
+}
+
+// NewPool creates a new empty Pool.
+func NewPool() *Pool {
+	return &Pool{
+		conns: make(map[string]connection),
+	}
+}
+
+// Connect returns the cached connection to the host of a client or connects to it.
+func (p *Pool) Connect(c Client) (*ssh.Client, *sftp.Client, error) {
+	key := fmt.Sprintf("%s:%s@%s:%d", c.user(), c.Pass, c.OriginalHost, c.Port)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if conn, ok := p.conns[key]; ok {
+		return conn.sshClient, conn.sftpClient, conn.err
+	}
+
+	sshClient, sftpClient, err := c.Connect()
+	p.conns[key] = connection{
+		sshClient:  sshClient,
+		sftpClient: sftpClient,
+		err:        err,
+	}
+
+	return sshClient, sftpClient, err
+}
+
+// Stat returns the file info of a file on the host of a client.
+func (p *Pool) Stat(c Client, fp string) (os.FileInfo, error) {
+	_, sc, err := p.Connect(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to sftp host: %s", err)
+	}
+
+	return sc.Stat(fp)
+}
+
+// ReadDir returns the file infos of the entries of a folder on the host of a client.
+func (p *Pool) ReadDir(c Client, fp string) ([]os.FileInfo, error) {
+	_, sc, err := p.Connect(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to sftp host: %s", err)
+	}
+
+	return sc.ReadDir(fp)
+}
+
+// ReadFile reads up to size bytes of a file on the host of a client.
+func (p *Pool) ReadFile(c Client, fp string, size int64) ([]byte, error) {
+	_, sc, err := p.Connect(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to sftp host: %s", err)
+	}
+
+	f, err := sc.Open(fp)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Debugf("failed to close remote ftp file: %s", err)
+		}
+	}()
+
+	var buf bytes.Buffer
+
+	_, err = io.CopyN(&buf, f, size)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Close closes all cached connections.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, conn := range p.conns {
+		if conn.sftpClient != nil {
+			if err := conn.sftpClient.Close(); err != nil {
+				log.Debugf("failed to close connection to ftp server: %s", err)
+			}
+		}
+
+		if conn.sshClient != nil {
+			if err := conn.sshClient.Close(); err != nil {
+				log.Debugf("failed to close connection to ssh server: %s", err)
+			}
+		}
+
+		delete(p.conns, key)
+	}
+}
@@ -30,6 +30,8 @@ const (
 	defaultTimeoutSecs = 20
 	// Max file size supporting downloading from remote. Default is 512Kb.
 	maxFileSize = 512000
+	// Max size read from the head of a remote file, when not downloading it. Default is 16Kb.
+	maxHeadSize = 16384
 	defaultPort = 22
 )
 
@@ -44,10 +46,21 @@ type Client struct {
 	Path         string
 }
 
+// Config contains remote file detection configurations.
+type Config struct {
+	// HeadOnly reads only the head of remote files, instead of downloading
+	// them. Lines are counted on the remote host.
+	HeadOnly bool
+	// Pool shares connections with later stages of the pipeline and is closed
+	// by the caller. If not set, a pool is created and closed per batch.
+	Pool *Pool
+}
+
 // WithDetection initializes and returns a heartbeat handle option, which
 // can be used in a heartbeat processing pipeline to detect remote file and
-// download to a temporary directory.
-func WithDetection() heartbeat.HandleOption {
+// download to a temporary directory. Connections are shared by heartbeats
+// of the same host.
+func WithDetection(config Config) heartbeat.HandleOption {
 	return func(next heartbeat.Handle) heartbeat.Handle {
 		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 			log.Debugln("execute remote file detection")
@@ -58,6 +71,12 @@ func WithDetection() heartbeat.HandleOption {
 				filtered []heartbeat.Heartbeat
 			)
 
+			pool := config.Pool
+			if pool == nil {
+				pool = NewPool()
+				defer pool.Close()
+			}
+
 			for _, h := range hh {
 				if !h.IsRemote() {
 					filtered = append(filtered, h)
@@ -80,6 +99,10 @@ func WithDetection() heartbeat.HandleOption {
 					continue
 				}
 
+				if err := tmpFile.Close(); err != nil {
+					log.Debugf("failed to close temporary file: %s", err)
+				}
+
 				c, err := NewClient(h.Entity)
 				if err != nil {
 					log.Errorf("failed to create new remote client: %s", err)
@@ -89,8 +112,15 @@ func WithDetection() heartbeat.HandleOption {
 					continue
 				}
 
-				err = c.DownloadFile(tmpFile.Name())
-				if err != nil {
+				if config.HeadOnly {
+					if err := c.detectHead(pool, &h, tmpFile.Name()); err != nil {
+						log.Errorf("failed to read head of remote file: %s", err)
+
+						deleteLocalFile(tmpFile.Name())
+
+						continue
+					}
+				} else if err := c.download(pool, tmpFile.Name(), maxFileSize); err != nil {
 					log.Errorf("failed to download file to temporary folder: %s", err)
 
 					err = c.DownloadFileFallback(tmpFile.Name())
@@ -114,6 +144,30 @@ func WithDetection() heartbeat.HandleOption {
 	}
 }
 
+// detectHead reads the head of the remote file to a local file and counts
+// its lines on the remote host.
+func (c Client) detectHead(pool *Pool, h *heartbeat.Heartbeat, localFile string) error {
+	sshClient, sftpClient, err := pool.Connect(c)
+	if err != nil {
+		return fmt.Errorf("failed to connect to sftp host: %s", err)
+	}
+
+	if err := c.copyFile(sftpClient, localFile, maxHeadSize); err != nil {
+		return err
+	}
+
+	if h.Lines == nil {
+		lines, err := c.countLines(sshClient)
+		if err != nil {
+			log.Warnf("failed to count lines of remote file %q: %s", c.Path, err)
+		} else {
+			h.Lines = heartbeat.PointerTo(lines)
+		}
+	}
+
+	return nil
+}
+
 // WithCleanup initializes and returns a heartbeat handle option, which
 // deletes a local temporary file if downloaded from a remote file.
 func WithCleanup() heartbeat.HandleOption {
@@ -189,21 +243,24 @@ func NewClient(address string) (Client, error) {
 
 // DownloadFile downloads a remote file and copy to a local file.
 func (c Client) DownloadFile(localFile string) error {
-	conn, sc, err := c.Connect()
+	pool := NewPool()
+	defer pool.Close()
+
+	return c.download(pool, localFile, maxFileSize)
+}
+
+// download copies up to size bytes of the remote file to a local file.
+func (c Client) download(pool *Pool, localFile string, size int64) error {
+	_, sc, err := pool.Connect(c)
 	if err != nil {
 		return fmt.Errorf("failed to connect to sftp host: %s", err)
 	}
 
-	defer func() {
-		if err := conn.Close(); err != nil {
-			log.Debugf("failed to close connection to ssh server: %s", err)
-		}
-
-		if err := sc.Close(); err != nil {
-			log.Debugf("failed to close connection to ftp server: %s", err)
-		}
-	}()
+	return c.copyFile(sc, localFile, size)
+}
 
+// copyFile copies up to size bytes of the remote file to a local file.
+func (c Client) copyFile(sc *sftp.Client, localFile string, size int64) error {
 	srcFile, err := sc.OpenFile(c.Path, os.O_RDONLY)
 	if err != nil {
 		return fmt.Errorf("failed to open remote file: %s", err)
@@ -226,7 +283,7 @@ func (c Client) DownloadFile(localFile string) error {
 		}
 	}()
 
-	_, err = io.CopyN(dstFile, srcFile, maxFileSize)
+	_, err = io.CopyN(dstFile, srcFile, size)
 	if err != nil && err != io.EOF {
 		return fmt.Errorf("failed to download remote file: %s", err)
 	}
@@ -234,6 +291,37 @@ func (c Client) DownloadFile(localFile string) error {
 	return nil
 }
 
+// countLines counts the lines of the remote file by running wc on the remote host.
+func (c Client) countLines(sshClient *ssh.Client) (int, error) {
+	session, err := sshClient.NewSession()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open ssh session: %s", err)
+	}
+
+	defer func() {
+		if err := session.Close(); err != nil && err != io.EOF {
+			log.Debugf("failed to close ssh session: %s", err)
+		}
+	}()
+
+	output, err := session.Output("wc -l < " + shellQuote(c.Path))
+	if err != nil {
+		return 0, fmt.Errorf("failed to run wc: %s", err)
+	}
+
+	lines, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse wc output %q: %s", output, err)
+	}
+
+	return lines, nil
+}
+
+// shellQuote quotes a string for use as a single argument in a posix shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
 // DownloadFileFallback downloads a remote file and copy to a local file using machine's ssh.
 func (c Client) DownloadFileFallback(localFile string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeoutSecs*time.Second)
@@ -264,6 +352,10 @@ func (c Client) Connect() (*ssh.Client, *sftp.Client, error) {
 	// Create new SFTP client
 	sftpClient, err := sftp.NewClient(sshClient)
 	if err != nil {
+		if err := sshClient.Close(); err != nil {
+			log.Debugf("failed to close connection to ssh server: %s", err)
+		}
+
 		return nil, nil, fmt.Errorf("failed to start sftp subsystem: %s", err)
 	}
 
@@ -0,0 +1,58 @@
+package scrub
+
+import (
+	"github.com/wakatime/wakatime-cli/pkg/regex"
+)
+
+// detector finds secrets of a common token format.
+type detector struct {
+	// Name is the name of the token format.
+	Name string
+	// Regex matches secrets.
+	Regex regex.Regex
+	// Replacement replaces matches. It may refer to groups of Regex.
+	Replacement string
+}
+
+// detectors contains the built-in detectors of common token formats.
+// nolint:gochecknoglobals
+var detectors = []detector{
+	{
+		Name:        "basic auth url",
+		Regex:       regex.MustCompile(`(?i)\b([a-z][a-z0-9+.-]*://)[^/\s:@]+:[^/\s@]+@`),
+		Replacement: "${1}",
+	},
+	{
+		Name: "url query",
+		Regex: regex.MustCompile(
+			`(?i)([?&;][a-z0-9_-]*(?:token|secret|passw(?:or)?d|pwd|api[_-]?key|sig(?:nature)?|session|auth|credential|code)=)` +
+				`[^&#\s]+`,
+		),
+		Replacement: "${1}" + Redacted,
+	},
+	{
+		Name:        "aws access key",
+		Regex:       regex.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA|AGPA|AIDA|AIPA|ANPA|ANVA|AROA|APKA|ASCA)[0-9A-Z]{16}\b`),
+		Replacement: Redacted,
+	},
+	{
+		Name:        "github token",
+		Regex:       regex.MustCompile(`\b(?:(?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`),
+		Replacement: Redacted,
+	},
+	{
+		Name:        "gitlab token",
+		Regex:       regex.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`),
+		Replacement: Redacted,
+	},
+	{
+		Name:        "slack token",
+		Regex:       regex.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`),
+		Replacement: Redacted,
+	},
+	{
+		Name:        "jwt",
+		Regex:       regex.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{10,}`),
+		Replacement: Redacted,
+	},
+}
@@ -0,0 +1,85 @@
+package scrub
+
+import (
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/regex"
+)
+
+// Redacted replaces secrets found in heartbeats.
+const Redacted = "REDACTED"
+
+// Config contains secret scrubbing configurations.
+type Config struct {
+	// Detectors enables the built-in detectors of common token formats.
+	Detectors bool
+	// Patterns are user defined regular expressions, whose matches are replaced.
+	Patterns []regex.Regex
+}
+
+// WithScrubbing initializes and returns a heartbeat handle option, which
+// can be used in a heartbeat processing pipeline to remove secrets and
+// credentials from entities, dependencies and branch names.
+func WithScrubbing(config Config) heartbeat.HandleOption {
+	return func(next heartbeat.Handle) heartbeat.Handle {
+		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			log.Debugln("execute secret scrubbing")
+
+			for n, h := range hh {
+				hh[n] = Scrub(h, config)
+			}
+
+			return next(hh)
+		}
+	}
+}
+
+// Scrub removes secrets from the entity, dependencies and branch of a
+// heartbeat following the passed in configuration.
+func Scrub(h heartbeat.Heartbeat, config Config) heartbeat.Heartbeat {
+	h.Entity = scrubField(h, "entity", h.Entity, config)
+
+	if h.Branch != nil {
+		h.Branch = heartbeat.PointerTo(scrubField(h, "branch", *h.Branch, config))
+	}
+
+	if len(h.Dependencies) > 0 {
+		dependencies := make([]string, len(h.Dependencies))
+		for i, dep := range h.Dependencies {
+			dependencies[i] = scrubField(h, "dependency", dep, config)
+		}
+
+		h.Dependencies = dependencies
+	}
+
+	return h
+}
+
+// scrubField removes secrets from the value of a heartbeat field.
+func scrubField(h heartbeat.Heartbeat, field, value string, config Config) string {
+	if config.Detectors {
+		for _, d := range detectors {
+			if !d.Regex.MatchString(value) {
+				continue
+			}
+
+			value = d.Regex.ReplaceAllString(value, d.Replacement)
+
+			log.Debugf("%s secret scrubbed from %s", d.Name, field)
+			h.Explanation.Add("scrub", "%s secret scrubbed from %s", d.Name, field)
+		}
+	}
+
+	for _, pattern := range config.Patterns {
+		if !pattern.MatchString(value) {
+			continue
+		}
+
+		value = pattern.ReplaceAllString(value, Redacted)
+
+		log.Debugf("secret matching scrub pattern %q scrubbed from %s", pattern.String(), field)
+		h.Explanation.Add("scrub", "secret matching scrub pattern %q scrubbed from %s", pattern.String(), field)
+	}
+
+	return value
+}
@@ -0,0 +1,370 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/durations"
+	"github.com/wakatime/wakatime-cli/pkg/fileexperts"
+	"github.com/wakatime/wakatime-cli/pkg/goal"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/ini"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/summary"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// BasePath is the path prefix of all endpoints. Clients use the server's
+	// address joined with this path as api url.
+	BasePath = "/api/v1"
+	// dbFilename is the default bolt db filename.
+	dbFilename = "serve.bdb"
+)
+
+// Config contains stand-in server configurations.
+type Config struct {
+	// DBFilepath is the path of the bolt db file storing all data.
+	DBFilepath string
+	// Goals maps goal ids to their daily target in seconds.
+	Goals map[string]int
+}
+
+// Server is a stand-in for the WakaTime API, which stores heartbeats in a
+// local bolt db and serves all endpoints used by the api client.
+type Server struct {
+	goals map[string]int
+	store *store
+}
+
+// New opens the bolt db and creates a new Server.
+func New(config Config) (*Server, error) {
+	db, err := bolt.Open(config.DBFilepath, 0600, &bolt.Options{Timeout: 30 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open db file: %s", err)
+	}
+
+	return &Server{
+		goals: config.Goals,
+		store: &store{db: db},
+	}, nil
+}
+
+// Filepath returns the default path for the server's bolt db file.
+func Filepath() (string, error) {
+	folder, err := ini.WakaResourcesDir()
+	if err != nil {
+		return "", fmt.Errorf("failed getting resource directory: %s", err)
+	}
+
+	return filepath.Join(folder, dbFilename), nil
+}
+
+// Close closes the bolt db.
+func (s *Server) Close() error {
+	return s.store.db.Close()
+}
+
+// Handler returns the http.Handler serving all endpoints.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST "+BasePath+"/users/current/heartbeats.bulk", s.withAuth(s.handleHeartbeats))
+	mux.HandleFunc("GET "+BasePath+"/users/current/statusbar/today", s.withAuth(s.handleToday))
+	mux.HandleFunc("GET "+BasePath+"/users/current/goals/{id}", s.withAuth(s.handleGoal))
+	mux.HandleFunc("POST "+BasePath+"/users/current/file_experts", s.withAuth(s.handleFileExperts))
+	mux.HandleFunc("POST "+BasePath+"/plugins/errors", s.handleDiagnostics)
+
+	return mux
+}
+
+type userHandlerFunc func(w http.ResponseWriter, req *http.Request, user string)
+
+// withAuth resolves the user from the basic auth api key and rejects
+// requests without an api key.
+func (*Server) withAuth(next userHandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		apiKey, err := parseAPIKey(req.Header.Get("Authorization"))
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		next(w, req, userID(apiKey))
+	}
+}
+
+func (s *Server) handleHeartbeats(w http.ResponseWriter, req *http.Request, user string) {
+	var hh []heartbeat.Heartbeat
+
+	if err := json.NewDecoder(req.Body).Decode(&hh); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("failed to parse heartbeats: %s", err))
+		return
+	}
+
+	var (
+		responses = make([][]any, len(hh))
+		valid     []heartbeat.Heartbeat
+	)
+
+	for i, h := range hh {
+		if err := validate(h); err != nil {
+			responses[i] = []any{map[string]string{"error": err.Error()}, http.StatusBadRequest}
+			continue
+		}
+
+		valid = append(valid, h)
+		responses[i] = []any{map[string]heartbeat.Heartbeat{"data": h}, http.StatusCreated}
+	}
+
+	if err := s.store.saveHeartbeats(user, valid); err != nil {
+		log.Errorf("failed to save heartbeats: %s", err)
+		writeError(w, http.StatusInternalServerError, "failed to save heartbeats")
+
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]any{"responses": responses})
+}
+
+func (s *Server) handleToday(w http.ResponseWriter, req *http.Request, user string) {
+	start, end := today(req)
+
+	hh, err := s.store.readHeartbeats(user, start, end)
+	if err != nil {
+		log.Errorf("failed to read heartbeats: %s", err)
+		writeError(w, http.StatusInternalServerError, "failed to read heartbeats")
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, summary.Compute(hh, start, end))
+}
+
+func (s *Server) handleGoal(w http.ResponseWriter, req *http.Request, user string) {
+	id := req.PathValue("id")
+
+	goalSeconds, ok := s.goals[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("goal %q not found", id))
+		return
+	}
+
+	start, end := today(req)
+
+	hh, err := s.store.readHeartbeats(user, start, end)
+	if err != nil {
+		log.Errorf("failed to read heartbeats: %s", err)
+		writeError(w, http.StatusInternalServerError, "failed to read heartbeats")
+
+		return
+	}
+
+	s.writeGoal(w, id, goalSeconds, summary.Compute(hh, start, end))
+}
+
+func (*Server) writeGoal(w http.ResponseWriter, id string, goalSeconds int, today *summary.Summary) {
+	status := "pending"
+	if today.Data.GrandTotal.TotalSeconds >= float64(goalSeconds) {
+		status = "success"
+	}
+
+	goalText := summary.NewGrandTotal(float64(goalSeconds)).Text
+
+	writeJSON(w, http.StatusOK, goal.Goal{
+		CachedAt: today.CachedAt,
+		Data: goal.Data{
+			ChartData: []goal.ChartData{
+				{
+					ActualSeconds:     today.Data.GrandTotal.TotalSeconds,
+					ActualSecondsText: today.Data.GrandTotal.Text,
+					GoalSeconds:       goalSeconds,
+					GoalSecondsText:   goalText,
+					Range: goal.Range{
+						Date:     today.Data.Range.Date,
+						End:      today.Data.Range.End,
+						Start:    today.Data.Range.Start,
+						Text:     today.Data.Range.Text,
+						Timezone: today.Data.Range.Timezone,
+					},
+					RangeStatus: status,
+				},
+			},
+			Editors:    []string{},
+			ID:         id,
+			IgnoreDays: []string{},
+			IsEnabled:  true,
+			Languages:  []string{},
+			Projects:   []string{},
+			RangeText:  "daily",
+			Seconds:    goalSeconds,
+			SharedWith: []string{},
+			Status:     status,
+			StatusPercentCalculated: int(math.Min(100,
+				today.Data.GrandTotal.TotalSeconds/math.Max(1, float64(goalSeconds))*100)),
+			Subscribers: []goal.Subscriber{},
+			Title:       fmt.Sprintf("Code %s per day", goalText),
+			Type:        "coding",
+		},
+	})
+}
+
+func (s *Server) handleFileExperts(w http.ResponseWriter, req *http.Request, user string) {
+	var entity fileexperts.Entity
+
+	if err := json.NewDecoder(req.Body).Decode(&entity); err != nil || entity.Filepath == "" {
+		writeError(w, http.StatusBadRequest, "invalid or missing entity")
+		return
+	}
+
+	users, err := s.store.readAllUsers(func(h heartbeat.Heartbeat) bool {
+		if h.Entity != entity.Filepath {
+			return false
+		}
+
+		return entity.Project == nil || h.Project == nil || *entity.Project == *h.Project
+	})
+	if err != nil {
+		log.Errorf("failed to read heartbeats: %s", err)
+		writeError(w, http.StatusInternalServerError, "failed to read heartbeats")
+
+		return
+	}
+
+	var experts fileexperts.FileExperts
+
+	for id, hh := range users {
+		total := durations.Total(durations.Calculate(hh, durations.Config{}))
+		if total == 0 {
+			continue
+		}
+
+		grandTotal := summary.NewGrandTotal(total)
+
+		experts.Data = append(experts.Data, fileexperts.Data{
+			Total: fileexperts.Total{
+				Decimal:      grandTotal.Decimal,
+				Digital:      grandTotal.Digital,
+				Text:         grandTotal.Text,
+				TotalSeconds: grandTotal.TotalSeconds,
+			},
+			User: fileexperts.User{
+				ID:            id,
+				IsCurrentUser: id == user,
+				LongName:      "user " + id,
+				Name:          "user-" + id[:8],
+			},
+		})
+	}
+
+	sort.Slice(experts.Data, func(i, j int) bool {
+		return experts.Data[i].Total.TotalSeconds > experts.Data[j].Total.TotalSeconds
+	})
+
+	writeJSON(w, http.StatusOK, experts)
+}
+
+func (s *Server) handleDiagnostics(w http.ResponseWriter, req *http.Request) {
+	data, err := io.ReadAll(req.Body)
+	if err != nil || !json.Valid(data) {
+		writeError(w, http.StatusBadRequest, "invalid diagnostics")
+		return
+	}
+
+	if err := s.store.saveDiagnostics(data); err != nil {
+		log.Errorf("failed to save diagnostics: %s", err)
+		writeError(w, http.StatusInternalServerError, "failed to save diagnostics")
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+// parseAPIKey parses the api key from a basic auth header value, as sent by
+// the api client.
+func parseAPIKey(header string) (string, error) {
+	encoded, ok := strings.CutPrefix(header, "Basic ")
+	if !ok {
+		return "", errors.New("missing basic auth")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("invalid basic auth: %s", err)
+	}
+
+	user, secret, _ := strings.Cut(string(decoded), ":")
+
+	apiKey := user
+	if secret != "" {
+		apiKey = secret
+	}
+
+	if apiKey == "" {
+		return "", errors.New("missing api key")
+	}
+
+	return apiKey, nil
+}
+
+// today returns the range of the current day in the timezone of the client.
+func today(req *http.Request) (time.Time, time.Time) {
+	loc := time.Local
+
+	if name := req.Header.Get("Timezone"); name != "" {
+		parsed, err := time.LoadLocation(name)
+		if err != nil {
+			log.Debugf("failed to load timezone %q: %s", name, err)
+		} else {
+			loc = parsed
+		}
+	}
+
+	now := time.Now().In(loc)
+
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc), now
+}
+
+func validate(h heartbeat.Heartbeat) error {
+	if h.Entity == "" {
+		return errors.New("missing entity")
+	}
+
+	if h.Time <= 0 {
+		return errors.New("missing time")
+	}
+
+	return nil
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		log.Errorf("failed to json marshal response: %s", err)
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if _, err := w.Write(data); err != nil {
+		log.Debugf("failed to write response: %s", err)
+	}
+}
@@ -0,0 +1,213 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/api"
+	"github.com/wakatime/wakatime-cli/pkg/diagnostic"
+	"github.com/wakatime/wakatime-cli/pkg/fileexperts"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/server"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_SendHeartbeats(t *testing.T) {
+	url := setupTestServer(t)
+
+	c := api.NewClient(url)
+
+	hh := testHeartbeats("00000000-0000-4000-8000-000000000000")
+	hh[1].Entity = ""
+
+	results, err := c.SendHeartbeats(hh[:2])
+	require.NoError(t, err)
+
+	require.Len(t, results, 2)
+
+	assert.Equal(t, http.StatusCreated, results[0].Status)
+	assert.Equal(t, "/tmp/main.go", results[0].Heartbeat.Entity)
+
+	assert.Equal(t, http.StatusBadRequest, results[1].Status)
+	assert.Equal(t, []string{"missing entity"}, results[1].Errors)
+}
+
+func TestServer_SendHeartbeats_Unauthorized(t *testing.T) {
+	url := setupTestServer(t)
+
+	resp, err := http.Post(url+"/users/current/heartbeats.bulk", "application/json", strings.NewReader("[]"))
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestServer_Today(t *testing.T) {
+	skipCloseToMidnight(t)
+
+	url := setupTestServer(t)
+
+	apiKey := "00000000-0000-4000-8000-000000000000"
+
+	_, err := api.NewClient(url).SendHeartbeats(testHeartbeats(apiKey))
+	require.NoError(t, err)
+
+	c := newAuthClient(t, url, apiKey)
+
+	s, err := c.Today()
+	require.NoError(t, err)
+
+	assert.Equal(t, float64(120), s.Data.GrandTotal.TotalSeconds)
+	assert.Equal(t, "2 mins", s.Data.GrandTotal.Text)
+	require.Len(t, s.Data.Projects, 1)
+	assert.Equal(t, "wakatime-cli", s.Data.Projects[0].Name)
+
+	// heartbeats of other api keys are not included
+	other, err := newAuthClient(t, url, "00000000-0000-4000-8000-000000000001").Today()
+	require.NoError(t, err)
+
+	assert.Zero(t, other.Data.GrandTotal.TotalSeconds)
+}
+
+func TestServer_Goal(t *testing.T) {
+	skipCloseToMidnight(t)
+
+	url := setupTestServer(t)
+
+	apiKey := "00000000-0000-4000-8000-000000000000"
+
+	_, err := api.NewClient(url).SendHeartbeats(testHeartbeats(apiKey))
+	require.NoError(t, err)
+
+	c := newAuthClient(t, url, apiKey)
+
+	g, err := c.Goal("daily")
+	require.NoError(t, err)
+
+	assert.Equal(t, "daily", g.Data.ID)
+	assert.Equal(t, 3600, g.Data.Seconds)
+	assert.Equal(t, "Code 1 hr per day", g.Data.Title)
+	require.Len(t, g.Data.ChartData, 1)
+	assert.Equal(t, float64(120), g.Data.ChartData[0].ActualSeconds)
+	assert.Equal(t, "2 mins", g.Data.ChartData[0].ActualSecondsText)
+	assert.Equal(t, 3600, g.Data.ChartData[0].GoalSeconds)
+	assert.Equal(t, "pending", g.Data.ChartData[0].RangeStatus)
+
+	_, err = c.Goal("unknown")
+	require.Error(t, err)
+}
+
+func TestServer_FileExperts(t *testing.T) {
+	url := setupTestServer(t)
+
+	var (
+		apiKey      = "00000000-0000-4000-8000-000000000000"
+		otherAPIKey = "00000000-0000-4000-8000-000000000001"
+	)
+
+	c := api.NewClient(url)
+
+	_, err := c.SendHeartbeats(testHeartbeats(apiKey))
+	require.NoError(t, err)
+
+	other := testHeartbeats(otherAPIKey)
+	other[2].Time += 60
+
+	_, err = c.SendHeartbeats(other)
+	require.NoError(t, err)
+
+	results, err := c.FileExperts(testHeartbeats(apiKey)[:1])
+	require.NoError(t, err)
+
+	require.Len(t, results, 1)
+
+	experts, ok := results[0].FileExpert.(*fileexperts.FileExperts)
+	require.True(t, ok)
+	require.Len(t, experts.Data, 2)
+
+	assert.False(t, experts.Data[0].User.IsCurrentUser)
+	assert.Equal(t, float64(180), experts.Data[0].Total.TotalSeconds)
+	assert.Equal(t, "3 mins", experts.Data[0].Total.Text)
+
+	assert.True(t, experts.Data[1].User.IsCurrentUser)
+	assert.Equal(t, float64(120), experts.Data[1].Total.TotalSeconds)
+}
+
+func TestServer_SendDiagnostics(t *testing.T) {
+	url := setupTestServer(t)
+
+	err := api.NewClient(url).SendDiagnostics("vim", false, diagnostic.Error("some error"))
+	require.NoError(t, err)
+}
+
+func setupTestServer(t *testing.T) string {
+	srv, err := server.New(server.Config{
+		DBFilepath: filepath.Join(t.TempDir(), "serve.bdb"),
+		Goals:      map[string]int{"daily": 3600},
+	})
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(srv.Handler())
+
+	t.Cleanup(func() {
+		ts.Close()
+
+		err := srv.Close()
+		require.NoError(t, err)
+	})
+
+	return ts.URL + server.BasePath
+}
+
+func newAuthClient(t *testing.T, url, apiKey string) *api.Client {
+	withAuth, err := api.WithAuth(api.BasicAuth{Secret: apiKey})
+	require.NoError(t, err)
+
+	return api.NewClient(url, withAuth)
+}
+
+func skipCloseToMidnight(t *testing.T) {
+	now := time.Now()
+
+	if now.Sub(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())) < 5*time.Minute {
+		t.Skip("Skipping because too close to midnight.")
+	}
+}
+
+func testHeartbeats(apiKey string) []heartbeat.Heartbeat {
+	start := float64(time.Now().Add(-3 * time.Minute).Unix())
+
+	return []heartbeat.Heartbeat{
+		{
+			APIKey:   apiKey,
+			Category: heartbeat.CodingCategory,
+			Entity:   "/tmp/main.go",
+			Language: heartbeat.PointerTo("Go"),
+			Project:  heartbeat.PointerTo("wakatime-cli"),
+			Time:     start,
+		},
+		{
+			APIKey:   apiKey,
+			Category: heartbeat.CodingCategory,
+			Entity:   "/tmp/main.go",
+			Language: heartbeat.PointerTo("Go"),
+			Project:  heartbeat.PointerTo("wakatime-cli"),
+			Time:     start + 60,
+		},
+		{
+			APIKey:   apiKey,
+			Category: heartbeat.CodingCategory,
+			Entity:   "/tmp/main.go",
+			Language: heartbeat.PointerTo("Go"),
+			Project:  heartbeat.PointerTo("wakatime-cli"),
+			Time:     start + 120,
+		},
+	}
+}
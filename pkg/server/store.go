@@ -0,0 +1,176 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// diagnosticsBucket is the bolt db bucket storing received diagnostics.
+	diagnosticsBucket = "diagnostics"
+	// heartbeatsBucketPrefix is the prefix of the per user bolt db buckets
+	// storing received heartbeats.
+	heartbeatsBucketPrefix = "heartbeats_"
+)
+
+// store persists heartbeats per user in a bolt db.
+type store struct {
+	db *bolt.DB
+}
+
+// userID returns an identifier for the passed in api key, which does not
+// expose the api key itself.
+func userID(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// saveHeartbeats stores heartbeats of the user. Storing the same heartbeat
+// twice keeps a single record.
+func (s *store) saveHeartbeats(user string, hh []heartbeat.Heartbeat) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(heartbeatsBucketPrefix + user))
+		if err != nil {
+			return fmt.Errorf("failed to create/load bucket: %s", err)
+		}
+
+		for _, h := range hh {
+			data, err := json.Marshal(h)
+			if err != nil {
+				return fmt.Errorf("failed to json marshal heartbeat: %s", err)
+			}
+
+			if err := b.Put(heartbeatKey(h), data); err != nil {
+				return fmt.Errorf("failed to store heartbeat with id %q: %s", h.ID(), err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// readHeartbeats returns all heartbeats of the user with a time in range
+// [start, end), ordered by time.
+func (s *store) readHeartbeats(user string, start, end time.Time) ([]heartbeat.Heartbeat, error) {
+	var hh []heartbeat.Heartbeat
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(heartbeatsBucketPrefix + user))
+		if b == nil {
+			return nil
+		}
+
+		hh = readRange(b, start, end)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read heartbeats: %s", err)
+	}
+
+	return hh, nil
+}
+
+// readAllUsers returns the heartbeats of all users, which match the passed in filter.
+func (s *store) readAllUsers(filter func(h heartbeat.Heartbeat) bool) (map[string][]heartbeat.Heartbeat, error) {
+	users := make(map[string][]heartbeat.Heartbeat)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			if !strings.HasPrefix(string(name), heartbeatsBucketPrefix) {
+				return nil
+			}
+
+			user := strings.TrimPrefix(string(name), heartbeatsBucketPrefix)
+
+			return b.ForEach(func(_, v []byte) error {
+				var h heartbeat.Heartbeat
+
+				if err := json.Unmarshal(v, &h); err != nil {
+					log.Warnf("failed to json unmarshal heartbeat data: %s", err)
+					return nil
+				}
+
+				if filter(h) {
+					users[user] = append(users[user], h)
+				}
+
+				return nil
+			})
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read heartbeats: %s", err)
+	}
+
+	return users, nil
+}
+
+// saveDiagnostics stores the raw diagnostics request body.
+func (s *store) saveDiagnostics(data []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(diagnosticsBucket))
+		if err != nil {
+			return fmt.Errorf("failed to create/load bucket: %s", err)
+		}
+
+		id, err := b.NextSequence()
+		if err != nil {
+			return fmt.Errorf("failed to generate id: %s", err)
+		}
+
+		key := fmt.Sprintf("%s-%020d", time.Now().UTC().Format(time.RFC3339), id)
+
+		return b.Put([]byte(key), data)
+	})
+}
+
+func readRange(b *bolt.Bucket, start, end time.Time) []heartbeat.Heartbeat {
+	var (
+		hh  []heartbeat.Heartbeat
+		c   = b.Cursor()
+		max = timePrefix(toSeconds(end))
+	)
+
+	for k, v := c.Seek(timePrefix(toSeconds(start))); k != nil; k, v = c.Next() {
+		if string(k[:len(max)]) >= string(max) {
+			break
+		}
+
+		var h heartbeat.Heartbeat
+
+		if err := json.Unmarshal(v, &h); err != nil {
+			log.Warnf("failed to json unmarshal heartbeat data: %s", err)
+			continue
+		}
+
+		hh = append(hh, h)
+	}
+
+	return hh
+}
+
+// heartbeatKey returns a lexicographically time ordered key for a heartbeat.
+func heartbeatKey(h heartbeat.Heartbeat) []byte {
+	return append(timePrefix(h.Time), []byte("-"+h.ID())...)
+}
+
+// timePrefix returns a zero padded representation of the passed in unix
+// timestamp, which keeps the bolt cursor order equal to the time order.
+func timePrefix(secs float64) []byte {
+	return []byte(fmt.Sprintf("%017.6f", secs))
+}
+
+func toSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / 1000000000
+}
@@ -0,0 +1,81 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// JSONL appends heartbeats to a local file, one json object per line.
+type JSONL struct {
+	filepath string
+}
+
+// NewJSONL creates a new JSONL sink writing to the passed in file.
+func NewJSONL(filepath string) *JSONL {
+	return &JSONL{
+		filepath: filepath,
+	}
+}
+
+// SendHeartbeats appends heartbeats to the file. The file is created if missing.
+func (s *JSONL) SendHeartbeats(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	log.Debugf("appending %d heartbeat(s) to %s", len(hh), s.filepath)
+
+	data, err := marshalLines(hh)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(s.filepath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %q: %s", s.filepath, err)
+	}
+
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Debugf("failed to close file %q: %s", s.filepath, err)
+		}
+	}()
+
+	if _, err := f.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to write to file %q: %s", s.filepath, err)
+	}
+
+	return results(hh), nil
+}
+
+// marshalLines encodes heartbeats as json objects, each terminated by a new line.
+func marshalLines(hh []heartbeat.Heartbeat) ([]byte, error) {
+	var data []byte
+
+	for _, h := range hh {
+		line, err := json.Marshal(h)
+		if err != nil {
+			return nil, fmt.Errorf("failed to json marshal heartbeat: %s", err)
+		}
+
+		data = append(data, line...)
+		data = append(data, '\n')
+	}
+
+	return data, nil
+}
+
+// results returns a successful result for every heartbeat.
+func results(hh []heartbeat.Heartbeat) []heartbeat.Result {
+	results := make([]heartbeat.Result, len(hh))
+
+	for i, h := range hh {
+		results[i] = heartbeat.Result{
+			Heartbeat: h,
+			Status:    http.StatusCreated,
+		}
+	}
+
+	return results
+}
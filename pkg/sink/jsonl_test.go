@@ -0,0 +1,55 @@
+package sink_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/sink"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONL_SendHeartbeats(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "heartbeats.jsonl")
+
+	s := sink.NewJSONL(fp)
+
+	for i := 0; i < 2; i++ {
+		results, err := s.SendHeartbeats([]heartbeat.Heartbeat{testHeartbeat()})
+		require.NoError(t, err)
+
+		assert.Equal(t, []heartbeat.Result{
+			{
+				Heartbeat: testHeartbeat(),
+				Status:    http.StatusCreated,
+			},
+		}, results)
+	}
+
+	data, err := os.ReadFile(fp)
+	require.NoError(t, err)
+
+	lines := splitLines(data)
+	require.Len(t, lines, 2)
+
+	for _, line := range lines {
+		var h heartbeat.Heartbeat
+
+		err := json.Unmarshal([]byte(line), &h)
+		require.NoError(t, err)
+
+		assert.Equal(t, testHeartbeat(), h)
+	}
+}
+
+func TestJSONL_SendHeartbeats_InvalidPath(t *testing.T) {
+	s := sink.NewJSONL(filepath.Join(t.TempDir(), "missing", "heartbeats.jsonl"))
+
+	_, err := s.SendHeartbeats([]heartbeat.Heartbeat{testHeartbeat()})
+	assert.Error(t, err)
+}
@@ -0,0 +1,235 @@
+package sink
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/backoff"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/ini"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/offline"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+)
+
+const (
+	// queueFolder is the folder inside of the wakatime resources directory
+	// holding the offline queue db files of all sinks.
+	queueFolder = "sinks"
+	// MaxTimeout is the maximum timeout of a request to a sink. Sinks are sent
+	// to before the WakaTime API, so a slow sink must not delay heartbeats.
+	MaxTimeout = 5 * time.Second
+)
+
+// nameRegex matches valid sink names. Names are used in file names.
+var nameRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`) // nolint:gochecknoglobals
+
+// Type is the type of a heartbeat sink.
+type Type int
+
+const (
+	// APIType sends heartbeats to a WakaTime compatible api.
+	APIType Type = iota
+	// JSONLType appends heartbeats to a local file, one json object per line.
+	JSONLType
+	// SocketType writes heartbeats to a unix socket, one json object per line.
+	SocketType
+	// WebhookType posts heartbeats as json array to an http endpoint.
+	WebhookType
+)
+
+const (
+	apiTypeString     = "api"
+	jsonlTypeString   = "jsonl"
+	socketTypeString  = "unix"
+	webhookTypeString = "webhook"
+)
+
+// String implements fmt.Stringer interface.
+func (t Type) String() string {
+	switch t {
+	case APIType:
+		return apiTypeString
+	case JSONLType:
+		return jsonlTypeString
+	case SocketType:
+		return socketTypeString
+	case WebhookType:
+		return webhookTypeString
+	default:
+		return ""
+	}
+}
+
+// Config contains the configuration and retry state of a heartbeat sink.
+type Config struct {
+	// Backoff is the backoff state of the sink.
+	Backoff backoff.State
+	// Name is the unique name of the sink.
+	Name string
+	// Type is the type of the sink.
+	Type Type
+	// Target is the api base url or webhook url, or the file path of the
+	// jsonl file or unix socket.
+	Target string
+}
+
+// ParseConfig parses a sink configuration from a name and an url like value.
+// Supported values are:
+//
+//	api+https://wakatime.example.com/api/v1
+//	file:///path/to/heartbeats.jsonl
+//	https://example.com/webhook
+//	unix:///path/to/wakatime.sock
+func ParseConfig(name, value string) (Config, error) {
+	if name == "" {
+		return Config{}, errors.New("missing sink name")
+	}
+
+	if !nameRegex.MatchString(name) {
+		return Config{}, fmt.Errorf("invalid sink name %q. only letters, digits, _ and - are allowed", name)
+	}
+
+	value = strings.TrimSpace(value)
+
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid sink url %q: %s", value, err)
+	}
+
+	config := Config{
+		Name:   name,
+		Target: value,
+	}
+
+	switch parsed.Scheme {
+	case "api+http", "api+https":
+		config.Type = APIType
+		config.Target = strings.TrimSuffix(strings.TrimPrefix(value, "api+"), "/")
+	case "file":
+		config.Type = JSONLType
+		config.Target, err = expandPath(value, "file://")
+	case "http", "https":
+		config.Type = WebhookType
+	case "unix":
+		config.Type = SocketType
+		config.Target, err = expandPath(value, "unix://")
+	default:
+		return Config{}, fmt.Errorf("unsupported sink url scheme %q", parsed.Scheme)
+	}
+
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to parse sink path: %s", err)
+	}
+
+	return config, nil
+}
+
+// BackoffName returns the name of the sink's backoff state.
+func (c Config) BackoffName() string {
+	return "sink_" + c.Name
+}
+
+// String implements fmt.Stringer interface.
+func (c Config) String() string {
+	return fmt.Sprintf("%s: %s '%s'", c.Name, c.Type, c.Target)
+}
+
+// Sink is an additional heartbeat destination next to the WakaTime API.
+type Sink struct {
+	Config    Config
+	QueueFile string
+	Sender    heartbeat.Sender
+	// SyncOptions are applied to heartbeats synced from the offline queue,
+	// e.g. to restore api keys, which are not persisted in the queue.
+	SyncOptions []heartbeat.HandleOption
+	// V is an instance of Viper, used to persist the retry state.
+	V *viper.Viper
+}
+
+// WithFanOut initializes and returns a heartbeat handle option, which can be
+// used in a heartbeat processing pipeline to send heartbeats to all passed in
+// sinks, next to the WakaTime API. Every sink has its own offline queue and
+// retry state, so a failing sink never affects the others or the WakaTime API.
+func WithFanOut(sinks []Sink) heartbeat.HandleOption {
+	return func(next heartbeat.Handle) heartbeat.Handle {
+		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			log.Debugf("execute heartbeat fan out to %d sink(s)", len(sinks))
+
+			// sinks are processed one after another, as retry state updates
+			// of all sinks share the internal config file.
+			for _, s := range sinks {
+				if len(hh) == 0 {
+					break
+				}
+
+				if err := s.Send(hh); err != nil {
+					log.Warnf("failed to send heartbeats to sink %q: %s", s.Config.Name, err)
+				}
+			}
+
+			return next(hh)
+		}
+	}
+}
+
+// Send sends heartbeats to the sink. On failure the heartbeats are stored
+// in the sink's offline queue. On success, previously queued heartbeats are
+// synced to the sink.
+func (s Sink) Send(hh []heartbeat.Heartbeat) error {
+	handle := heartbeat.NewHandle(s.Sender,
+		offline.WithQueue(s.QueueFile),
+		backoff.WithBackoff(backoff.Config{
+			At:       s.Config.Backoff.At,
+			Retries:  s.Config.Backoff.Retries,
+			Until:    s.Config.Backoff.Until,
+			V:        s.V,
+			Name:     s.Config.BackoffName(),
+			Endpoint: "sink " + s.Config.Name,
+		}),
+	)
+
+	if _, err := handle(hh); err != nil {
+		return err
+	}
+
+	if err := offline.Sync(s.QueueFile, offline.SyncMaxDefault)(heartbeat.NewHandle(s.Sender, s.SyncOptions...)); err != nil {
+		return fmt.Errorf("failed to sync offline heartbeats: %s", err)
+	}
+
+	return nil
+}
+
+// QueueFilepath returns the path for the offline queue db file of the sink
+// with the passed in name. The containing folder is created if missing.
+func QueueFilepath(name string) (string, error) {
+	folder, err := ini.WakaResourcesDir()
+	if err != nil {
+		return "", fmt.Errorf("failed getting resource directory: %s", err)
+	}
+
+	folder = filepath.Join(folder, queueFolder)
+
+	if err := os.MkdirAll(folder, 0750); err != nil {
+		return "", fmt.Errorf("failed to create sinks folder: %s", err)
+	}
+
+	return filepath.Join(folder, name+".bdb"), nil
+}
+
+func expandPath(value, prefix string) (string, error) {
+	fp := strings.TrimPrefix(value, prefix)
+	if fp == "" {
+		return "", fmt.Errorf("missing path in %q", value)
+	}
+
+	return homedir.Expand(fp)
+}
@@ -0,0 +1,264 @@
+package sink_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/offline"
+	"github.com/wakatime/wakatime-cli/pkg/sink"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		Value    string
+		Expected sink.Config
+	}{
+		"api": {
+			Value: "api+https://wakatime.example.com/api/v1/",
+			Expected: sink.Config{
+				Name:   "test",
+				Type:   sink.APIType,
+				Target: "https://wakatime.example.com/api/v1",
+			},
+		},
+		"jsonl": {
+			Value: "file:///var/log/heartbeats.jsonl",
+			Expected: sink.Config{
+				Name:   "test",
+				Type:   sink.JSONLType,
+				Target: "/var/log/heartbeats.jsonl",
+			},
+		},
+		"jsonl home dir": {
+			Value: "file://~/heartbeats.jsonl",
+			Expected: sink.Config{
+				Name:   "test",
+				Type:   sink.JSONLType,
+				Target: filepath.Join(home, "heartbeats.jsonl"),
+			},
+		},
+		"webhook": {
+			Value: " https://example.com/webhook?token=secret ",
+			Expected: sink.Config{
+				Name:   "test",
+				Type:   sink.WebhookType,
+				Target: "https://example.com/webhook?token=secret",
+			},
+		},
+		"socket": {
+			Value: "unix:///tmp/wakatime.sock",
+			Expected: sink.Config{
+				Name:   "test",
+				Type:   sink.SocketType,
+				Target: "/tmp/wakatime.sock",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			config, err := sink.ParseConfig("test", test.Value)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, config)
+		})
+	}
+}
+
+func TestParseConfig_Err(t *testing.T) {
+	tests := map[string]struct {
+		Name  string
+		Value string
+	}{
+		"missing name": {
+			Value: "https://example.com/webhook",
+		},
+		"unsupported scheme": {
+			Name:  "test",
+			Value: "ftp://example.com/heartbeats",
+		},
+		"missing path": {
+			Name:  "test",
+			Value: "file://",
+		},
+		"path traversal name": {
+			Name:  "../x",
+			Value: "https://example.com/webhook",
+		},
+		"name with path separator": {
+			Name:  `a\b`,
+			Value: "https://example.com/webhook",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := sink.ParseConfig(test.Name, test.Value)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestWithFanOut(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	v := viper.New()
+	v.Set("internal-config", filepath.Join(tmpDir, "wakatime-internal.cfg"))
+
+	failingQueue := filepath.Join(tmpDir, "failing.bdb")
+
+	sinks := []sink.Sink{
+		{
+			Config: sink.Config{
+				Name: "failing",
+				Type: sink.WebhookType,
+			},
+			QueueFile: failingQueue,
+			Sender:    failingSender{},
+			V:         v,
+		},
+		{
+			Config: sink.Config{
+				Name: "local",
+				Type: sink.JSONLType,
+			},
+			QueueFile: filepath.Join(tmpDir, "local.bdb"),
+			Sender:    sink.NewJSONL(filepath.Join(tmpDir, "heartbeats.jsonl")),
+			V:         v,
+		},
+	}
+
+	opt := sink.WithFanOut(sinks)
+
+	var numCalls int
+
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		numCalls++
+
+		assert.Len(t, hh, 1)
+
+		return []heartbeat.Result{
+			{
+				Status: 201,
+			},
+		}, nil
+	})
+
+	results, err := handle([]heartbeat.Heartbeat{testHeartbeat()})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, numCalls)
+	assert.Len(t, results, 1)
+
+	// the failing sink queued the heartbeat
+	queued, err := offline.ReadHeartbeats(failingQueue, 10)
+	require.NoError(t, err)
+
+	assert.Equal(t, []heartbeat.Heartbeat{testHeartbeat()}, queued)
+
+	// the local sink received the heartbeat
+	data, err := os.ReadFile(filepath.Join(tmpDir, "heartbeats.jsonl"))
+	require.NoError(t, err)
+
+	assert.Contains(t, string(data), `"entity":"/tmp/main.go"`)
+
+	// the failing sink increased its own retry state
+	internal, err := os.ReadFile(filepath.Join(tmpDir, "wakatime-internal.cfg"))
+	require.NoError(t, err)
+
+	assert.Regexp(t, `sink_failing_backoff_retries\s*= 1`, string(internal))
+	assert.NotContains(t, string(internal), "sink_local_backoff_retries")
+}
+
+func TestSink_Send_SyncsQueue(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	v := viper.New()
+	v.Set("internal-config", filepath.Join(tmpDir, "wakatime-internal.cfg"))
+
+	queueFile := filepath.Join(tmpDir, "local.bdb")
+	jsonlFile := filepath.Join(tmpDir, "heartbeats.jsonl")
+
+	// queue a heartbeat through a failing sender first
+	err := sink.Sink{
+		Config:    sink.Config{Name: "local"},
+		QueueFile: queueFile,
+		Sender:    failingSender{},
+		V:         v,
+	}.Send([]heartbeat.Heartbeat{testHeartbeat()})
+	require.Error(t, err)
+
+	var apiKeys []string
+
+	err = sink.Sink{
+		Config:    sink.Config{Name: "local"},
+		QueueFile: queueFile,
+		Sender:    sink.NewJSONL(jsonlFile),
+		SyncOptions: []heartbeat.HandleOption{
+			func(next heartbeat.Handle) heartbeat.Handle {
+				return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+					for _, h := range hh {
+						apiKeys = append(apiKeys, h.APIKey)
+					}
+
+					return next(hh)
+				}
+			},
+		},
+		V: v,
+	}.Send([]heartbeat.Heartbeat{testHeartbeat()})
+	require.NoError(t, err)
+
+	count, err := offline.CountHeartbeats(queueFile)
+	require.NoError(t, err)
+
+	assert.Zero(t, count)
+	assert.Len(t, apiKeys, 1)
+
+	data, err := os.ReadFile(jsonlFile)
+	require.NoError(t, err)
+
+	assert.Len(t, splitLines(data), 2)
+}
+
+type failingSender struct{}
+
+func (failingSender) SendHeartbeats(_ []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	return nil, errors.New("failed")
+}
+
+func testHeartbeat() heartbeat.Heartbeat {
+	return heartbeat.Heartbeat{
+		Branch:     heartbeat.PointerTo("heartbeat"),
+		Category:   heartbeat.CodingCategory,
+		Entity:     "/tmp/main.go",
+		EntityType: heartbeat.FileType,
+		Language:   heartbeat.PointerTo("Go"),
+		Project:    heartbeat.PointerTo("wakatime-cli"),
+		Time:       1592868367.219124,
+		UserAgent:  "wakatime/13.0.6 (linux-4.19.0-9-amd64-x86_64) go1.20.4 vim/8.2",
+	}
+}
+
+func splitLines(data []byte) []string {
+	var lines []string
+
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,57 @@
+package sink
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// Socket writes heartbeats to a listener on a unix socket, one json object per line.
+type Socket struct {
+	path    string
+	timeout time.Duration
+}
+
+// NewSocket creates a new Socket sink connecting to the passed in socket path.
+func NewSocket(path string, timeout time.Duration) *Socket {
+	return &Socket{
+		path:    path,
+		timeout: timeout,
+	}
+}
+
+// SendHeartbeats writes heartbeats to the unix socket.
+func (s *Socket) SendHeartbeats(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	log.Debugf("writing %d heartbeat(s) to unix socket %s", len(hh), s.path)
+
+	data, err := marshalLines(hh)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialTimeout("unix", s.path, s.timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to unix socket %q: %s", s.path, err)
+	}
+
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.Debugf("failed to close unix socket connection: %s", err)
+		}
+	}()
+
+	if s.timeout > 0 {
+		if err := conn.SetWriteDeadline(time.Now().Add(s.timeout)); err != nil {
+			return nil, fmt.Errorf("failed to set write deadline: %s", err)
+		}
+	}
+
+	if _, err := conn.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to write to unix socket %q: %s", s.path, err)
+	}
+
+	return results(hh), nil
+}
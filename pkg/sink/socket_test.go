@@ -0,0 +1,77 @@
+package sink_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/sink"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSocket_SendHeartbeats(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping because OS is windows.")
+	}
+
+	path := filepath.Join(t.TempDir(), "wakatime.sock")
+
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+
+	defer listener.Close()
+
+	received := make(chan heartbeat.Heartbeat, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			var h heartbeat.Heartbeat
+
+			if err := json.Unmarshal(scanner.Bytes(), &h); err == nil {
+				received <- h
+			}
+		}
+	}()
+
+	s := sink.NewSocket(path, 5*time.Second)
+
+	results, err := s.SendHeartbeats([]heartbeat.Heartbeat{testHeartbeat()})
+	require.NoError(t, err)
+
+	assert.Equal(t, []heartbeat.Result{
+		{
+			Heartbeat: testHeartbeat(),
+			Status:    http.StatusCreated,
+		},
+	}, results)
+
+	select {
+	case h := <-received:
+		assert.Equal(t, testHeartbeat(), h)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for heartbeat")
+	}
+}
+
+func TestSocket_SendHeartbeats_NoListener(t *testing.T) {
+	s := sink.NewSocket(filepath.Join(t.TempDir(), "wakatime.sock"), time.Second)
+
+	_, err := s.SendHeartbeats([]heartbeat.Heartbeat{testHeartbeat()})
+	assert.Error(t, err)
+}
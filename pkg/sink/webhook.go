@@ -0,0 +1,66 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// Webhook posts heartbeats as json array to an http endpoint.
+type Webhook struct {
+	client *http.Client
+	url    string
+}
+
+// NewWebhook creates a new Webhook sink posting to the passed in url.
+func NewWebhook(url string, timeout time.Duration) *Webhook {
+	return &Webhook{
+		client: &http.Client{
+			Timeout: timeout,
+		},
+		url: url,
+	}
+}
+
+// SendHeartbeats posts heartbeats to the webhook. Any 2xx response status
+// is considered a success.
+func (s *Webhook) SendHeartbeats(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	log.Debugf("posting %d heartbeat(s) to webhook at %s", len(hh), s.url)
+
+	data, err := json.Marshal(hh)
+	if err != nil {
+		return nil, fmt.Errorf("failed to json encode body: %s", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewBuffer(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %s", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed making request to %q: %s", s.url, err)
+	}
+	defer resp.Body.Close() // nolint:errcheck,gosec
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(resp.Body)
+
+		return nil, fmt.Errorf(
+			"invalid response status from %q. got: %d, want: 2xx. body: %q",
+			s.url,
+			resp.StatusCode,
+			string(body),
+		)
+	}
+
+	return results(hh), nil
+}
@@ -0,0 +1,69 @@
+package sink_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/sink"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook_SendHeartbeats(t *testing.T) {
+	var numCalls int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		numCalls++
+
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		var hh []heartbeat.Heartbeat
+
+		err = json.Unmarshal(body, &hh)
+		require.NoError(t, err)
+
+		assert.Equal(t, []heartbeat.Heartbeat{testHeartbeat()}, hh)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	s := sink.NewWebhook(server.URL, 5*time.Second)
+
+	results, err := s.SendHeartbeats([]heartbeat.Heartbeat{testHeartbeat()})
+	require.NoError(t, err)
+
+	assert.Equal(t, []heartbeat.Result{
+		{
+			Heartbeat: testHeartbeat(),
+			Status:    http.StatusCreated,
+		},
+	}, results)
+
+	assert.Equal(t, 1, numCalls)
+}
+
+func TestWebhook_SendHeartbeats_InvalidStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("internal error"))
+	}))
+	defer server.Close()
+
+	s := sink.NewWebhook(server.URL, 5*time.Second)
+
+	_, err := s.SendHeartbeats([]heartbeat.Heartbeat{testHeartbeat()})
+	require.Error(t, err)
+
+	assert.Contains(t, err.Error(), "got: 500, want: 2xx")
+}
@@ -0,0 +1,344 @@
+package summary
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/durations"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+const (
+	// aiAttribution is the name used for time spent coding with an ai assistant.
+	aiAttribution = "AI"
+	// humanAttribution is the name used for time spent coding without an ai assistant.
+	humanAttribution = "Human"
+	// unknownProject is the name used for heartbeats without a project.
+	unknownProject = "Unknown Project"
+	// otherLanguage is the name used for heartbeats without a language.
+	otherLanguage = "Other"
+	// unknownEditor is the name used when the editor cannot be parsed from the user agent.
+	unknownEditor = "Unknown"
+)
+
+// userAgentRegex is a pattern for user agents generated by heartbeat.UserAgent().
+var userAgentRegex = regexp.MustCompile(`^wakatime/\S+ \((?P<os>[^)]*)\) \S+ (?P<plugin>.+)$`)
+
+// nolint:gochecknoglobals
+var editorNames = map[string]string{
+	"atom":         "Atom",
+	"eclipse":      "Eclipse",
+	"emacs":        "Emacs",
+	"neovim":       "Neovim",
+	"nvim":         "Neovim",
+	"sublime":      "Sublime Text",
+	"sublime_text": "Sublime Text",
+	"unknown":      unknownEditor,
+	"vim":          "Vim",
+	"visualstudio": "Visual Studio",
+	"vscode":       "VS Code",
+	"xcode":        "Xcode",
+}
+
+// nolint:gochecknoglobals
+var operatingSystemNames = map[string]string{
+	"darwin":  "Mac",
+	"freebsd": "FreeBSD",
+	"linux":   "Linux",
+	"netbsd":  "NetBSD",
+	"openbsd": "OpenBSD",
+	"windows": "Windows",
+}
+
+// Compute calculates a summary from the passed in heartbeats, counting only
+// activity inside [start, end). Time spent is calculated separately for every
+// summary section, using durations split by the section's heartbeat field.
+// Attributions are only calculated, if any heartbeat contains ai attribution.
+func Compute(hh []heartbeat.Heartbeat, start, end time.Time) *Summary {
+	var (
+		from = float64(start.UnixNano()) / 1000000000
+		to   = float64(end.UnixNano()) / 1000000000
+	)
+
+	var filtered []heartbeat.Heartbeat
+
+	for _, h := range hh {
+		if h.Time < from || h.Time >= to {
+			continue
+		}
+
+		filtered = append(filtered, h)
+	}
+
+	var (
+		total            = durations.Total(durations.Calculate(filtered, durations.Config{}))
+		attributions     = newCounterSet()
+		branches         = newCounterSet()
+		categories       = newCounterSet()
+		dependencies     = newCounterSet()
+		editors          = newCounterSet()
+		languages        = newCounterSet()
+		operatingSystems = newCounterSet()
+		projects         = newCounterSet()
+	)
+
+	if hasAttribution(filtered) {
+		for _, d := range calculate(filtered, durations.FieldAIAssisted) {
+			if d.AIAssisted {
+				attributions.add(aiAttribution, d.Seconds())
+			} else {
+				attributions.add(humanAttribution, d.Seconds())
+			}
+		}
+	}
+
+	for _, d := range calculate(filtered, durations.FieldBranch) {
+		if d.Branch != "" {
+			branches.add(d.Branch, d.Seconds())
+		}
+	}
+
+	for _, d := range calculate(filtered, durations.FieldCategory) {
+		categories.add(cases.Title(language.AmericanEnglish).String(d.Category.String()), d.Seconds())
+	}
+
+	for _, d := range calculate(filtered, durations.FieldDependencies) {
+		for _, dep := range d.Dependencies {
+			dependencies.add(dep, d.Seconds())
+		}
+	}
+
+	for _, d := range calculate(filtered, durations.FieldUserAgent) {
+		editor, os := parseUserAgent(d.UserAgent)
+
+		editors.add(editor, d.Seconds())
+
+		if os != "" {
+			operatingSystems.add(os, d.Seconds())
+		}
+	}
+
+	for _, d := range calculate(filtered, durations.FieldLanguage) {
+		languages.add(nameOrDefault(d.Language, otherLanguage), d.Seconds())
+	}
+
+	for _, d := range calculate(filtered, durations.FieldProject) {
+		projects.add(nameOrDefault(d.Project, unknownProject), d.Seconds())
+	}
+
+	return &Summary{
+		CachedAt: time.Now().UTC().Format(time.RFC3339),
+		Data: Data{
+			Attributions:     convert[Attribution](attributions.counters()),
+			Branches:         convert[Branch](branches.counters()),
+			Categories:       convert[Category](categories.counters()),
+			Dependencies:     convert[Dependency](dependencies.counters()),
+			Editors:          convert[Editor](editors.counters()),
+			GrandTotal:       NewGrandTotal(total),
+			Languages:        convert[Language](languages.counters()),
+			Machines:         []Machine{},
+			OperatingSystems: convert[OperatingSystem](operatingSystems.counters()),
+			Projects:         convert[Project](projects.counters()),
+			Range:            newRange(start, end),
+		},
+	}
+}
+
+// hasAttribution returns true, if any heartbeat tells whether its code was
+// written with an ai assistant.
+func hasAttribution(hh []heartbeat.Heartbeat) bool {
+	for _, h := range hh {
+		if h.AILineChanges != nil || h.HumanLineChanges != nil || h.Category == heartbeat.AICodingCategory {
+			return true
+		}
+	}
+
+	return false
+}
+
+func calculate(hh []heartbeat.Heartbeat, field durations.Field) []durations.Duration {
+	return durations.Calculate(hh, durations.Config{
+		SplitBy: []durations.Field{field},
+	})
+}
+
+// counterSet accumulates seconds per name, keeping the order of appearance.
+type counterSet struct {
+	names   []string
+	seconds map[string]float64
+}
+
+func newCounterSet() *counterSet {
+	return &counterSet{
+		seconds: make(map[string]float64),
+	}
+}
+
+func (c *counterSet) add(name string, secs float64) {
+	if _, ok := c.seconds[name]; !ok {
+		c.names = append(c.names, name)
+	}
+
+	c.seconds[name] += secs
+}
+
+// counters returns all counters ordered by most time spent.
+func (c *counterSet) counters() []Counter {
+	var total float64
+
+	for _, secs := range c.seconds {
+		total += secs
+	}
+
+	counters := make([]Counter, 0, len(c.names))
+
+	for _, name := range c.names {
+		counters = append(counters, newCounter(name, c.seconds[name], total))
+	}
+
+	sort.SliceStable(counters, func(i, j int) bool {
+		return counters[i].TotalSeconds > counters[j].TotalSeconds
+	})
+
+	return counters
+}
+
+func newCounter(name string, secs, total float64) Counter {
+	whole := int(secs)
+	hours := whole / 3600
+	minutes := (whole % 3600) / 60
+	seconds := whole % 60
+
+	var percent float64
+	if total > 0 {
+		percent = math.Round(secs/total*10000) / 100
+	}
+
+	return Counter{
+		Decimal:      fmt.Sprintf("%.2f", float64(hours)+float64(minutes)/60),
+		Digital:      fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds),
+		Hours:        hours,
+		Minutes:      minutes,
+		Name:         name,
+		Percent:      percent,
+		Seconds:      seconds,
+		Text:         formatText(hours, minutes, seconds),
+		TotalSeconds: secs,
+	}
+}
+
+// convert converts counters into any type sharing the fields of Counter.
+func convert[T Attribution | Branch | Category | Dependency | Editor | Language | OperatingSystem | Project](
+	counters []Counter,
+) []T {
+	converted := make([]T, len(counters))
+
+	for i, c := range counters {
+		converted[i] = T(c)
+	}
+
+	return converted
+}
+
+// NewGrandTotal returns the grand total for the passed in seconds, formatted
+// like the WakaTime API, e.g. "2 hrs 17 mins".
+func NewGrandTotal(secs float64) GrandTotal {
+	c := newCounter("", secs, secs)
+
+	return GrandTotal{
+		Decimal:      c.Decimal,
+		Digital:      c.Digital,
+		Hours:        c.Hours,
+		Minutes:      c.Minutes,
+		Text:         c.Text,
+		TotalSeconds: c.TotalSeconds,
+	}
+}
+
+// formatText formats a duration like the WakaTime API, e.g. "2 hrs 17 mins".
+func formatText(hours, minutes, seconds int) string {
+	if hours == 0 && minutes == 0 {
+		return pluralize(seconds, "sec")
+	}
+
+	var parts []string
+
+	if hours > 0 {
+		parts = append(parts, pluralize(hours, "hr"))
+	}
+
+	if minutes > 0 {
+		parts = append(parts, pluralize(minutes, "min"))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func pluralize(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+func nameOrDefault(name, fallback string) string {
+	if name == "" {
+		return fallback
+	}
+
+	return name
+}
+
+func newRange(start, end time.Time) Range {
+	text := start.Format("Mon Jan 2 2006")
+	if start.Format(time.DateOnly) == time.Now().In(start.Location()).Format(time.DateOnly) {
+		text = "Today"
+	}
+
+	timezone, _ := start.Zone()
+
+	return Range{
+		Date:     start.Format(time.DateOnly),
+		End:      end.UTC().Format(time.RFC3339),
+		Start:    start.UTC().Format(time.RFC3339),
+		Text:     text,
+		Timezone: timezone,
+	}
+}
+
+// parseUserAgent returns the editor and operating system names from a user agent.
+func parseUserAgent(userAgent string) (string, string) {
+	match := userAgentRegex.FindStringSubmatch(userAgent)
+	if match == nil {
+		return unknownEditor, ""
+	}
+
+	var editor, os string
+
+	for i, name := range userAgentRegex.SubexpNames() {
+		switch name {
+		case "os":
+			os = strings.SplitN(match[i], "-", 2)[0]
+		case "plugin":
+			editor = strings.SplitN(strings.Fields(match[i])[0], "/", 2)[0]
+		}
+	}
+
+	if name, ok := editorNames[strings.ToLower(editor)]; ok {
+		editor = name
+	}
+
+	if name, ok := operatingSystemNames[strings.ToLower(os)]; ok {
+		os = name
+	}
+
+	return editor, os
+}
@@ -0,0 +1,195 @@
+package summary_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/summary"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompute(t *testing.T) {
+	start := time.Date(2020, 6, 23, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	base := float64(start.Add(10 * time.Hour).Unix())
+
+	hh := []heartbeat.Heartbeat{
+		{
+			Branch:       heartbeat.PointerTo("master"),
+			Category:     heartbeat.CodingCategory,
+			Dependencies: []string{"fmt"},
+			Entity:       "/tmp/main.go",
+			Language:     heartbeat.PointerTo("Go"),
+			Project:      heartbeat.PointerTo("wakatime-cli"),
+			Time:         base + 600,
+			UserAgent:    "wakatime/13.0.6 (linux-4.19.0-9-amd64-x86_64) go1.20.4 vscode/1.78.2 vscode-wakatime/24.0.0",
+		},
+		{
+			Branch:       heartbeat.PointerTo("master"),
+			Category:     heartbeat.CodingCategory,
+			Dependencies: []string{"fmt"},
+			Entity:       "/tmp/main.go",
+			Language:     heartbeat.PointerTo("Go"),
+			Project:      heartbeat.PointerTo("wakatime-cli"),
+			Time:         base,
+			UserAgent:    "wakatime/13.0.6 (linux-4.19.0-9-amd64-x86_64) go1.20.4 vscode/1.78.2 vscode-wakatime/24.0.0",
+		},
+		{
+			Category:  heartbeat.DebuggingCategory,
+			Entity:    "/tmp/main.py",
+			Time:      base + 1800,
+			UserAgent: "wakatime/13.0.6 (darwin-22.4.0-arm64) go1.20.4 vim/9.0 vim-wakatime/10.0.0",
+		},
+		{
+			Category:  heartbeat.DebuggingCategory,
+			Entity:    "/tmp/main.py",
+			Time:      base + 1807,
+			UserAgent: "wakatime/13.0.6 (darwin-22.4.0-arm64) go1.20.4 vim/9.0 vim-wakatime/10.0.0",
+		},
+		{
+			// outside of range
+			Category: heartbeat.CodingCategory,
+			Entity:   "/tmp/main.go",
+			Time:     float64(end.Unix()),
+		},
+	}
+
+	s := summary.Compute(hh, start, end)
+
+	// first gap of 600 seconds counts, second gap of 1200 seconds exceeds
+	// the idle timeout and the last heartbeat has no following one.
+	assert.Equal(t, summary.GrandTotal{
+		Decimal:      "0.17",
+		Digital:      "0:10:07",
+		Hours:        0,
+		Minutes:      10,
+		Text:         "10 mins",
+		TotalSeconds: 607,
+	}, s.Data.GrandTotal)
+
+	assert.Equal(t, []summary.Project{
+		{
+			Decimal:      "0.17",
+			Digital:      "0:10:00",
+			Minutes:      10,
+			Name:         "wakatime-cli",
+			Percent:      98.85,
+			Text:         "10 mins",
+			TotalSeconds: 600,
+		},
+		{
+			Decimal:      "0.00",
+			Digital:      "0:00:07",
+			Name:         "Unknown Project",
+			Percent:      1.15,
+			Seconds:      7,
+			Text:         "7 secs",
+			TotalSeconds: 7,
+		},
+	}, s.Data.Projects)
+
+	assert.Empty(t, s.Data.Attributions)
+	assert.Equal(t, []string{"Go", "Other"}, names(s.Data.Languages))
+	assert.Equal(t, []string{"master"}, names(s.Data.Branches))
+	assert.Equal(t, []string{"Coding", "Debugging"}, names(s.Data.Categories))
+	assert.Equal(t, []string{"fmt"}, names(s.Data.Dependencies))
+	assert.Equal(t, []string{"VS Code", "Vim"}, names(s.Data.Editors))
+	assert.Equal(t, []string{"Linux", "Mac"}, names(s.Data.OperatingSystems))
+
+	assert.Equal(t, summary.Range{
+		Date:     "2020-06-23",
+		End:      "2020-06-24T00:00:00Z",
+		Start:    "2020-06-23T00:00:00Z",
+		Text:     "Tue Jun 23 2020",
+		Timezone: "UTC",
+	}, s.Data.Range)
+}
+
+func TestCompute_Attributions(t *testing.T) {
+	start := time.Date(2020, 6, 23, 0, 0, 0, 0, time.UTC)
+	base := float64(start.Add(10 * time.Hour).Unix())
+
+	hh := []heartbeat.Heartbeat{
+		{
+			Category:         heartbeat.CodingCategory,
+			Entity:           "/tmp/main.go",
+			HumanLineChanges: heartbeat.PointerTo(4),
+			Time:             base,
+		},
+		{
+			AILineChanges: heartbeat.PointerTo(30),
+			Category:      heartbeat.CodingCategory,
+			Entity:        "/tmp/main.go",
+			Time:          base + 60,
+		},
+		{
+			Category: heartbeat.AICodingCategory,
+			Entity:   "/tmp/main.go",
+			Time:     base + 240,
+		},
+		{
+			Category: heartbeat.CodingCategory,
+			Entity:   "/tmp/main.go",
+			Time:     base + 300,
+		},
+	}
+
+	s := summary.Compute(hh, start, start.Add(24*time.Hour))
+
+	assert.Equal(t, []summary.Attribution{
+		{
+			Decimal:      "0.07",
+			Digital:      "0:04:00",
+			Minutes:      4,
+			Name:         "AI",
+			Percent:      80,
+			Text:         "4 mins",
+			TotalSeconds: 240,
+		},
+		{
+			Decimal:      "0.02",
+			Digital:      "0:01:00",
+			Minutes:      1,
+			Name:         "Human",
+			Percent:      20,
+			Text:         "1 min",
+			TotalSeconds: 60,
+		},
+	}, s.Data.Attributions)
+}
+
+func TestCompute_Empty(t *testing.T) {
+	start := time.Date(2020, 6, 23, 0, 0, 0, 0, time.UTC)
+
+	s := summary.Compute(nil, start, start.Add(24*time.Hour))
+
+	assert.Equal(t, "0 secs", s.Data.GrandTotal.Text)
+	assert.Empty(t, s.Data.Projects)
+	assert.Empty(t, s.Data.Branches)
+}
+
+func names[T summary.Branch | summary.Category | summary.Dependency | summary.Editor |
+	summary.Language | summary.OperatingSystem](items []T) []string {
+	var result []string
+
+	for _, item := range items {
+		switch i := any(item).(type) {
+		case summary.Branch:
+			result = append(result, i.Name)
+		case summary.Category:
+			result = append(result, i.Name)
+		case summary.Dependency:
+			result = append(result, i.Name)
+		case summary.Editor:
+			result = append(result, i.Name)
+		case summary.Language:
+			result = append(result, i.Name)
+		case summary.OperatingSystem:
+			result = append(result, i.Name)
+		}
+	}
+
+	return result
+}
@@ -10,6 +10,33 @@ import (
 )
 
 type (
+	// Attribution represents the time spent writing code with or without an
+	// ai assistant for a single day activity.
+	Attribution struct {
+		Decimal      string  `json:"decimal"`
+		Digital      string  `json:"digital"`
+		Hours        int     `json:"hours"`
+		Minutes      int     `json:"minutes"`
+		Name         string  `json:"name"`
+		Percent      float64 `json:"percent"`
+		Seconds      int     `json:"seconds"`
+		Text         string  `json:"text"`
+		TotalSeconds float64 `json:"total_seconds"`
+	}
+
+	// Branch represents the tracked branch for a single day activity.
+	Branch struct {
+		Decimal      string  `json:"decimal"`
+		Digital      string  `json:"digital"`
+		Hours        int     `json:"hours"`
+		Minutes      int     `json:"minutes"`
+		Name         string  `json:"name"`
+		Percent      float64 `json:"percent"`
+		Seconds      int     `json:"seconds"`
+		Text         string  `json:"text"`
+		TotalSeconds float64 `json:"total_seconds"`
+	}
+
 	// Category represents the tracked category for a single day activity.
 	Category struct {
 		Decimal      string  `json:"decimal"`
@@ -38,6 +65,8 @@ type (
 
 	// Data aggregates all activities for a single day.
 	Data struct {
+		Attributions     []Attribution     `json:"attributions,omitempty"`
+		Branches         []Branch          `json:"branches,omitempty"`
 		Categories       []Category        `json:"categories"`
 		Dependencies     []Dependency      `json:"dependencies"`
 		Editors          []Editor          `json:"editors"`